@@ -20,7 +20,8 @@ const (
 	ChannelRoleUSSD    ChannelRole = "ussd"
 )
 
-// Channel is something that can send/receive messages.
+// Channel is something that can send/receive messages. Channels which require consent can only be used to send to
+// contacts who have opted in on them.
 //
 //   {
 //     "uuid": "14782905-81a6-4910-bc9f-93ad287b23c3",
@@ -43,6 +44,27 @@ type Channel interface {
 	MatchPrefixes() []string
 }
 
+// ClassifierUUID is the UUID of a classifier
+type ClassifierUUID utils.UUID
+
+// Classifier is an NLU service which can classify text into intents and extract entities from it. The type is the
+// provider of the service, e.g. `wit` or `luis`.
+//
+//   {
+//     "uuid": "37657cf7-5eab-4286-9cb0-bbf270587bad",
+//     "name": "Booking",
+//     "type": "wit",
+//     "intents": ["book_flight", "book_hotel"]
+//   }
+//
+// @asset classifier
+type Classifier interface {
+	UUID() ClassifierUUID
+	Name() string
+	Type() string
+	Intents() []string
+}
+
 // FieldType is the data type of values for each field
 type FieldType string
 
@@ -181,13 +203,70 @@ type Resthook interface {
 	Subscribers() []string
 }
 
+// TemplateUUID is the UUID of a template
+type TemplateUUID utils.UUID
+
+// Template is a pre-approved message template, as required by channels like WhatsApp for messages sent outside of a
+// conversation window. It has a translation for each language and channel it has been approved for, and the content of
+// each translation has positional variables like `{{1}}`.
+//
+//   {
+//     "uuid": "14782905-81a6-4910-bc9f-93ad287b23c3",
+//     "name": "appointment_reminder",
+//     "translations": [
+//       {
+//         "language": "eng",
+//         "channel": {"uuid": "cf7c51ee-3d10-4a40-8c1b-5a5de9a1ef3d", "name": "WhatsApp"},
+//         "content": "Hi {{1}}, your appointment is on {{2}}",
+//         "variable_count": 2
+//       }
+//     ]
+//   }
+//
+// @asset template
+type Template interface {
+	UUID() TemplateUUID
+	Name() string
+	Translations() []TemplateTranslation
+}
+
+// TemplateTranslation is the content of a template in a single language for a single channel
+type TemplateTranslation interface {
+	Language() utils.Language
+	Channel() *ChannelReference
+	Content() string
+	VariableCount() int
+}
+
+// TicketerUUID is the UUID of a ticketer
+type TicketerUUID utils.UUID
+
+// Ticketer is a system which manages tickets, such as a help desk, allowing contacts to be handed off to human agents.
+// The type is the provider of the system, e.g. `mailgun` or `zendesk`.
+//
+//   {
+//     "uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5",
+//     "name": "Support Tickets",
+//     "type": "mailgun"
+//   }
+//
+// @asset ticketer
+type Ticketer interface {
+	UUID() TicketerUUID
+	Name() string
+	Type() string
+}
+
 // AssetSource is a source of assets
 type AssetSource interface {
 	Channels() ([]Channel, error)
+	Classifiers() ([]Classifier, error)
 	Fields() ([]Field, error)
 	Flow(FlowUUID) (Flow, error)
 	Groups() ([]Group, error)
 	Labels() ([]Label, error)
 	Locations() ([]LocationHierarchy, error)
 	Resthooks() ([]Resthook, error)
+	Templates() ([]Template, error)
+	Ticketers() ([]Ticketer, error)
 }
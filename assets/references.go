@@ -53,6 +53,38 @@ func (r *ChannelReference) String() string {
 
 var _ Reference = (*ChannelReference)(nil)
 
+// ClassifierReference is used to reference a classifier
+type ClassifierReference struct {
+	UUID ClassifierUUID `json:"uuid" validate:"required,uuid"`
+	Name string         `json:"name"`
+}
+
+// NewClassifierReference creates a new classifier reference with the given UUID and name
+func NewClassifierReference(uuid ClassifierUUID, name string) *ClassifierReference {
+	return &ClassifierReference{UUID: uuid, Name: name}
+}
+
+// Type returns the name of the asset type
+func (r *ClassifierReference) Type() string {
+	return "classifier"
+}
+
+// Identity returns the unique identity of the asset
+func (r *ClassifierReference) Identity() string {
+	return string(r.UUID)
+}
+
+// Variable returns whether this a variable (vs concrete) reference
+func (r *ClassifierReference) Variable() bool {
+	return false
+}
+
+func (r *ClassifierReference) String() string {
+	return fmt.Sprintf("%s[uuid=%s,name=%s]", r.Type(), r.Identity(), r.Name)
+}
+
+var _ Reference = (*ClassifierReference)(nil)
+
 // GroupReference is used to reference a group
 type GroupReference struct {
 	UUID      GroupUUID `json:"uuid,omitempty" validate:"omitempty,uuid4"`
@@ -193,6 +225,70 @@ func (r *LabelReference) String() string {
 
 var _ Reference = (*LabelReference)(nil)
 
+// TemplateReference is used to reference a template
+type TemplateReference struct {
+	UUID TemplateUUID `json:"uuid" validate:"required,uuid4"`
+	Name string       `json:"name"`
+}
+
+// NewTemplateReference creates a new template reference with the given UUID and name
+func NewTemplateReference(uuid TemplateUUID, name string) *TemplateReference {
+	return &TemplateReference{UUID: uuid, Name: name}
+}
+
+// Type returns the name of the asset type
+func (r *TemplateReference) Type() string {
+	return "template"
+}
+
+// Identity returns the unique identity of the asset
+func (r *TemplateReference) Identity() string {
+	return string(r.UUID)
+}
+
+// Variable returns whether this a variable (vs concrete) reference
+func (r *TemplateReference) Variable() bool {
+	return false
+}
+
+func (r *TemplateReference) String() string {
+	return fmt.Sprintf("%s[uuid=%s,name=%s]", r.Type(), r.Identity(), r.Name)
+}
+
+var _ Reference = (*TemplateReference)(nil)
+
+// TicketerReference is used to reference a ticketer
+type TicketerReference struct {
+	UUID TicketerUUID `json:"uuid" validate:"required,uuid"`
+	Name string       `json:"name"`
+}
+
+// NewTicketerReference creates a new ticketer reference with the given UUID and name
+func NewTicketerReference(uuid TicketerUUID, name string) *TicketerReference {
+	return &TicketerReference{UUID: uuid, Name: name}
+}
+
+// Type returns the name of the asset type
+func (r *TicketerReference) Type() string {
+	return "ticketer"
+}
+
+// Identity returns the unique identity of the asset
+func (r *TicketerReference) Identity() string {
+	return string(r.UUID)
+}
+
+// Variable returns whether this a variable (vs concrete) reference
+func (r *TicketerReference) Variable() bool {
+	return false
+}
+
+func (r *TicketerReference) String() string {
+	return fmt.Sprintf("%s[uuid=%s,name=%s]", r.Type(), r.Identity(), r.Name)
+}
+
+var _ Reference = (*TicketerReference)(nil)
+
 //------------------------------------------------------------------------------------------
 // Callbacks for missing assets
 //------------------------------------------------------------------------------------------
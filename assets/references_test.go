@@ -19,6 +19,15 @@ func TestReferences(t *testing.T) {
 	// channel references must always be concrete
 	assert.EqualError(t, utils.Validate(assets.NewChannelReference("", "Nexmo")), "field 'uuid' is required")
 
+	classifierRef := assets.NewClassifierReference("61602f3e-f603-4c70-8a8f-c477505bf4bf", "Booking")
+	assert.Equal(t, "classifier", classifierRef.Type())
+	assert.Equal(t, "61602f3e-f603-4c70-8a8f-c477505bf4bf", classifierRef.Identity())
+	assert.Equal(t, "classifier[uuid=61602f3e-f603-4c70-8a8f-c477505bf4bf,name=Booking]", classifierRef.String())
+	assert.NoError(t, utils.Validate(classifierRef))
+
+	// classifier references must always be concrete
+	assert.EqualError(t, utils.Validate(assets.NewClassifierReference("", "Booking")), "field 'uuid' is required")
+
 	fieldRef := assets.NewFieldReference("gender", "Gender")
 	assert.Equal(t, "field", fieldRef.Type())
 	assert.Equal(t, "gender", fieldRef.Identity())
@@ -76,6 +85,24 @@ func TestReferences(t *testing.T) {
 		utils.Validate(&assets.LabelReference{UUID: "61602f3e-f603-4c70-8a8f-c477505bf4bf", Name: "Spam", NameMatch: "@contact.fields.district"}),
 		"field 'uuid' is mutually exclusive with 'name_match', field 'name_match' is mutually exclusive with 'uuid'",
 	)
+
+	templateRef := assets.NewTemplateReference("61602f3e-f603-4c70-8a8f-c477505bf4bf", "appointment_reminder")
+	assert.Equal(t, "template", templateRef.Type())
+	assert.Equal(t, "61602f3e-f603-4c70-8a8f-c477505bf4bf", templateRef.Identity())
+	assert.Equal(t, "template[uuid=61602f3e-f603-4c70-8a8f-c477505bf4bf,name=appointment_reminder]", templateRef.String())
+	assert.NoError(t, utils.Validate(templateRef))
+
+	// template references must always be concrete
+	assert.EqualError(t, utils.Validate(assets.NewTemplateReference("", "appointment_reminder")), "field 'uuid' is required")
+
+	ticketerRef := assets.NewTicketerReference("19dc6346-9623-4fe4-be80-538d493ecdf5", "Support Tickets")
+	assert.Equal(t, "ticketer", ticketerRef.Type())
+	assert.Equal(t, "19dc6346-9623-4fe4-be80-538d493ecdf5", ticketerRef.Identity())
+	assert.Equal(t, "ticketer[uuid=19dc6346-9623-4fe4-be80-538d493ecdf5,name=Support Tickets]", ticketerRef.String())
+	assert.NoError(t, utils.Validate(ticketerRef))
+
+	// ticketer references must always be concrete
+	assert.EqualError(t, utils.Validate(assets.NewTicketerReference("", "Support Tickets")), "field 'uuid' is required")
 }
 
 func TestChannelReferenceUnmarsal(t *testing.T) {
@@ -15,13 +15,16 @@ import (
 // StaticSource is an asset source which loads assets from a static JSON file
 type StaticSource struct {
 	s struct {
-		Channels  []*types.Channel           `json:"channels" validate:"omitempty,dive"`
-		Fields    []*types.Field             `json:"fields" validate:"omitempty,dive"`
-		Flows     []*types.Flow              `json:"flows" validate:"omitempty,dive"`
-		Groups    []*types.Group             `json:"groups" validate:"omitempty,dive"`
-		Labels    []*types.Label             `json:"labels" validate:"omitempty,dive"`
-		Locations []*utils.LocationHierarchy `json:"locations"`
-		Resthooks []*types.Resthook          `json:"resthooks" validate:"omitempty,dive"`
+		Channels    []*types.Channel           `json:"channels" validate:"omitempty,dive"`
+		Classifiers []*types.Classifier        `json:"classifiers" validate:"omitempty,dive"`
+		Fields      []*types.Field             `json:"fields" validate:"omitempty,dive"`
+		Flows       []*types.Flow              `json:"flows" validate:"omitempty,dive"`
+		Groups      []*types.Group             `json:"groups" validate:"omitempty,dive"`
+		Labels      []*types.Label             `json:"labels" validate:"omitempty,dive"`
+		Locations   []*utils.LocationHierarchy `json:"locations"`
+		Resthooks   []*types.Resthook          `json:"resthooks" validate:"omitempty,dive"`
+		Templates   []*types.Template          `json:"templates" validate:"omitempty,dive"`
+		Ticketers   []*types.Ticketer          `json:"ticketers" validate:"omitempty,dive"`
 	}
 }
 
@@ -59,6 +62,15 @@ func (s *StaticSource) Channels() ([]assets.Channel, error) {
 	return set, nil
 }
 
+// Classifiers returns all classifier assets
+func (s *StaticSource) Classifiers() ([]assets.Classifier, error) {
+	set := make([]assets.Classifier, len(s.s.Classifiers))
+	for i := range s.s.Classifiers {
+		set[i] = s.s.Classifiers[i]
+	}
+	return set, nil
+}
+
 // Fields returns all field assets
 func (s *StaticSource) Fields() ([]assets.Field, error) {
 	set := make([]assets.Field, len(s.s.Fields))
@@ -113,3 +125,21 @@ func (s *StaticSource) Resthooks() ([]assets.Resthook, error) {
 	}
 	return set, nil
 }
+
+// Templates returns all template assets
+func (s *StaticSource) Templates() ([]assets.Template, error) {
+	set := make([]assets.Template, len(s.s.Templates))
+	for i := range s.s.Templates {
+		set[i] = s.s.Templates[i]
+	}
+	return set, nil
+}
+
+// Ticketers returns all ticketer assets
+func (s *StaticSource) Ticketers() ([]assets.Ticketer, error) {
+	set := make([]assets.Ticketer, len(s.s.Ticketers))
+	for i := range s.s.Ticketers {
+		set[i] = s.s.Ticketers[i]
+	}
+	return set, nil
+}
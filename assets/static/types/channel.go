@@ -7,14 +7,15 @@ import (
 
 // Channel is a JSON serializable implementation of a channel asset
 type Channel struct {
-	UUID_          assets.ChannelUUID       `json:"uuid" validate:"required,uuid"`
-	Name_          string                   `json:"name"`
-	Address_       string                   `json:"address"`
-	Schemes_       []string                 `json:"schemes" validate:"min=1"`
-	Roles_         []assets.ChannelRole     `json:"roles" validate:"min=1,dive,eq=send|eq=receive|eq=call|eq=answer|eq=ussd"`
-	Parent_        *assets.ChannelReference `json:"parent" validate:"omitempty,dive"`
-	Country_       string                   `json:"country,omitempty"`
-	MatchPrefixes_ []string                 `json:"match_prefixes,omitempty"`
+	UUID_            assets.ChannelUUID       `json:"uuid" validate:"required,uuid"`
+	Name_            string                   `json:"name"`
+	Address_         string                   `json:"address"`
+	Schemes_         []string                 `json:"schemes" validate:"min=1"`
+	Roles_           []assets.ChannelRole     `json:"roles" validate:"min=1,dive,eq=send|eq=receive|eq=call|eq=answer|eq=ussd"`
+	Parent_          *assets.ChannelReference `json:"parent" validate:"omitempty,dive"`
+	Country_         string                   `json:"country,omitempty"`
+	MatchPrefixes_   []string                 `json:"match_prefixes,omitempty"`
+	RequiresConsent_ bool                     `json:"requires_consent,omitempty"`
 }
 
 // NewChannel creates a new channel
@@ -66,3 +67,6 @@ func (c *Channel) Country() string { return c.Country_ }
 
 // MatchPrefixes returns this channel's match prefixes values used for selecting a channel for a URN (if any)
 func (c *Channel) MatchPrefixes() []string { return c.MatchPrefixes_ }
+
+// RequiresConsent returns whether contacts must have consented to messages before we can send to them on this channel
+func (c *Channel) RequiresConsent() bool { return c.RequiresConsent_ }
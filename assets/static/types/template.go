@@ -0,0 +1,58 @@
+package types
+
+import (
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/utils"
+)
+
+// Template is a JSON serializable implementation of a template asset
+type Template struct {
+	UUID_         assets.TemplateUUID    `json:"uuid" validate:"required,uuid4"`
+	Name_         string                 `json:"name" validate:"required"`
+	Translations_ []*TemplateTranslation `json:"translations" validate:"omitempty,dive"`
+}
+
+// NewTemplate creates a new template from the passed in UUID, name and translations
+func NewTemplate(uuid assets.TemplateUUID, name string, translations []*TemplateTranslation) assets.Template {
+	return &Template{UUID_: uuid, Name_: name, Translations_: translations}
+}
+
+// UUID returns the UUID of this template
+func (t *Template) UUID() assets.TemplateUUID { return t.UUID_ }
+
+// Name returns the name of this template
+func (t *Template) Name() string { return t.Name_ }
+
+// Translations returns the translations of this template
+func (t *Template) Translations() []assets.TemplateTranslation {
+	translations := make([]assets.TemplateTranslation, len(t.Translations_))
+	for i := range t.Translations_ {
+		translations[i] = t.Translations_[i]
+	}
+	return translations
+}
+
+// TemplateTranslation is a JSON serializable implementation of a template translation
+type TemplateTranslation struct {
+	Language_      utils.Language           `json:"language" validate:"required,language"`
+	Channel_       *assets.ChannelReference `json:"channel" validate:"required,dive"`
+	Content_       string                   `json:"content" validate:"required"`
+	VariableCount_ int                      `json:"variable_count" validate:"min=0"`
+}
+
+// NewTemplateTranslation creates a new template translation
+func NewTemplateTranslation(language utils.Language, channel *assets.ChannelReference, content string, variableCount int) *TemplateTranslation {
+	return &TemplateTranslation{Language_: language, Channel_: channel, Content_: content, VariableCount_: variableCount}
+}
+
+// Language returns the language of this translation
+func (t *TemplateTranslation) Language() utils.Language { return t.Language_ }
+
+// Channel returns the channel this translation has been approved for
+func (t *TemplateTranslation) Channel() *assets.ChannelReference { return t.Channel_ }
+
+// Content returns the content of this translation with its variable placeholders
+func (t *TemplateTranslation) Content() string { return t.Content_ }
+
+// VariableCount returns the number of variables in this translation
+func (t *TemplateTranslation) VariableCount() int { return t.VariableCount_ }
@@ -0,0 +1,25 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static/types"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	channel := assets.NewChannelReference("ffffffff-9b24-92e1-ffff-ffffb207cdb4", "WhatsApp")
+	translation := types.NewTemplateTranslation(utils.Language("eng"), channel, "Hi {{1}}, your appointment is on {{2}}", 2)
+	assert.Equal(t, utils.Language("eng"), translation.Language())
+	assert.Equal(t, channel, translation.Channel())
+	assert.Equal(t, "Hi {{1}}, your appointment is on {{2}}", translation.Content())
+	assert.Equal(t, 2, translation.VariableCount())
+
+	template := types.NewTemplate(assets.TemplateUUID("8a9c1f73-5059-46a0-ba4a-6390979c01d3"), "appointment_reminder", []*types.TemplateTranslation{translation})
+	assert.Equal(t, assets.TemplateUUID("8a9c1f73-5059-46a0-ba4a-6390979c01d3"), template.UUID())
+	assert.Equal(t, "appointment_reminder", template.Name())
+	assert.Equal(t, []assets.TemplateTranslation{translation}, template.Translations())
+}
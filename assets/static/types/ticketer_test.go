@@ -0,0 +1,17 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketer(t *testing.T) {
+	ticketer := types.NewTicketer(assets.TicketerUUID("19dc6346-9623-4fe4-be80-538d493ecdf5"), "Support Tickets", "mailgun")
+	assert.Equal(t, assets.TicketerUUID("19dc6346-9623-4fe4-be80-538d493ecdf5"), ticketer.UUID())
+	assert.Equal(t, "Support Tickets", ticketer.Name())
+	assert.Equal(t, "mailgun", ticketer.Type())
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
 	"github.com/nyaruka/goflow/test"
 	"github.com/nyaruka/goflow/utils"
 
@@ -59,11 +61,30 @@ func buildDocsContext(items map[string][]*documentedItem) (map[string]string, er
 	utils.SetUUIDGenerator(utils.NewSeededUUID4Generator(123456))
 	utils.SetTimeSource(utils.NewFixedTimeSource(time.Date(2018, 4, 11, 18, 24, 30, 123456000, time.UTC)))
 
-	session, _, err := test.CreateTestSession(server.URL, nil)
+	// the example session also classifies some text so that there's a result for the intent test examples
+	classify := actions.NewCallClassifierAction(
+		flows.ActionUUID("10c62052-7db1-49d1-b8ba-60d66db82e39"),
+		assets.NewClassifierReference("1c06c884-39dd-4ce4-ad9f-9a01cbe6c000", "Booking"),
+		"I'd like to book a flight to Quito",
+		"Intent",
+	)
+
+	session, _, err := test.CreateTestSession(server.URL, classify)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating example session")
 	}
 
+	// the example contact doesn't have any tickets so give it one for the ticket examples
+	session.Contact().AddTicket(&flows.Ticket{
+		UUID:     flows.TicketUUID("78d1fe0d-7e39-461e-81c3-a6a25f15ed69"),
+		Ticketer: assets.NewTicketerReference("19dc6346-9623-4fe4-be80-538d493ecdf5", "Support Tickets"),
+		Subject:  "Need help",
+		Body:     "Where are my cookies?",
+		Topic:    "Orders",
+		Status:   flows.TicketStatusOpen,
+		OpenedOn: time.Date(2018, 6, 20, 11, 45, 30, 123456789, time.UTC),
+	})
+
 	context := make(map[string]string, len(docSets))
 
 	for _, ds := range docSets {
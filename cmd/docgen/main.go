@@ -35,7 +35,7 @@ var templates = []struct {
 	{"Flow Specification", "index.md", nil},
 	{"Expressions", "expressions.md", []string{"context", "function", "test"}},
 	{"Flows", "flows.md", []string{"action", "router", "wait"}},
-	{"Sessions", "sessions.md", []string{"event", "trigger"}},
+	{"Sessions", "sessions.md", []string{"event", "trigger", "resume"}},
 	{"Assets", "assets.md", []string{"asset"}},
 }
 
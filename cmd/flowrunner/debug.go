@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/utils"
+)
+
+var debugHelp = `commands:
+  s, step          step to the next node, action or router decision (or just press enter)
+  c, continue      continue until the next breakpoint
+  b <uuid>         add a breakpoint on a node or action
+  d <uuid>         delete a breakpoint
+  p <template>     evaluate a template in the context of the run, e.g. p @results.favorite_color
+  results          print the results of the run
+  events           print the events generated so far in this sprint
+  h, help          print this help`
+
+// creates a debugger which reads commands from the given scanner whenever execution is paused
+func newDebugger(scanner *bufio.Scanner, out io.Writer) *engine.SteppingDebugger {
+	var debugger *engine.SteppingDebugger
+	debugger = engine.NewSteppingDebugger(true, func(stop *flows.DebugStop) engine.DebugCommand {
+		return debugPause(debugger, stop, scanner, out)
+	})
+	return debugger
+}
+
+func debugPause(debugger *engine.SteppingDebugger, stop *flows.DebugStop, scanner *bufio.Scanner, out io.Writer) engine.DebugCommand {
+	fmt.Fprintf(out, "⏸  %s\n", describeStop(stop))
+
+	for {
+		fmt.Fprintf(out, "(debug) ")
+		if !scanner.Scan() {
+			return engine.DebugContinue
+		}
+
+		command := strings.TrimSpace(scanner.Text())
+		arg := ""
+		if parts := strings.SplitN(command, " ", 2); len(parts) == 2 {
+			command, arg = parts[0], strings.TrimSpace(parts[1])
+		}
+
+		switch command {
+		case "", "s", "step":
+			debugger.SetStepping(true)
+			return engine.DebugStep
+		case "c", "continue":
+			debugger.SetStepping(false)
+			return engine.DebugContinue
+		case "b":
+			debugger.AddBreakpoint(utils.UUID(arg))
+			fmt.Fprintf(out, "added breakpoint on %s\n", arg)
+		case "d":
+			debugger.RemoveBreakpoint(utils.UUID(arg))
+			fmt.Fprintf(out, "deleted breakpoint on %s\n", arg)
+		case "p":
+			value, err := stop.Run.EvaluateTemplate(arg)
+			if err != nil {
+				fmt.Fprintf(out, "error: %s\n", err)
+			} else {
+				fmt.Fprintln(out, value)
+			}
+		case "results":
+			resultsJSON, _ := utils.JSONMarshalPretty(stop.Run.Results())
+			fmt.Fprintln(out, string(resultsJSON))
+		case "events":
+			printEvents(stop.Sprint.Events(), out)
+		case "h", "help":
+			fmt.Fprintln(out, debugHelp)
+		default:
+			fmt.Fprintf(out, "unknown command '%s', type h for help\n", command)
+		}
+	}
+}
+
+// describes a stop in a way that's readable to a flow author
+func describeStop(stop *flows.DebugStop) string {
+	switch stop.Type {
+	case flows.DebugStopNode:
+		return fmt.Sprintf("entering node %s in flow '%s'", stop.Node.UUID(), stop.Run.Flow().Name())
+	case flows.DebugStopAction:
+		return fmt.Sprintf("executing %s action %s", stop.Action.Type(), stop.Action.UUID())
+	case flows.DebugStopRouter:
+		return fmt.Sprintf("routing with %s router on node %s", stop.Node.Router().Type(), stop.Node.UUID())
+	case flows.DebugStopExit:
+		exit := stop.Exit()
+		if exit == nil {
+			return fmt.Sprintf("no exit taken from node %s", stop.Node.UUID())
+		}
+
+		description := fmt.Sprintf("taking exit '%s' %s", exit.Name(), exit.UUID())
+		if stop.Operand != nil {
+			description += fmt.Sprintf(", operand was \"%s\"", *stop.Operand)
+		}
+		if stop.Route.Match() != "" {
+			description += fmt.Sprintf(" and matched \"%s\"", stop.Route.Match())
+		}
+		return description
+	}
+	return string(stop.Type)
+}
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"time"
 
@@ -39,13 +40,21 @@ var contactJSON = `{
 
 var usage = `usage: flowrunner [flags] <assets.json> <flow_uuid>`
 
+var replayUsage = `usage: flowrunner replay [flags] <assets.json> <repro.json>`
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		replayMain(os.Args[2:])
+		return
+	}
+
 	var initialMsg, contactLang string
-	var printRepro bool
+	var printRepro, debug bool
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 	flags.StringVar(&initialMsg, "msg", "", "initial message to trigger session with")
 	flags.StringVar(&contactLang, "lang", "eng", "initial language of the contact")
 	flags.BoolVar(&printRepro, "repro", false, "print repro afterwards")
+	flags.BoolVar(&debug, "debug", false, "step through the flow in the debugger")
 	flags.Parse(os.Args[1:])
 	args := flags.Args()
 
@@ -58,7 +67,7 @@ func main() {
 	assetsPath := args[0]
 	flowUUID := assets.FlowUUID(args[1])
 
-	repro, err := RunFlow(assetsPath, flowUUID, initialMsg, utils.Language(contactLang), os.Stdin, os.Stdout)
+	repro, err := RunFlow(assetsPath, flowUUID, initialMsg, utils.Language(contactLang), debug, os.Stdin, os.Stdout)
 
 	if err != nil {
 		fmt.Println(err.Error())
@@ -72,8 +81,45 @@ func main() {
 	}
 }
 
-// RunFlow steps through a flow
-func RunFlow(assetsPath string, flowUUID assets.FlowUUID, initialMsg string, contactLang utils.Language, in io.Reader, out io.Writer) (*Repro, error) {
+func replayMain(arguments []string) {
+	var baselinePath, writePath, startTime string
+	var seed int64
+	var liveWebhooks bool
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.StringVar(&baselinePath, "baseline", "", "recorded output to compare the replay against")
+	flags.StringVar(&writePath, "write", "", "file to write the replay output to")
+	flags.StringVar(&startTime, "start-time", engine.DefaultReplayConfig.StartTime.Format(time.RFC3339Nano), "time of the replay clock when the session starts")
+	flags.Int64Var(&seed, "seed", engine.DefaultReplayConfig.Seed, "seed for random numbers and UUIDs")
+	flags.BoolVar(&liveWebhooks, "live-webhooks", false, "make real webhook calls instead of mocking them")
+	flags.Parse(arguments)
+	args := flags.Args()
+
+	if len(args) != 2 {
+		fmt.Println(replayUsage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, startTime)
+	if err != nil {
+		fmt.Printf("invalid start time: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	config := &engine.ReplayConfig{StartTime: start, Seed: seed, LiveWebhooks: liveWebhooks}
+
+	differences, err := ReplayRepro(args[0], args[1], config, baselinePath, writePath, os.Stdout)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if len(differences) > 0 {
+		os.Exit(2)
+	}
+}
+
+// RunFlow steps through a flow, optionally pausing in the debugger at each node, action and router decision
+func RunFlow(assetsPath string, flowUUID assets.FlowUUID, initialMsg string, contactLang utils.Language, debug bool, in io.Reader, out io.Writer) (*engine.Repro, error) {
 	source, err := static.LoadSource(assetsPath)
 	if err != nil {
 		return nil, err
@@ -100,7 +146,7 @@ func RunFlow(assetsPath string, flowUUID assets.FlowUUID, initialMsg string, con
 	languages := []utils.Language{flow.Language(), contact.Language()}
 	env := utils.NewEnvironmentBuilder().WithTimezone(la).WithAllowedLanguages(languages).Build()
 
-	repro := &Repro{}
+	repro := &engine.Repro{}
 
 	if initialMsg != "" {
 		msg := createMessage(contact, initialMsg)
@@ -110,7 +156,13 @@ func RunFlow(assetsPath string, flowUUID assets.FlowUUID, initialMsg string, con
 	}
 	fmt.Fprintf(out, "Starting flow '%s'....\n---------------------------------------\n", flow.Name())
 
-	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-flowrunner").Build()
+	scanner := bufio.NewScanner(in)
+
+	builder := engine.NewBuilder().WithDefaultUserAgent("goflow-flowrunner")
+	if debug {
+		builder.WithDebugger(newDebugger(scanner, out))
+	}
+	eng := builder.Build()
 	session := eng.NewSession(sessionAssets)
 
 	// start our session
@@ -120,7 +172,6 @@ func RunFlow(assetsPath string, flowUUID assets.FlowUUID, initialMsg string, con
 	}
 
 	printEvents(sprint.Events(), out)
-	scanner := bufio.NewScanner(in)
 
 	for session.Wait() != nil {
 
@@ -164,6 +215,8 @@ func printEvents(log []flows.Event, out io.Writer) {
 			msg = fmt.Sprintf("💬 \"%s\"", typed.Msg.Text())
 		case *events.MsgReceivedEvent:
 			msg = fmt.Sprintf("📥 received message '%s'", typed.Msg.Text())
+		case *events.MsgRejectedEvent:
+			msg = fmt.Sprintf("🚫 reply rejected as it doesn't match %s hint, %d retries remaining", typed.HintType, typed.RetriesRemaining)
 		case *events.MsgWaitEvent:
 			msg = fmt.Sprintf("⏳ waiting for message....")
 		case *events.RunResultChangedEvent:
@@ -176,8 +229,78 @@ func printEvents(log []flows.Event, out io.Writer) {
 	}
 }
 
-// Repro describes the trigger and resumes needed to reproduce this session
-type Repro struct {
-	Trigger flows.Trigger  `json:"trigger"`
-	Resumes []flows.Resume `json:"resumes"`
+// ReplayRepro replays a repro, optionally writing its output to a file and comparing it against a recorded baseline
+func ReplayRepro(assetsPath string, reproPath string, config *engine.ReplayConfig, baselinePath string, writePath string, out io.Writer) ([]*engine.ReplayDifference, error) {
+	source, err := static.LoadSource(assetsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionAssets, err := engine.NewSessionAssets(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing assets")
+	}
+
+	reproJSON, err := ioutil.ReadFile(reproPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading repro file")
+	}
+
+	missing, checkMissing := engine.CollectMissing()
+
+	repro, err := engine.ReadRepro(sessionAssets, reproJSON, missing)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMissing(); err != nil {
+		return nil, errors.Wrap(err, "unable to read repro")
+	}
+
+	outputs, err := engine.Replay(sessionAssets, repro, config)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(out, "Replayed %d sprints\n", len(outputs))
+
+	if writePath != "" {
+		outputJSON, err := utils.JSONMarshalPretty(outputs)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(writePath, outputJSON, 0644); err != nil {
+			return nil, errors.Wrap(err, "error writing replay output")
+		}
+		fmt.Fprintf(out, "Wrote output to %s\n", writePath)
+	}
+
+	if baselinePath == "" {
+		return nil, nil
+	}
+
+	baselineJSON, err := ioutil.ReadFile(baselinePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading baseline file")
+	}
+
+	var baseline []*engine.SprintOutput
+	if err := json.Unmarshal(baselineJSON, &baseline); err != nil {
+		return nil, errors.Wrap(err, "error parsing baseline file")
+	}
+
+	differences, err := engine.CompareReplay(baseline, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(differences) == 0 {
+		fmt.Fprintln(out, "✅ replay matches baseline")
+	} else {
+		fmt.Fprintf(out, "❌ replay has %d differences from baseline\n", len(differences))
+		for _, d := range differences {
+			fmt.Fprintln(out, d.String())
+		}
+	}
+
+	return differences, nil
 }
@@ -1,11 +1,16 @@
 package main_test
 
 import (
-	"github.com/nyaruka/goflow/assets"
+	"io/ioutil"
+	"os"
+	"path"
 	"strings"
 	"testing"
 
+	"github.com/nyaruka/goflow/assets"
 	main "github.com/nyaruka/goflow/cmd/flowrunner"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,7 +21,7 @@ func TestRunFlow(t *testing.T) {
 	in := strings.NewReader("I like red\npepsi\n")
 	out := &strings.Builder{}
 
-	_, err := main.RunFlow("testdata/two_questions.json", assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", in, out)
+	_, err := main.RunFlow("testdata/two_questions.json", assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", false, in, out)
 	require.NoError(t, err)
 
 	// remove input prompts and split output by line to get each event
@@ -38,3 +43,69 @@ func TestRunFlow(t *testing.T) {
 		"",
 	}, lines)
 }
+
+func TestReplayRepro(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// record a repro by running the flow
+	repro, err := main.RunFlow("testdata/two_questions.json", assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", false, strings.NewReader("I like red\npepsi\n"), &strings.Builder{})
+	require.NoError(t, err)
+
+	reproJSON, err := utils.JSONMarshalPretty(repro)
+	require.NoError(t, err)
+
+	reproPath := path.Join(tempDir, "repro.json")
+	baselinePath := path.Join(tempDir, "baseline.json")
+	require.NoError(t, ioutil.WriteFile(reproPath, reproJSON, 0644))
+
+	// replay it and write the output as our baseline
+	out := &strings.Builder{}
+	differences, err := main.ReplayRepro("testdata/two_questions.json", reproPath, engine.DefaultReplayConfig, "", baselinePath, out)
+	require.NoError(t, err)
+	assert.Nil(t, differences)
+	assert.Equal(t, "Replayed 3 sprints\nWrote output to "+baselinePath+"\n", out.String())
+
+	// replaying again should match that baseline
+	out = &strings.Builder{}
+	differences, err = main.ReplayRepro("testdata/two_questions.json", reproPath, engine.DefaultReplayConfig, baselinePath, "", out)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(differences))
+	assert.Equal(t, "Replayed 3 sprints\n✅ replay matches baseline\n", out.String())
+
+	// but not if we change the seed
+	out = &strings.Builder{}
+	config := &engine.ReplayConfig{StartTime: engine.DefaultReplayConfig.StartTime, Seed: 111}
+	differences, err = main.ReplayRepro("testdata/two_questions.json", reproPath, config, baselinePath, "", out)
+	require.NoError(t, err)
+	assert.True(t, len(differences) > 0)
+	assert.Contains(t, out.String(), "❌ replay has")
+
+	// error if the repro references assets which don't exist
+	missingPath := path.Join(tempDir, "missing.json")
+	missingJSON := strings.Replace(string(reproJSON), `"urns": [`, `"groups": [{"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Testers"}], "urns": [`, 1)
+	require.NoError(t, ioutil.WriteFile(missingPath, []byte(missingJSON), 0644))
+
+	_, err = main.ReplayRepro("testdata/two_questions.json", missingPath, engine.DefaultReplayConfig, "", "", &strings.Builder{})
+	assert.EqualError(t, err, "unable to read repro: missing assets: group[uuid=b7cf0d83-f1c9-411c-96fd-c511a4cfa86d,name=Testers]")
+}
+
+func TestRunFlowWithDebugger(t *testing.T) {
+	// step through the first node, evaluate a template, then continue and answer the two questions
+	in := strings.NewReader("\nh\np @contact.name\nc\nI like red\npepsi\n")
+	out := &strings.Builder{}
+
+	_, err := main.RunFlow("testdata/two_questions.json", assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", true, in, out)
+	require.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "⏸  entering node 46d51f50-58de-49da-8d13-dadbf322685d in flow 'Two Questions'\n")
+	assert.Contains(t, output, "⏸  executing send_msg action e97cd6d5-3354-4dbd-85bc-6c1f87849eec\n")
+	assert.Contains(t, output, "commands:\n")
+	assert.Contains(t, output, "(debug) Ben Haggerty\n")
+	assert.Contains(t, output, "💬 \"Great, you are done!\"")
+
+	// after continuing, we shouldn't pause again
+	assert.Equal(t, 2, strings.Count(output, "⏸"))
+}
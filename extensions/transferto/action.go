@@ -1,6 +1,7 @@
 package transferto
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/nyaruka/gocommon/urns"
@@ -83,7 +84,7 @@ func (a *TransferAirtimeAction) Execute(run flows.FlowRun, step flows.Step, logM
 		return errors.Wrap(err, "unable to read config")
 	}
 
-	transfer, err := attemptTransfer(contact.PreferredChannel(), config, a.Amounts, recipient, run.Session().Engine().HTTPClient())
+	transfer, err := attemptTransfer(run.Session().SprintContext(), contact.PreferredChannel(), config, a.Amounts, recipient, run.Session().Engine().HTTPClient())
 
 	if err != nil {
 		logEvent(events.NewErrorEvent(err))
@@ -94,7 +95,7 @@ func (a *TransferAirtimeAction) Execute(run flows.FlowRun, step flows.Step, logM
 	if a.ResultName != "" && transfer != nil {
 		value := transfer.actualAmount.String()
 		category := statusCategories[transfer.status]
-		result := flows.NewResult(a.ResultName, value, category, "", step.NodeUUID(), nil, nil, utils.Now())
+		result := flows.NewResult(a.ResultName, value, category, "", step.NodeUUID(), nil, nil, run.Session().Now())
 
 		run.SaveResult(result)
 		logEvent(events.NewRunResultChangedEvent(result))
@@ -128,7 +129,7 @@ var statusCategories = map[transferStatus]string{
 }
 
 // attempts to make the transfer, returning the actual transfer or an error
-func attemptTransfer(channel *flows.Channel, config *transferToConfig, amounts map[string]decimal.Decimal, recipient string, httpClient *utils.HTTPClient) (*transfer, error) {
+func attemptTransfer(ctx context.Context, channel *flows.Channel, config *transferToConfig, amounts map[string]decimal.Decimal, recipient string, httpClient *utils.HTTPClient) (*transfer, error) {
 	// if airtime transferred are disabled, return a mock transfer
 	if config.Disabled {
 		amount := decimal.RequireFromString("1")
@@ -136,6 +137,7 @@ func attemptTransfer(channel *flows.Channel, config *transferToConfig, amounts m
 	}
 
 	cl := client.NewTransferToClient(config.Login, config.APIToken, httpClient)
+	cl.SetContext(ctx)
 	t := &transfer{recipient: recipient, status: transferStatusFailed}
 
 	info, err := cl.MSISDNInfo(recipient, config.Currency, "1")
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -29,6 +30,7 @@ type Client struct {
 	token      string
 	httpClient *utils.HTTPClient
 	apiURL     string
+	ctx        context.Context
 }
 
 // Response is a base interface for all responses
@@ -47,7 +49,7 @@ func (r *baseResponse) ErrorTxt() string { return r.ErrorTxt_ }
 
 // NewTransferToClient creates a new TransferTo client
 func NewTransferToClient(login string, token string, httpClient *utils.HTTPClient) *Client {
-	return &Client{login: login, token: token, httpClient: httpClient, apiURL: apiURL}
+	return &Client{login: login, token: token, httpClient: httpClient, apiURL: apiURL, ctx: context.Background()}
 }
 
 // SetAPIURL sets the API URL used by this client
@@ -55,6 +57,11 @@ func (c *Client) SetAPIURL(url string) {
 	c.apiURL = url
 }
 
+// SetContext sets the context used for requests made by this client
+func (c *Client) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
 // Ping just verifies the credentials
 func (c *Client) Ping() error {
 	request := url.Values{}
@@ -172,6 +179,7 @@ func (c *Client) request(data url.Values, dest interface{}) error {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(c.ctx)
 
 	response, _, err := c.httpClient.DoWithDump(req)
 	if err != nil {
@@ -250,10 +250,29 @@ func TestConstructors(t *testing.T) {
 			]
 		}`,
 		},
+		{
+			actions.NewCallClassifierAction(
+				actionUUID,
+				assets.NewClassifierReference(assets.ClassifierUUID("1c06c884-39dd-4ce4-ad9f-9a01cbe6c000"), "Booking"),
+				"@input.text",
+				"Intent",
+			),
+			`{
+			"type": "call_classifier",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"classifier": {
+				"uuid": "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000",
+				"name": "Booking"
+			},
+			"input": "@input.text",
+			"result_name": "Intent"
+		}`,
+		},
 		{
 			actions.NewCallResthookAction(
 				actionUUID,
 				"new-registration",
+				nil, // no retry
 				"My Result",
 			),
 			`{
@@ -272,6 +291,7 @@ func TestConstructors(t *testing.T) {
 					"Authentication": "Token @contact.fields.token",
 				},
 				`{"contact_id": 234}`, // body
+				flows.NewWebhookRetry(3, 500, []int{502, 503}),
 				"Webhook Response",
 			),
 			`{
@@ -283,9 +303,32 @@ func TestConstructors(t *testing.T) {
 				"Authentication": "Token @contact.fields.token"
 			},
 			"body": "{\"contact_id\": 234}",
+			"retry": {"max_attempts": 3, "backoff_ms": 500, "statuses": [502, 503]},
 			"result_name": "Webhook Response"
 		}`,
 		},
+		{
+			actions.NewOpenTicketAction(
+				actionUUID,
+				assets.NewTicketerReference(assets.TicketerUUID("19dc6346-9623-4fe4-be80-538d493ecdf5"), "Support Tickets"),
+				"Need help",
+				"@input.text",
+				"Orders",
+				"Ticket",
+			),
+			`{
+			"type": "open_ticket",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"ticketer": {
+				"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5",
+				"name": "Support Tickets"
+			},
+			"subject": "Need help",
+			"body": "@input.text",
+			"topic": "Orders",
+			"result_name": "Ticket"
+		}`,
+		},
 		{
 			actions.NewPlayAudioAction(
 				actionUUID,
@@ -391,6 +434,10 @@ func TestConstructors(t *testing.T) {
 				[]string{"http://example.com/red.jpg"},
 				[]string{"Red", "Blue"},
 				true,
+				actions.NewTemplating(
+					assets.NewTemplateReference(assets.TemplateUUID("5722e1fd-fe32-4e74-ac78-3cf41a6adb7e"), "order_shipped"),
+					[]string{"@contact.name", "ORD-1234"},
+				),
 			),
 			`{
 			"type": "send_msg",
@@ -398,7 +445,14 @@ func TestConstructors(t *testing.T) {
 			"text": "Hi there",
 			"attachments": ["http://example.com/red.jpg"],
 			"quick_replies": ["Red", "Blue"],
-			"all_urns": true
+			"all_urns": true,
+			"templating": {
+				"template": {
+					"uuid": "5722e1fd-fe32-4e74-ac78-3cf41a6adb7e",
+					"name": "order_shipped"
+				},
+				"variables": ["@contact.name", "ORD-1234"]
+			}
 		}`,
 		},
 		{
@@ -484,6 +538,8 @@ func TestConstructors(t *testing.T) {
 				actionUUID,
 				assets.NewFlowReference(assets.FlowUUID("fece6eac-9127-4343-9269-56e88f391562"), "Parent"),
 				true, // terminal
+				map[string]string{"name": "@contact.name"},
+				map[string]string{"language": "Language"},
 			),
 			`{
 			"type": "enter_flow",
@@ -492,7 +548,9 @@ func TestConstructors(t *testing.T) {
 				"uuid": "fece6eac-9127-4343-9269-56e88f391562",
 				"name": "Parent"
 			},
-			"terminal": true
+			"terminal": true,
+			"params": {"name": "@contact.name"},
+			"returns": {"language": "Language"}
 		}`,
 		},
 		{
@@ -0,0 +1,129 @@
+package actions
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	RegisterType(TypeCallClassifier, func() flows.Action { return &CallClassifierAction{} })
+}
+
+// TypeCallClassifier is the type for the call classifier action
+const TypeCallClassifier string = "call_classifier"
+
+// CallClassifierAction can be used to classify the intent and entities from a given input using an NLU classifier. The
+// input field may be a template and defaults to `@input`. A result is created with the given name, whose value is the
+// name of the top intent, and whose `extra` holds all the recognized intents and extracted entities, so that a switch
+// router can branch on it with tests like `has_intent` and `has_top_intent`. If the input can't be classified, the
+// category of the result will be `Failure`.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "call_classifier",
+//     "classifier": {
+//       "uuid": "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000",
+//       "name": "Booking"
+//     },
+//     "input": "I'd like to book a flight to Quito",
+//     "result_name": "Intent"
+//   }
+//
+// @action call_classifier
+type CallClassifierAction struct {
+	BaseAction
+	onlineAction
+
+	Classifier *assets.ClassifierReference `json:"classifier" validate:"required,dive"`
+	Input      string                      `json:"input,omitempty"`
+	ResultName string                      `json:"result_name" validate:"required"`
+}
+
+// NewCallClassifierAction creates a new call classifier action
+func NewCallClassifierAction(uuid flows.ActionUUID, classifier *assets.ClassifierReference, input string, resultName string) *CallClassifierAction {
+	return &CallClassifierAction{
+		BaseAction: NewBaseAction(TypeCallClassifier, uuid),
+		Classifier: classifier,
+		Input:      input,
+		ResultName: resultName,
+	}
+}
+
+// Execute runs this action
+func (a *CallClassifierAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	template := a.Input
+	if template == "" {
+		template = "@input"
+	}
+
+	input, err := run.EvaluateTemplate(template)
+	if err != nil {
+		logEvent(events.NewErrorEvent(err))
+	}
+
+	classification := a.classify(run, input, logEvent)
+	if classification == nil {
+		a.saveResult(run, step, a.ResultName, "", "Failure", "", &input, nil, logEvent)
+		return nil
+	}
+
+	value := ""
+	if top := classification.TopIntent(); top != nil {
+		value = top.Name
+	}
+
+	extra, _ := json.Marshal(classification)
+
+	a.saveResult(run, step, a.ResultName, value, "Success", "", &input, extra, logEvent)
+	return nil
+}
+
+func (a *CallClassifierAction) classify(run flows.FlowRun, input string, logEvent flows.EventCallback) *flows.Classification {
+	if input == "" {
+		logEvent(events.NewErrorEventf("call_classifier input evaluated to empty string, skipping"))
+		return nil
+	}
+
+	classifier := run.Session().Assets().Classifiers().Get(a.Classifier.UUID)
+	if classifier == nil {
+		logEvent(events.NewErrorEventf("missing %s", a.Classifier))
+		return nil
+	}
+
+	service := run.Session().Engine().ClassifierService()
+	if service == nil {
+		logEvent(events.NewErrorEventf("no classifier service available to call %s", a.Classifier))
+		return nil
+	}
+
+	classification, err := service.Classify(run.Session(), classifier, input)
+	if err != nil {
+		logEvent(events.NewErrorEvent(err))
+		return nil
+	}
+	return classification
+}
+
+// Inspect inspects this object and any children
+func (a *CallClassifierAction) Inspect(inspect func(flows.Inspectable)) {
+	inspect(a)
+	flows.InspectReference(a.Classifier, inspect)
+}
+
+// EnumerateTemplates enumerates all expressions on this object and its children
+func (a *CallClassifierAction) EnumerateTemplates(localization flows.Localization, include func(string)) {
+	include(a.Input)
+}
+
+// RewriteTemplates rewrites all templates on this object and its children
+func (a *CallClassifierAction) RewriteTemplates(localization flows.Localization, rewrite func(string) string) {
+	a.Input = rewrite(a.Input)
+}
+
+// EnumerateResultNames enumerates all result names on this object
+func (a *CallClassifierAction) EnumerateResultNames(include func(string)) {
+	include(a.ResultName)
+}
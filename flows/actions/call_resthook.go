@@ -21,12 +21,14 @@ const TypeCallResthook string = "call_resthook"
 // A [event:webhook_called] event will be created for each subscriber of the resthook with the results
 // of the HTTP call. If the action has `result_name` set, a result will
 // be created with that name, and if the resthook returns valid JSON, that will be accessible
-// through `extra` on the result.
+// through `extra` on the result. As with [action:call_webhook], an optional `retry` policy can be given, which applies
+// to the call to each subscriber.
 //
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "call_resthook",
-//     "resthook": "new-registration"
+//     "resthook": "new-registration",
+//     "retry": {"max_attempts": 2}
 //   }
 //
 // @action call_resthook
@@ -34,15 +36,17 @@ type CallResthookAction struct {
 	BaseAction
 	onlineAction
 
-	Resthook   string `json:"resthook" validate:"required"`
-	ResultName string `json:"result_name,omitempty"`
+	Resthook   string              `json:"resthook" validate:"required"`
+	Retry      *flows.WebhookRetry `json:"retry,omitempty" validate:"omitempty,dive"`
+	ResultName string              `json:"result_name,omitempty"`
 }
 
 // NewCallResthookAction creates a new call resthook action
-func NewCallResthookAction(uuid flows.ActionUUID, resthook string, resultName string) *CallResthookAction {
+func NewCallResthookAction(uuid flows.ActionUUID, resthook string, retry *flows.WebhookRetry, resultName string) *CallResthookAction {
 	return &CallResthookAction{
 		BaseAction: NewBaseAction(TypeCallResthook, uuid),
 		Resthook:   resthook,
+		Retry:      retry,
 		ResultName: resultName,
 	}
 }
@@ -76,7 +80,7 @@ func (a *CallResthookAction) Execute(run flows.FlowRun, step flows.Step, logModi
 
 		req.Header.Add("Content-Type", "application/json")
 
-		webhook, err := flows.MakeWebhookCall(run.Session(), req, a.Resthook)
+		webhook, err := flows.MakeWebhookCall(run.Session(), req, a.Resthook, a.Retry)
 		if err != nil {
 			logEvent(events.NewErrorEvent(err))
 		} else {
@@ -23,6 +23,11 @@ const TypeCallWebhook string = "call_webhook"
 // a new result with that name. If the webhook returned valid JSON, that will be accessible
 // through `extra` on the result.
 //
+// An optional `retry` policy can be given so that calls which fail with a connection error or a response status that
+// might be temporary are retried. It has the `max_attempts` to make, the `backoff_ms` to wait before the first retry,
+// which doubles after each attempt, and the response `statuses` to retry, which default to any 5xx status. Retries
+// which would take the total wait over 30 seconds, or the sprint over its duration limit, aren't made.
+//
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "call_webhook",
@@ -31,6 +36,7 @@ const TypeCallWebhook string = "call_webhook"
 //     "headers": {
 //       "Authorization": "Token AAFFZZHH"
 //     },
+//     "retry": {"max_attempts": 3, "backoff_ms": 500, "statuses": [502, 503, 504]},
 //     "result_name": "webhook"
 //   }
 //
@@ -39,21 +45,23 @@ type CallWebhookAction struct {
 	BaseAction
 	onlineAction
 
-	Method     string            `json:"method" validate:"required,http_method"`
-	URL        string            `json:"url" validate:"required"`
-	Headers    map[string]string `json:"headers,omitempty"`
-	Body       string            `json:"body,omitempty"`
-	ResultName string            `json:"result_name,omitempty"`
+	Method     string              `json:"method" validate:"required,http_method"`
+	URL        string              `json:"url" validate:"required"`
+	Headers    map[string]string   `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	Retry      *flows.WebhookRetry `json:"retry,omitempty" validate:"omitempty,dive"`
+	ResultName string              `json:"result_name,omitempty"`
 }
 
 // NewCallWebhookAction creates a new call webhook action
-func NewCallWebhookAction(uuid flows.ActionUUID, method string, url string, headers map[string]string, body string, resultName string) *CallWebhookAction {
+func NewCallWebhookAction(uuid flows.ActionUUID, method string, url string, headers map[string]string, body string, retry *flows.WebhookRetry, resultName string) *CallWebhookAction {
 	return &CallWebhookAction{
 		BaseAction: NewBaseAction(TypeCallWebhook, uuid),
 		Method:     method,
 		URL:        url,
 		Headers:    headers,
 		Body:       body,
+		Retry:      retry,
 		ResultName: resultName,
 	}
 }
@@ -107,7 +115,7 @@ func (a *CallWebhookAction) Execute(run flows.FlowRun, step flows.Step, logModif
 		req.Header.Add(key, headerValue)
 	}
 
-	webhook, err := flows.MakeWebhookCall(run.Session(), req, "")
+	webhook, err := flows.MakeWebhookCall(run.Session(), req, "", a.Retry)
 
 	if err != nil {
 		logEvent(events.NewErrorEvent(err))
@@ -2,8 +2,10 @@ package actions
 
 import (
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
 
 	"github.com/pkg/errors"
 )
@@ -17,13 +19,20 @@ const TypeEnterFlow string = "enter_flow"
 
 // EnterFlowAction can be used to start a contact down another flow. The current flow will pause until the subflow exits or expires.
 //
+// Templates can be bound to the `params` of the subflow, which are evaluated and, if the subflow declares inputs, converted
+// to their declared types. The subflow can access them as `@run.params`. When the subflow completes, any of its declared outputs
+// named in `returns` are saved as results on the current run with the given result names. Like all results, these are stored
+// as text, so an output of another type is saved as the text of its value.
+//
 // A [event:flow_entered] event will be created to record that the flow was started.
 //
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "enter_flow",
-//     "flow": {"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Collect Language"},
-//     "terminal": false
+//     "flow": {"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Collect Age"},
+//     "terminal": false,
+//     "params": {"name": "@contact.name"},
+//     "returns": {"age": "Age"}
 //   }
 //
 // @action enter_flow
@@ -33,14 +42,18 @@ type EnterFlowAction struct {
 
 	Flow     *assets.FlowReference `json:"flow" validate:"required"`
 	Terminal bool                  `json:"terminal,omitempty"`
+	Params   map[string]string     `json:"params,omitempty"`
+	Returns  map[string]string     `json:"returns,omitempty"`
 }
 
 // NewEnterFlowAction creates a new start flow action
-func NewEnterFlowAction(uuid flows.ActionUUID, flow *assets.FlowReference, terminal bool) *EnterFlowAction {
+func NewEnterFlowAction(uuid flows.ActionUUID, flow *assets.FlowReference, terminal bool, params map[string]string, returns map[string]string) *EnterFlowAction {
 	return &EnterFlowAction{
 		BaseAction: NewBaseAction(TypeEnterFlow, uuid),
 		Flow:       flow,
 		Terminal:   terminal,
+		Params:     params,
+		Returns:    returns,
 	}
 }
 
@@ -57,13 +70,68 @@ func (a *EnterFlowAction) Execute(run flows.FlowRun, step flows.Step, logModifie
 		return nil
 	}
 
-	run.Session().PushFlow(flow, run, a.Terminal)
+	params, err := a.evaluateParams(run, flow, logEvent)
+	if err != nil {
+		run.Exit(flows.RunStatusErrored)
+		logEvent(events.NewFatalErrorEvent(err))
+		return nil
+	}
+
+	run.Session().PushFlow(flow, run, a.Terminal, params, a.Returns)
 	logEvent(events.NewFlowEnteredEvent(a.Flow, run.UUID(), a.Terminal))
 	return nil
 }
 
+// evaluates our params and checks them against the inputs declared by the flow being entered
+func (a *EnterFlowAction) evaluateParams(run flows.FlowRun, flow flows.Flow, logEvent flows.EventCallback) (types.XMap, error) {
+	if len(a.Params) == 0 && flow.Inputs() == nil {
+		return nil, nil
+	}
+
+	params := types.NewEmptyXMap()
+	for _, key := range utils.SortedKeys(a.Params) {
+		value, err := run.EvaluateTemplateValue(a.Params[key])
+		if err != nil {
+			logEvent(events.NewErrorEvent(err))
+		}
+		params.Put(key, value)
+	}
+
+	// flows which don't declare inputs can be entered with any params
+	if flow.Inputs() == nil {
+		return params, nil
+	}
+
+	coerced, err := flow.Inputs().Coerce(run.Environment(), params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid params for %s", flow.Reference())
+	}
+	return coerced, nil
+}
+
 // Inspect inspects this object and any children
 func (a *EnterFlowAction) Inspect(inspect func(flows.Inspectable)) {
 	inspect(a)
 	flows.InspectReference(a.Flow, inspect)
 }
+
+// EnumerateTemplates enumerates all expressions on this object and its children
+func (a *EnterFlowAction) EnumerateTemplates(localization flows.Localization, include func(string)) {
+	for _, key := range utils.SortedKeys(a.Params) {
+		include(a.Params[key])
+	}
+}
+
+// RewriteTemplates rewrites all templates on this object and its children
+func (a *EnterFlowAction) RewriteTemplates(localization flows.Localization, rewrite func(string) string) {
+	for key, value := range a.Params {
+		a.Params[key] = rewrite(value)
+	}
+}
+
+// EnumerateResultNames enumerates all result names on this object
+func (a *EnterFlowAction) EnumerateResultNames(include func(string)) {
+	for _, key := range utils.SortedKeys(a.Returns) {
+		include(a.Returns[key])
+	}
+}
@@ -88,6 +88,7 @@ func TestConstructors(t *testing.T) {
 	require.NoError(t, err)
 
 	nexmo := assets.Channels().Get("3a05eaf5-cb1b-4246-bef1-f277419c83a7")
+	twitter := assets.Channels().Get("8e21f093-99aa-413b-b55b-758b54308fcb")
 	age := assets.Fields().Get("age")
 	ageValue := types.NewXNumberFromInt(37)
 	testers := assets.Groups().Get("b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")
@@ -107,6 +108,18 @@ func TestConstructors(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			modifiers.NewConsentModifier(twitter, flows.ConsentStatusGranted, time.Date(2018, 10, 18, 15, 0, 0, 0, time.UTC)),
+			`{
+				"type": "consent",
+				"channel": {
+					"uuid": "8e21f093-99aa-413b-b55b-758b54308fcb",
+					"name": "Twitter Channel"
+				},
+				"status": "granted",
+				"changed_on": "2018-10-18T15:00:00Z"
+			}`,
+		},
 		{
 			modifiers.NewFieldModifier(age, flows.NewValue(types.NewXText("37 years"), nil, &ageValue, "", "", "")),
 			`{
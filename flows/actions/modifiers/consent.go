@@ -0,0 +1,80 @@
+package modifiers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	RegisterType(TypeConsent, readConsentModifier)
+}
+
+// TypeConsent is the type of our consent modifier
+const TypeConsent string = "consent"
+
+// ConsentModifier grants or revokes a contact's consent to be messaged on a channel
+type ConsentModifier struct {
+	baseModifier
+
+	channel   *flows.Channel
+	status    flows.ConsentStatus
+	changedOn time.Time
+}
+
+// NewConsentModifier creates a new consent modifier for a change made at the given time
+func NewConsentModifier(channel *flows.Channel, status flows.ConsentStatus, changedOn time.Time) *ConsentModifier {
+	return &ConsentModifier{
+		baseModifier: newBaseModifier(TypeConsent),
+		channel:      channel,
+		status:       status,
+		changedOn:    changedOn,
+	}
+}
+
+// Apply applies this modification to the given contact
+func (m *ConsentModifier) Apply(env utils.Environment, assets flows.SessionAssets, contact *flows.Contact, log flows.EventCallback) {
+	if contact.SetConsent(m.channel, m.status, m.changedOn) {
+		log(events.NewContactConsentChangedEvent(m.channel.Reference(), m.status))
+	}
+}
+
+var _ flows.Modifier = (*ConsentModifier)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type consentModifierEnvelope struct {
+	utils.TypedEnvelope
+	Channel   *assets.ChannelReference `json:"channel" validate:"required,dive"`
+	Status    flows.ConsentStatus      `json:"status" validate:"required,eq=granted|eq=revoked"`
+	ChangedOn time.Time                `json:"changed_on" validate:"required"`
+}
+
+func readConsentModifier(assets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Modifier, error) {
+	e := &consentModifierEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	channel := assets.Channels().Get(e.Channel.UUID)
+	if channel == nil {
+		missing(e.Channel)
+		return nil, ErrNoModifier // nothing left to modify without the channel
+	}
+	return NewConsentModifier(channel, e.Status, e.ChangedOn), nil
+}
+
+func (m *ConsentModifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&consentModifierEnvelope{
+		TypedEnvelope: utils.TypedEnvelope{Type: m.Type()},
+		Channel:       m.channel.Reference(),
+		Status:        m.status,
+		ChangedOn:     m.changedOn,
+	})
+}
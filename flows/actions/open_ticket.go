@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	RegisterType(TypeOpenTicket, func() flows.Action { return &OpenTicketAction{} })
+}
+
+// TypeOpenTicket is the type for the open ticket action
+const TypeOpenTicket string = "open_ticket"
+
+// OpenTicketAction can be used to hand the contact off to a human agent by opening a ticket with a ticketer. The
+// subject, body and topic can all contain expressions. The ticket is added to the contact's tickets, and if a result
+// name is given, a result is created whose value is the UUID of the ticket. If the ticket can't be opened, the category
+// of that result will be `Failure`.
+//
+// An [event:ticket_opened] event will be created which the caller should act on. To continue the flow once an agent
+// has closed the ticket, follow this action with a `ticket` wait.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "open_ticket",
+//     "ticketer": {
+//       "uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5",
+//       "name": "Support Tickets"
+//     },
+//     "subject": "Need help",
+//     "body": "@input.text",
+//     "topic": "Orders",
+//     "result_name": "Ticket"
+//   }
+//
+// @action open_ticket
+type OpenTicketAction struct {
+	BaseAction
+	onlineAction
+
+	Ticketer   *assets.TicketerReference `json:"ticketer" validate:"required,dive"`
+	Subject    string                    `json:"subject" validate:"required"`
+	Body       string                    `json:"body,omitempty"`
+	Topic      string                    `json:"topic,omitempty"`
+	ResultName string                    `json:"result_name,omitempty"`
+}
+
+// NewOpenTicketAction creates a new open ticket action
+func NewOpenTicketAction(uuid flows.ActionUUID, ticketer *assets.TicketerReference, subject, body, topic, resultName string) *OpenTicketAction {
+	return &OpenTicketAction{
+		BaseAction: NewBaseAction(TypeOpenTicket, uuid),
+		Ticketer:   ticketer,
+		Subject:    subject,
+		Body:       body,
+		Topic:      topic,
+		ResultName: resultName,
+	}
+}
+
+// Execute runs this action
+func (a *OpenTicketAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	contact := run.Contact()
+	if contact == nil {
+		logEvent(events.NewErrorEventf("can't execute action in session without a contact"))
+		return nil
+	}
+
+	ticket := a.open(run, logEvent)
+	if ticket == nil {
+		if a.ResultName != "" {
+			a.saveResult(run, step, a.ResultName, "", "Failure", "", nil, nil, logEvent)
+		}
+		return nil
+	}
+
+	contact.AddTicket(ticket)
+
+	// the event gets its own copy of the ticket so it isn't changed when the ticket is later closed
+	opened := *ticket
+	logEvent(events.NewTicketOpenedEvent(&opened))
+
+	if a.ResultName != "" {
+		extra, _ := json.Marshal(ticket)
+		a.saveResult(run, step, a.ResultName, string(ticket.UUID), "Success", "", nil, extra, logEvent)
+	}
+	return nil
+}
+
+func (a *OpenTicketAction) open(run flows.FlowRun, logEvent flows.EventCallback) *flows.Ticket {
+	ticketer := run.Session().Assets().Ticketers().Get(a.Ticketer.UUID)
+	if ticketer == nil {
+		logEvent(events.NewErrorEventf("missing %s", a.Ticketer))
+		return nil
+	}
+
+	subject, err := run.EvaluateTemplate(a.Subject)
+	if err != nil {
+		logEvent(events.NewErrorEvent(err))
+	}
+
+	// make sure the subject is single line - replace '\t\n\r\f\v' to ' '
+	subject = regexp.MustCompile(`\s+`).ReplaceAllString(subject, " ")
+	subject = strings.TrimSpace(subject)
+
+	if subject == "" {
+		logEvent(events.NewErrorEventf("ticket subject evaluated to empty string, skipping"))
+		return nil
+	}
+
+	body, err := run.EvaluateTemplate(a.Body)
+	if err != nil {
+		logEvent(events.NewErrorEvent(err))
+	}
+
+	topic, err := run.EvaluateTemplate(a.Topic)
+	if err != nil {
+		logEvent(events.NewErrorEvent(err))
+	}
+
+	return flows.NewTicket(ticketer.Reference(), subject, body, strings.TrimSpace(topic), run.Session().Now())
+}
+
+// Inspect inspects this object and any children
+func (a *OpenTicketAction) Inspect(inspect func(flows.Inspectable)) {
+	inspect(a)
+	flows.InspectReference(a.Ticketer, inspect)
+}
+
+// EnumerateTemplates enumerates all expressions on this object and its children
+func (a *OpenTicketAction) EnumerateTemplates(localization flows.Localization, include func(string)) {
+	include(a.Subject)
+	include(a.Body)
+	include(a.Topic)
+}
+
+// RewriteTemplates rewrites all templates on this object and its children
+func (a *OpenTicketAction) RewriteTemplates(localization flows.Localization, rewrite func(string) string) {
+	a.Subject = rewrite(a.Subject)
+	a.Body = rewrite(a.Body)
+	a.Topic = rewrite(a.Topic)
+}
+
+// EnumerateResultNames enumerates all result names on this object
+func (a *OpenTicketAction) EnumerateResultNames(include func(string)) {
+	if a.ResultName != "" {
+		include(a.ResultName)
+	}
+}
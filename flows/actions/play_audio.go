@@ -63,7 +63,7 @@ func (a *PlayAudioAction) Execute(run flows.FlowRun, step flows.Step, logModifie
 
 	// if we have an audio URL, turn it into a message
 	attachments := []flows.Attachment{flows.Attachment(fmt.Sprintf("audio:%s", evaluatedAudioURL))}
-	msg := flows.NewMsgOut(connection.URN(), connection.Channel(), "", attachments, nil)
+	msg := flows.NewMsgOut(connection.URN(), connection.Channel(), "", attachments, nil, nil)
 	logEvent(events.NewIVRCreatedEvent(msg))
 
 	return nil
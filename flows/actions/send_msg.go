@@ -5,6 +5,7 @@ import (
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
 )
 
 func init() {
@@ -16,16 +17,26 @@ const TypeSendMsg string = "send_msg"
 
 // SendMsgAction can be used to reply to the current contact in a flow. The text field may contain templates. The action
 // will attempt to find pairs of URNs and channels which can be used for sending. If it can't find such a pair, it will
-// create a message without a channel or URN.
+// create a message without a channel or URN. Channels which require consent are skipped unless the contact has consented
+// to messages on them.
 //
-// A [event:msg_created] event will be created with the evaluated text.
+// The action can also reference a message template. A message to a channel for which the template has a translation is
+// created from that translation instead, with the evaluated variables substituted into it. The contact's language is
+// tried first, then the allowed languages of the environment. Messages to other channels are created from the text.
+//
+// A [event:msg_created] event will be created with the evaluated text, and the template, language and variables if
+// the message was created from a template.
 //
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "send_msg",
 //     "text": "Hi @contact.name, are you ready to complete today's survey?",
 //     "attachments": [],
-//     "all_urns": false
+//     "all_urns": false,
+//     "templating": {
+//       "template": {"uuid": "3ce100b7-a734-4b4e-891b-350b1279ade2", "name": "survey_reminder"},
+//       "variables": ["@contact.name"]
+//     }
 //   }
 //
 // @action send_msg
@@ -34,11 +45,23 @@ type SendMsgAction struct {
 	universalAction
 	createMsgAction
 
-	AllURNs bool `json:"all_urns,omitempty"`
+	AllURNs    bool        `json:"all_urns,omitempty"`
+	Templating *Templating `json:"templating,omitempty" validate:"omitempty,dive"`
+}
+
+// Templating is the template a send_msg action should use and the expressions for its variables
+type Templating struct {
+	Template  *assets.TemplateReference `json:"template" validate:"required,dive"`
+	Variables []string                  `json:"variables"`
+}
+
+// NewTemplating creates a new templating for a send_msg action
+func NewTemplating(template *assets.TemplateReference, variables []string) *Templating {
+	return &Templating{Template: template, Variables: variables}
 }
 
 // NewSendMsgAction creates a new send msg action
-func NewSendMsgAction(uuid flows.ActionUUID, text string, attachments []string, quickReplies []string, allURNs bool) *SendMsgAction {
+func NewSendMsgAction(uuid flows.ActionUUID, text string, attachments []string, quickReplies []string, allURNs bool, templating *Templating) *SendMsgAction {
 	return &SendMsgAction{
 		BaseAction: NewBaseAction(TypeSendMsg, uuid),
 		createMsgAction: createMsgAction{
@@ -46,7 +69,8 @@ func NewSendMsgAction(uuid flows.ActionUUID, text string, attachments []string,
 			Attachments:  attachments,
 			QuickReplies: quickReplies,
 		},
-		AllURNs: allURNs,
+		AllURNs:    allURNs,
+		Templating: templating,
 	}
 }
 
@@ -59,32 +83,92 @@ func (a *SendMsgAction) Execute(run flows.FlowRun, step flows.Step, logModifier
 
 	evaluatedText, evaluatedAttachments, evaluatedQuickReplies := a.evaluateMessage(run, nil, a.Text, a.Attachments, a.QuickReplies, logEvent)
 
-	destinations := run.Contact().ResolveDestinations(a.AllURNs)
+	template, evaluatedVariables := a.evaluateTemplating(run, logEvent)
+	languages := a.templateLanguages(run)
+
+	destinations, unconsented := run.Contact().ResolveConsentedDestinations(a.AllURNs)
+
+	for _, dest := range unconsented {
+		logEvent(events.NewErrorEventf("contact hasn't consented to messages on %s", dest.Channel.Reference()))
+	}
 
 	// create a new message for each URN+channel destination
 	for _, dest := range destinations {
 		var channelRef *assets.ChannelReference
+		text := evaluatedText
+		var templating *flows.MsgTemplating
+
 		if dest.Channel != nil {
 			channelRef = assets.NewChannelReference(dest.Channel.UUID(), dest.Channel.Name())
+
+			// if we have a template with a translation for this channel, the message is created from that
+			if template != nil {
+				translation := template.FindTranslation(dest.Channel.UUID(), languages)
+				if translation != nil {
+					if translation.VariableCount() == len(evaluatedVariables) {
+						text = translation.Substitute(evaluatedVariables)
+						templating = flows.NewMsgTemplating(template.Reference(), translation.Language(), evaluatedVariables)
+					} else {
+						logEvent(events.NewErrorEventf("%s requires %d variables, got %d", template.Reference(), translation.VariableCount(), len(evaluatedVariables)))
+					}
+				}
+			}
 		}
 
-		msg := flows.NewMsgOut(dest.URN.URN(), channelRef, evaluatedText, evaluatedAttachments, evaluatedQuickReplies)
+		msg := flows.NewMsgOut(dest.URN.URN(), channelRef, text, evaluatedAttachments, evaluatedQuickReplies, templating)
 		logEvent(events.NewMsgCreatedEvent(msg))
 	}
 
 	// if we couldn't find a destination, create a msg without a URN or channel and it's up to the caller
 	// to handle that as they want
-	if len(destinations) == 0 {
-		msg := flows.NewMsgOut(urns.NilURN, nil, evaluatedText, evaluatedAttachments, evaluatedQuickReplies)
+	if len(destinations) == 0 && len(unconsented) == 0 {
+		msg := flows.NewMsgOut(urns.NilURN, nil, evaluatedText, evaluatedAttachments, evaluatedQuickReplies, nil)
 		logEvent(events.NewMsgCreatedEvent(msg))
 	}
 
 	return nil
 }
 
+// looks up our template and evaluates its variables
+func (a *SendMsgAction) evaluateTemplating(run flows.FlowRun, logEvent flows.EventCallback) (*flows.Template, []string) {
+	if a.Templating == nil {
+		return nil, nil
+	}
+
+	template := run.Session().Assets().Templates().Get(a.Templating.Template.UUID)
+	if template == nil {
+		logEvent(events.NewErrorEventf("missing %s, sending as text", a.Templating.Template))
+		return nil, nil
+	}
+
+	variables := make([]string, len(a.Templating.Variables))
+	for i, variable := range a.Templating.Variables {
+		evaluated, err := run.EvaluateTemplate(variable)
+		if err != nil {
+			logEvent(events.NewErrorEvent(err))
+		}
+		variables[i] = evaluated
+	}
+	return template, variables
+}
+
+// gets the languages to try when looking for a template translation, i.e. the contact's language followed by the
+// allowed languages of the environment
+func (a *SendMsgAction) templateLanguages(run flows.FlowRun) []utils.Language {
+	languages := make([]utils.Language, 0, len(run.Environment().AllowedLanguages())+1)
+	if run.Contact().Language() != utils.NilLanguage {
+		languages = append(languages, run.Contact().Language())
+	}
+	return append(languages, run.Environment().AllowedLanguages()...)
+}
+
 // Inspect inspects this object and any children
 func (a *SendMsgAction) Inspect(inspect func(flows.Inspectable)) {
 	inspect(a)
+
+	if a.Templating != nil {
+		flows.InspectReference(a.Templating.Template, inspect)
+	}
 }
 
 // EnumerateTemplates enumerates all expressions on this object and its children
@@ -92,6 +176,9 @@ func (a *SendMsgAction) EnumerateTemplates(localization flows.Localization, incl
 	include(a.Text)
 	flows.EnumerateTemplateArray(a.Attachments, include)
 	flows.EnumerateTemplateArray(a.QuickReplies, include)
+	if a.Templating != nil {
+		flows.EnumerateTemplateArray(a.Templating.Variables, include)
+	}
 	flows.EnumerateTemplateTranslations(localization, a, "text", include)
 	flows.EnumerateTemplateTranslations(localization, a, "attachments", include)
 	flows.EnumerateTemplateTranslations(localization, a, "quick_replies", include)
@@ -102,6 +189,9 @@ func (a *SendMsgAction) RewriteTemplates(localization flows.Localization, rewrit
 	a.Text = rewrite(a.Text)
 	flows.RewriteTemplateArray(a.Attachments, rewrite)
 	flows.RewriteTemplateArray(a.QuickReplies, rewrite)
+	if a.Templating != nil {
+		flows.RewriteTemplateArray(a.Templating.Variables, rewrite)
+	}
 	flows.RewriteTemplateTranslations(localization, a, "text", rewrite)
 	flows.RewriteTemplateTranslations(localization, a, "attachments", rewrite)
 	flows.RewriteTemplateTranslations(localization, a, "quick_replies", rewrite)
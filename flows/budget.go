@@ -0,0 +1,124 @@
+package flows
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BudgetType is a type of resource whose use within a single sprint is limited by the engine
+type BudgetType string
+
+const (
+	// BudgetTypeSteps is the number of steps taken in a sprint
+	BudgetTypeSteps BudgetType = "step"
+
+	// BudgetTypeEvents is the number of events generated in a sprint
+	BudgetTypeEvents BudgetType = "event"
+
+	// BudgetTypeWebhookCalls is the number of webhook calls made in a sprint
+	BudgetTypeWebhookCalls BudgetType = "webhook_call"
+
+	// BudgetTypeWebhookBytes is the total size of webhook response bodies in a sprint
+	BudgetTypeWebhookBytes BudgetType = "webhook_bytes"
+
+	// BudgetTypeDuration is the wall-clock time taken by a sprint
+	BudgetTypeDuration BudgetType = "duration"
+)
+
+var budgetDescriptions = map[BudgetType]string{
+	BudgetTypeSteps:        "step",
+	BudgetTypeEvents:       "event",
+	BudgetTypeWebhookCalls: "webhook call",
+	BudgetTypeWebhookBytes: "webhook response bytes",
+	BudgetTypeDuration:     "sprint duration",
+}
+
+// BudgetExceededError is the error when a sprint exceeds one of its budgets and execution is stopped
+type BudgetExceededError struct {
+	Budget      BudgetType
+	Destination NodeUUID
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s limit exceeded, stopping execution before entering '%s'", budgetDescriptions[e.Budget], e.Destination)
+}
+
+// Code returns the code of this error, e.g. step_limit_exceeded
+func (e *BudgetExceededError) Code() string {
+	return string(e.Budget) + "_limit_exceeded"
+}
+
+// SprintBudget tracks the resources used by a sprint against the limits configured on the engine. A limit of zero
+// means that resource isn't limited.
+type SprintBudget struct {
+	engine       Engine
+	started      time.Time
+	webhookCalls int
+	webhookBytes int
+}
+
+// NewSprintBudget creates a new budget for a sprint which is starting now
+func NewSprintBudget(engine Engine) *SprintBudget {
+	b := &SprintBudget{engine: engine}
+
+	// only check the clock if we need to, and use the wall clock rather than the engine's time source as this is
+	// measuring how long the sprint actually takes
+	if engine.MaxSprintDuration() > 0 {
+		b.started = time.Now()
+	}
+	return b
+}
+
+// CanUseWebhookCall returns an error if another webhook call shouldn't be made because this sprint has used up its
+// webhook budgets
+func (b *SprintBudget) CanUseWebhookCall() error {
+	if max := b.engine.MaxWebhookCallsPerSprint(); max > 0 && b.webhookCalls >= max {
+		return errors.Errorf("webhook call limit of %d exceeded, call not made", max)
+	}
+	if max := b.engine.MaxWebhookBytesPerSprint(); max > 0 && b.webhookBytes > max {
+		return errors.Errorf("webhook response bytes limit of %d exceeded, call not made", max)
+	}
+	return nil
+}
+
+// UseWebhookCall records that a webhook call has been attempted
+func (b *SprintBudget) UseWebhookCall() {
+	b.webhookCalls++
+}
+
+// CanWait returns whether this sprint can wait for the given duration without exceeding its duration limit
+func (b *SprintBudget) CanWait(d time.Duration) bool {
+	max := b.engine.MaxSprintDuration()
+	return max <= 0 || time.Since(b.started)+d <= max
+}
+
+// UseWebhookBytes records the size of a webhook response body
+func (b *SprintBudget) UseWebhookBytes(n int) {
+	b.webhookBytes += n
+}
+
+// Check checks whether this sprint can continue to the given destination node after taking the given number of steps
+// and generating the given number of events, returning an error if a budget has been exceeded
+func (b *SprintBudget) Check(steps int, events int, destination NodeUUID) *BudgetExceededError {
+	exceeded := func(max int, used int) bool { return max > 0 && used > max }
+
+	var budget BudgetType
+
+	if steps > b.engine.MaxStepsPerSprint() {
+		budget = BudgetTypeSteps
+	} else if exceeded(b.engine.MaxEventsPerSprint(), events) {
+		budget = BudgetTypeEvents
+	} else if exceeded(b.engine.MaxWebhookCallsPerSprint(), b.webhookCalls) {
+		budget = BudgetTypeWebhookCalls
+	} else if exceeded(b.engine.MaxWebhookBytesPerSprint(), b.webhookBytes) {
+		budget = BudgetTypeWebhookBytes
+	} else if max := b.engine.MaxSprintDuration(); max > 0 && time.Since(b.started) > max {
+		budget = BudgetTypeDuration
+	} else {
+		return nil
+	}
+
+	return &BudgetExceededError{Budget: budget, Destination: destination}
+}
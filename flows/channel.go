@@ -45,6 +45,15 @@ func (c *Channel) Reference() *assets.ChannelReference {
 	return assets.NewChannelReference(c.UUID(), c.Name())
 }
 
+// RequiresConsent returns whether contacts must have consented to messages before we can send to them on this channel.
+// Channel assets opt in to this by implementing `RequiresConsent() bool`, otherwise consent isn't required.
+func (c *Channel) RequiresConsent() bool {
+	if asset, ok := c.Channel.(interface{ RequiresConsent() bool }); ok {
+		return asset.RequiresConsent()
+	}
+	return false
+}
+
 // SupportsScheme returns whether this channel supports the given URN scheme
 func (c *Channel) SupportsScheme(scheme string) bool {
 	for _, s := range c.Schemes() {
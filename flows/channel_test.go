@@ -6,6 +6,7 @@ import (
 
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/goflow/assets"
+	static "github.com/nyaruka/goflow/assets/static/types"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/test"
@@ -40,6 +41,27 @@ func TestChannel(t *testing.T) {
 	assert.Equal(t, assets.NewChannelReference(ch.UUID(), "Android"), ch.Reference())
 	assert.True(t, ch.HasRole(assets.ChannelRoleSend))
 	assert.False(t, ch.HasRole(assets.ChannelRoleCall))
+	assert.False(t, ch.RequiresConsent())
+}
+
+// a channel asset from a host which knows nothing about consent
+type legacyChannel struct {
+	assets.Channel
+}
+
+func TestChannelRequiresConsent(t *testing.T) {
+	asset := &static.Channel{
+		UUID_:            assets.ChannelUUID("294a14d4-c998-41e5-a314-5941b97b89d7"),
+		Name_:            "WhatsApp",
+		Schemes_:         []string{"whatsapp"},
+		Roles_:           []assets.ChannelRole{assets.ChannelRoleSend},
+		RequiresConsent_: true,
+	}
+
+	assert.True(t, flows.NewChannel(asset).RequiresConsent())
+
+	// channel assets which don't say whether they require consent, don't
+	assert.False(t, flows.NewChannel(&legacyChannel{asset}).RequiresConsent())
 }
 
 func TestChannelSetGetForURN(t *testing.T) {
@@ -0,0 +1,121 @@
+package flows
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExtractedIntent is an intent recognized by a classifier, with the classifier's confidence in it
+type ExtractedIntent struct {
+	Name       string          `json:"name"`
+	Confidence decimal.Decimal `json:"confidence"`
+}
+
+// ExtractedEntity is an entity extracted by a classifier, with the classifier's confidence in it
+type ExtractedEntity struct {
+	Value      string          `json:"value"`
+	Confidence decimal.Decimal `json:"confidence"`
+}
+
+// Classification is the result of classifying some text, i.e. the recognized intents and the extracted entities by
+// name. Services aren't required to order intents or entity values by confidence.
+type Classification struct {
+	Intents  []ExtractedIntent            `json:"intents"`
+	Entities map[string][]ExtractedEntity `json:"entities"`
+}
+
+// TopIntent returns the intent with the highest confidence, or nil if no intents were recognized
+func (c *Classification) TopIntent() *ExtractedIntent {
+	var top *ExtractedIntent
+	for i := range c.Intents {
+		if top == nil || c.Intents[i].Confidence.GreaterThan(top.Confidence) {
+			top = &c.Intents[i]
+		}
+	}
+	return top
+}
+
+// TopEntities returns the value with the highest confidence of each extracted entity
+func (c *Classification) TopEntities() map[string]string {
+	top := make(map[string]string, len(c.Entities))
+	for name, values := range c.Entities {
+		var best *ExtractedEntity
+		for i := range values {
+			if best == nil || values[i].Confidence.GreaterThan(best.Confidence) {
+				best = &values[i]
+			}
+		}
+		if best != nil {
+			top[name] = best.Value
+		}
+	}
+	return top
+}
+
+// ClassifierService classifies text using a classifier, e.g. by calling the API of the classifier's provider
+type ClassifierService interface {
+	Classify(session Session, classifier *Classifier, input string) (*Classification, error)
+}
+
+// ClassifierServiceFunc is an adapter to allow a function to be used as a classifier service
+type ClassifierServiceFunc func(Session, *Classifier, string) (*Classification, error)
+
+// Classify calls the function
+func (f ClassifierServiceFunc) Classify(session Session, classifier *Classifier, input string) (*Classification, error) {
+	return f(session, classifier, input)
+}
+
+// LocalClassifierRule is a regular expression which recognizes an intent with the given confidence when it matches
+// the input. Named groups in the expression are extracted as entities.
+type LocalClassifierRule struct {
+	intent     string
+	pattern    *regexp.Regexp
+	confidence decimal.Decimal
+}
+
+// NewLocalClassifierRule creates a new rule for a local classifier service. The pattern is matched case-insensitively.
+func NewLocalClassifierRule(intent string, pattern string, confidence decimal.Decimal) *LocalClassifierRule {
+	return &LocalClassifierRule{intent: intent, pattern: regexp.MustCompile(`(?i)` + pattern), confidence: confidence}
+}
+
+type localClassifierService struct {
+	rules []*LocalClassifierRule
+}
+
+// NewLocalClassifierService creates a classifier service which doesn't call any providers but classifies text using
+// regular expressions. Rules for intents the classifier doesn't have are ignored. It's intended for testing.
+func NewLocalClassifierService(rules ...*LocalClassifierRule) ClassifierService {
+	return &localClassifierService{rules: rules}
+}
+
+func (s *localClassifierService) Classify(session Session, classifier *Classifier, input string) (*Classification, error) {
+	c := &Classification{
+		Intents:  make([]ExtractedIntent, 0),
+		Entities: make(map[string][]ExtractedEntity),
+	}
+
+	for _, rule := range s.rules {
+		if !classifier.HasIntent(rule.intent) {
+			continue
+		}
+
+		match := rule.pattern.FindStringSubmatch(input)
+		if match == nil {
+			continue
+		}
+
+		c.Intents = append(c.Intents, ExtractedIntent{Name: rule.intent, Confidence: rule.confidence})
+
+		for i, name := range rule.pattern.SubexpNames() {
+			if name != "" && match[i] != "" {
+				c.Entities[name] = append(c.Entities[name], ExtractedEntity{Value: match[i], Confidence: rule.confidence})
+			}
+		}
+	}
+
+	sort.SliceStable(c.Intents, func(i, j int) bool { return c.Intents[i].Confidence.GreaterThan(c.Intents[j].Confidence) })
+
+	return c, nil
+}
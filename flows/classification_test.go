@@ -0,0 +1,70 @@
+package flows_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static/types"
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalClassifierService(t *testing.T) {
+	classifier := flows.NewClassifier(types.NewClassifier(
+		assets.ClassifierUUID("1c06c884-39dd-4ce4-ad9f-9a01cbe6c000"),
+		"Booking",
+		"wit",
+		[]string{"book_flight", "book_hotel"},
+	))
+
+	assert.Equal(t, assets.NewClassifierReference("1c06c884-39dd-4ce4-ad9f-9a01cbe6c000", "Booking"), classifier.Reference())
+	assert.True(t, classifier.HasIntent("book_hotel"))
+	assert.False(t, classifier.HasIntent("cancel"))
+
+	service := flows.NewLocalClassifierService(
+		flows.NewLocalClassifierRule("book_hotel", `\bbook\b`, decimal.RequireFromString("0.3")),
+		flows.NewLocalClassifierRule("book_flight", `\bflight\b(?:.* to (?P<location>\w+))?`, decimal.RequireFromString("0.9")),
+		flows.NewLocalClassifierRule("cancel", `\bcancel\b`, decimal.RequireFromString("1.0")),
+	)
+
+	// intents are ordered by confidence and named groups become entities
+	c, err := service.Classify(nil, classifier, "Book a FLIGHT to Quito")
+	require.NoError(t, err)
+	assert.Equal(t, []flows.ExtractedIntent{
+		{Name: "book_flight", Confidence: decimal.RequireFromString("0.9")},
+		{Name: "book_hotel", Confidence: decimal.RequireFromString("0.3")},
+	}, c.Intents)
+	assert.Equal(t, map[string][]flows.ExtractedEntity{
+		"location": {{Value: "Quito", Confidence: decimal.RequireFromString("0.9")}},
+	}, c.Entities)
+	assert.Equal(t, "book_flight", c.TopIntent().Name)
+
+	// rules for intents the classifier doesn't have are ignored
+	c, err = service.Classify(nil, classifier, "cancel my booking")
+	require.NoError(t, err)
+	assert.Equal(t, []flows.ExtractedIntent{}, c.Intents)
+	assert.Nil(t, c.TopIntent())
+}
+
+func TestClassificationTops(t *testing.T) {
+	// classifications from other services might not be ordered by confidence
+	c := &flows.Classification{
+		Intents: []flows.ExtractedIntent{
+			{Name: "book_hotel", Confidence: decimal.RequireFromString("0.3")},
+			{Name: "book_flight", Confidence: decimal.RequireFromString("0.9")},
+		},
+		Entities: map[string][]flows.ExtractedEntity{
+			"location": {
+				{Value: "Lima", Confidence: decimal.RequireFromString("0.4")},
+				{Value: "Quito", Confidence: decimal.RequireFromString("0.8")},
+			},
+			"date": {},
+		},
+	}
+
+	assert.Equal(t, "book_flight", c.TopIntent().Name)
+	assert.Equal(t, map[string]string{"location": "Quito"}, c.TopEntities())
+}
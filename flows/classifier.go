@@ -0,0 +1,66 @@
+package flows
+
+import (
+	"github.com/nyaruka/goflow/assets"
+)
+
+// Classifier represents an NLU classifier
+type Classifier struct {
+	assets.Classifier
+}
+
+// NewClassifier returns a new classifier object from the given classifier asset
+func NewClassifier(asset assets.Classifier) *Classifier {
+	return &Classifier{Classifier: asset}
+}
+
+// Asset returns the underlying asset
+func (c *Classifier) Asset() assets.Classifier { return c.Classifier }
+
+// Reference returns a reference to this classifier
+func (c *Classifier) Reference() *assets.ClassifierReference {
+	if c == nil {
+		return nil
+	}
+	return assets.NewClassifierReference(c.UUID(), c.Name())
+}
+
+// HasIntent returns whether this classifier can recognize the given intent
+func (c *Classifier) HasIntent(intent string) bool {
+	for _, i := range c.Intents() {
+		if i == intent {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifierAssets provides access to all classifier assets
+type ClassifierAssets struct {
+	all    []*Classifier
+	byUUID map[assets.ClassifierUUID]*Classifier
+}
+
+// NewClassifierAssets creates a new set of classifier assets
+func NewClassifierAssets(classifiers []assets.Classifier) *ClassifierAssets {
+	s := &ClassifierAssets{
+		all:    make([]*Classifier, len(classifiers)),
+		byUUID: make(map[assets.ClassifierUUID]*Classifier, len(classifiers)),
+	}
+	for i, asset := range classifiers {
+		classifier := NewClassifier(asset)
+		s.all[i] = classifier
+		s.byUUID[classifier.UUID()] = classifier
+	}
+	return s
+}
+
+// All returns all the classifiers
+func (s *ClassifierAssets) All() []*Classifier {
+	return s.all
+}
+
+// Get returns the classifier with the given UUID
+func (s *ClassifierAssets) Get(uuid assets.ClassifierUUID) *Classifier {
+	return s.byUUID[uuid]
+}
@@ -0,0 +1,53 @@
+package flows
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+)
+
+// ConsentStatus is whether a contact has granted or revoked consent to be messaged on a channel
+type ConsentStatus string
+
+// the possible consent statuses
+const (
+	ConsentStatusGranted ConsentStatus = "granted"
+	ConsentStatusRevoked ConsentStatus = "revoked"
+)
+
+// Consent records whether a contact has consented to be messaged on a channel, and when that last changed
+type Consent struct {
+	Channel   *assets.ChannelReference `json:"channel" validate:"required,dive"`
+	Status    ConsentStatus            `json:"status" validate:"required,eq=granted|eq=revoked"`
+	ChangedOn time.Time                `json:"changed_on" validate:"required"`
+}
+
+// NewConsent creates a new consent record
+func NewConsent(channel *assets.ChannelReference, status ConsentStatus, changedOn time.Time) *Consent {
+	return &Consent{Channel: channel, Status: status, ChangedOn: changedOn}
+}
+
+// ConsentList is the consent records of a contact, one per channel
+type ConsentList []*Consent
+
+// Get gets the consent record for the given channel, or nil if there isn't one
+func (l ConsentList) Get(channel assets.ChannelUUID) *Consent {
+	for _, c := range l {
+		if c.Channel.UUID == channel {
+			return c
+		}
+	}
+	return nil
+}
+
+func (l ConsentList) clone() ConsentList {
+	if l == nil {
+		return nil
+	}
+
+	clone := make(ConsentList, len(l))
+	for i, c := range l {
+		clone[i] = NewConsent(c.Channel, c.Status, c.ChangedOn)
+	}
+	return clone
+}
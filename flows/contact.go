@@ -32,6 +32,7 @@ import (
 //  * `fields` all the custom contact fields the contact has set
 //  * `fields.[snaked_field_name]` the value of the specific field
 //  * `channel` shorthand for `contact.urns[0].channel`, i.e. the [channel](#context:channel) of the contact's preferred URN
+//  * `tickets` all the [tickets](#context:ticket) opened for the contact
 //
 // Examples:
 //
@@ -62,6 +63,8 @@ type Contact struct {
 	urns      URNList
 	groups    *GroupList
 	fields    FieldValues
+	consents  ConsentList
+	tickets   TicketList
 
 	// transient fields
 	assets SessionAssets
@@ -140,6 +143,8 @@ func (c *Contact) Clone() *Contact {
 		urns:      c.urns.clone(),
 		groups:    c.groups.clone(),
 		fields:    c.fields.clone(),
+		consents:  c.consents.clone(),
+		tickets:   c.tickets.clone(),
 		assets:    c.assets,
 	}
 }
@@ -216,6 +221,45 @@ func (c *Contact) Groups() *GroupList { return c.groups }
 // Fields returns this contact's field values
 func (c *Contact) Fields() FieldValues { return c.fields }
 
+// Consents returns this contact's consent records
+func (c *Contact) Consents() ConsentList { return c.consents }
+
+// SetConsent records whether this contact has consented to be messaged on the given channel and returns whether
+// that changed anything
+func (c *Contact) SetConsent(channel *Channel, status ConsentStatus, changedOn time.Time) bool {
+	consent := c.consents.Get(channel.UUID())
+	if consent != nil {
+		if consent.Status == status {
+			return false
+		}
+		consent.Status = status
+		consent.ChangedOn = changedOn
+		return true
+	}
+
+	c.consents = append(c.consents, NewConsent(channel.Reference(), status, changedOn))
+	return true
+}
+
+// HasConsent returns whether we can message this contact on the given channel, i.e. the channel doesn't require
+// consent or the contact has granted it
+func (c *Contact) HasConsent(channel *Channel) bool {
+	if !channel.RequiresConsent() {
+		return true
+	}
+
+	consent := c.consents.Get(channel.UUID())
+	return consent != nil && consent.Status == ConsentStatusGranted
+}
+
+// Tickets returns the tickets opened for this contact
+func (c *Contact) Tickets() TicketList { return c.tickets }
+
+// AddTicket adds the given newly opened ticket to this contact
+func (c *Contact) AddTicket(ticket *Ticket) {
+	c.tickets = append(c.tickets, ticket)
+}
+
 // Reference returns a reference to this contact
 func (c *Contact) Reference() *ContactReference {
 	if c == nil {
@@ -276,6 +320,8 @@ func (c *Contact) Resolve(env utils.Environment, key string) types.XValue {
 		return c.fields
 	case "channel":
 		return c.PreferredChannel()
+	case "tickets":
+		return c.tickets
 	}
 
 	return types.NewXResolveError(c, key)
@@ -291,7 +337,7 @@ func (c *Contact) Reduce(env utils.Environment) types.XPrimitive {
 
 // ToXJSON is called when this type is passed to @(json(...))
 func (c *Contact) ToXJSON(env utils.Environment) types.XText {
-	return types.ResolveKeys(env, c, "uuid", "name", "language", "timezone", "created_on", "urns", "groups", "fields", "channel").ToXJSON(env)
+	return types.ResolveKeys(env, c, "uuid", "name", "language", "timezone", "created_on", "urns", "groups", "fields", "channel", "tickets").ToXJSON(env)
 }
 
 var _ types.XValue = (*Contact)(nil)
@@ -319,6 +365,27 @@ func (c *Contact) ResolveDestinations(all bool) []Destination {
 	return destinations
 }
 
+// ResolveConsentedDestinations resolves the URN/channel destinations which can be sent to, skipping those on channels
+// the contact hasn't consented to. Those are returned separately, but only if sending to all destinations or if no
+// consented destination was found, as otherwise they didn't prevent sending.
+func (c *Contact) ResolveConsentedDestinations(all bool) ([]Destination, []Destination) {
+	consented := []Destination{}
+	unconsented := []Destination{}
+
+	for _, dest := range c.ResolveDestinations(true) {
+		if !c.HasConsent(dest.Channel) {
+			unconsented = append(unconsented, dest)
+			continue
+		}
+
+		consented = append(consented, dest)
+		if !all {
+			return consented, []Destination{}
+		}
+	}
+	return consented, unconsented
+}
+
 // PreferredURN gets the preferred URN for this contact, i.e. the URN we would use for sending
 func (c *Contact) PreferredURN() *ContactURN {
 	destinations := c.ResolveDestinations(false)
@@ -495,6 +562,8 @@ type contactEnvelope struct {
 	URNs      []urns.URN               `json:"urns,omitempty" validate:"dive,urn"`
 	Groups    []*assets.GroupReference `json:"groups,omitempty" validate:"dive"`
 	Fields    map[string]*Value        `json:"fields,omitempty"`
+	Consents  ConsentList              `json:"consents,omitempty" validate:"dive"`
+	Tickets   TicketList               `json:"tickets,omitempty" validate:"dive"`
 }
 
 // ReadContact decodes a contact from the passed in JSON
@@ -512,6 +581,8 @@ func ReadContact(sa SessionAssets, data json.RawMessage, missing assets.MissingC
 		name:      envelope.Name,
 		language:  envelope.Language,
 		createdOn: envelope.CreatedOn,
+		consents:  envelope.Consents,
+		tickets:   envelope.Tickets,
 		assets:    sa,
 	}
 
@@ -559,6 +630,8 @@ func (c *Contact) MarshalJSON() ([]byte, error) {
 		ID:        c.id,
 		Language:  c.language,
 		CreatedOn: c.createdOn,
+		Consents:  c.consents,
+		Tickets:   c.tickets,
 	}
 
 	ce.URNs = c.urns.RawURNs()
@@ -87,7 +87,7 @@ func TestContact(t *testing.T) {
 	assert.Equal(t, types.NewXResolveError(contact, "xxx"), contact.Resolve(env, "xxx"))
 	assert.Equal(t, types.NewXText("Joe Bloggs"), contact.Reduce(env))
 	assert.Equal(t, "contact", contact.Describe())
-	assert.Equal(t, types.NewXText(`{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"294a14d4-c998-41e5-a314-5941b97b89d7"},"created_on":"2017-12-15T10:00:00.000000Z","fields":{},"groups":[],"language":"eng","name":"Joe Bloggs","timezone":"UTC","urns":[{"display":"(636) 464-6466","path":"+16364646466","scheme":"tel"},{"display":"joey","path":"joey","scheme":"twitter"}],"uuid":"c00e5d67-c275-4389-aded-7d8b151cbd5b"}`), contact.ToXJSON(env))
+	assert.Equal(t, types.NewXText(`{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"294a14d4-c998-41e5-a314-5941b97b89d7"},"created_on":"2017-12-15T10:00:00.000000Z","fields":{},"groups":[],"language":"eng","name":"Joe Bloggs","tickets":[],"timezone":"UTC","urns":[{"display":"(636) 464-6466","path":"+16364646466","scheme":"tel"},{"display":"joey","path":"joey","scheme":"twitter"}],"uuid":"c00e5d67-c275-4389-aded-7d8b151cbd5b"}`), contact.ToXJSON(env))
 }
 
 func TestContactFormat(t *testing.T) {
@@ -153,6 +153,70 @@ func TestContactSetPreferredChannel(t *testing.T) {
 	assert.Equal(t, twitter, contact.URNs()[0].Channel())
 }
 
+func TestContactResolveConsentedDestinations(t *testing.T) {
+	source, err := static.NewSource([]byte(`{
+		"channels": [
+			{
+				"uuid": "294a14d4-c998-41e5-a314-5941b97b89d7",
+				"name": "WhatsApp",
+				"address": "+12345671111",
+				"schemes": ["whatsapp"],
+				"roles": ["send", "receive"],
+				"requires_consent": true
+			},
+			{
+				"uuid": "57f1078f-88aa-46f4-a59a-948a5739c03d",
+				"name": "Android",
+				"address": "+12345672222",
+				"schemes": ["tel"],
+				"roles": ["send", "receive"]
+			}
+		]
+	}`))
+	require.NoError(t, err)
+
+	sa, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	whatsapp := sa.Channels().Get("294a14d4-c998-41e5-a314-5941b97b89d7")
+
+	contact := flows.NewEmptyContact(sa, "Joe", utils.NilLanguage, nil)
+	contact.AddURN(flows.NewContactURN(urns.URN("whatsapp:12345678999"), nil))
+	contact.AddURN(flows.NewContactURN(urns.URN("tel:+12345678999"), nil))
+
+	// without consent the WhatsApp destination is skipped, but isn't reported as we could still send
+	destinations, unconsented := contact.ResolveConsentedDestinations(false)
+	assert.Equal(t, 1, len(destinations))
+	assert.Equal(t, urns.URN("tel:+12345678999"), destinations[0].URN.URN())
+	assert.Equal(t, 0, len(unconsented))
+
+	// unless we're sending to all destinations
+	destinations, unconsented = contact.ResolveConsentedDestinations(true)
+	assert.Equal(t, 1, len(destinations))
+	assert.Equal(t, 1, len(unconsented))
+	assert.Equal(t, whatsapp, unconsented[0].Channel)
+
+	// or there's no other destination
+	whatsappOnly := flows.NewEmptyContact(sa, "Jim", utils.NilLanguage, nil)
+	whatsappOnly.AddURN(flows.NewContactURN(urns.URN("whatsapp:12345678111"), nil))
+
+	destinations, unconsented = whatsappOnly.ResolveConsentedDestinations(false)
+	assert.Equal(t, 0, len(destinations))
+	assert.Equal(t, 1, len(unconsented))
+	assert.Equal(t, whatsapp, unconsented[0].Channel)
+
+	// once granted it's the preferred destination
+	contact.SetConsent(whatsapp, flows.ConsentStatusGranted, time.Date(2018, 7, 6, 12, 30, 0, 0, time.UTC))
+
+	destinations, unconsented = contact.ResolveConsentedDestinations(false)
+	assert.Equal(t, 1, len(destinations))
+	assert.Equal(t, whatsapp, destinations[0].Channel)
+	assert.Equal(t, 0, len(unconsented))
+
+	destinations, _ = contact.ResolveConsentedDestinations(true)
+	assert.Equal(t, 2, len(destinations))
+}
+
 func TestReevaluateDynamicGroups(t *testing.T) {
 	session, _, err := test.CreateTestSession("http://localhost", nil)
 	require.NoError(t, err)
@@ -0,0 +1,52 @@
+package flows
+
+// DebugStopType is the type of point in flow execution where a debugger is notified
+type DebugStopType string
+
+const (
+	// DebugStopNode is when a run has arrived at a node, before any of its actions are executed
+	DebugStopNode DebugStopType = "node"
+
+	// DebugStopAction is when a node's action is about to be executed
+	DebugStopAction DebugStopType = "action"
+
+	// DebugStopRouter is when a node's router is about to pick an exit
+	DebugStopRouter DebugStopType = "router"
+
+	// DebugStopExit is when a node's router has picked an exit
+	DebugStopExit DebugStopType = "exit"
+)
+
+// DebugStop describes a point in flow execution where a debugger is notified
+type DebugStop struct {
+	Type   DebugStopType
+	Sprint Sprint
+	Run    FlowRun
+	Node   Node
+	Step   Step
+
+	// the action about to be executed, for action stops
+	Action Action
+
+	// the operand that the router evaluated and the route it picked, for exit stops
+	Operand *string
+	Route   Route
+}
+
+// Exit returns the exit picked by the router, for exit stops
+func (s *DebugStop) Exit() Exit {
+	for _, exit := range s.Node.Exits() {
+		if exit.UUID() == s.Route.Exit() {
+			return exit
+		}
+	}
+	return nil
+}
+
+// Debugger is notified by the engine at each point in flow execution, and can pause execution by not returning until
+// it's ready for execution to continue. It's also notified when each sprint of a session ends, so it can release any
+// state it holds for that session.
+type Debugger interface {
+	Stop(*DebugStop)
+	EndSprint(Session)
+}
@@ -6,12 +6,15 @@ import (
 )
 
 type dependencies struct {
-	Channels []*assets.ChannelReference `json:"channels,omitempty"`
-	Contacts []*flows.ContactReference  `json:"contacts,omitempty"`
-	Fields   []*assets.FieldReference   `json:"fields,omitempty"`
-	Flows    []*assets.FlowReference    `json:"flows,omitempty"`
-	Groups   []*assets.GroupReference   `json:"groups,omitempty"`
-	Labels   []*assets.LabelReference   `json:"labels,omitempty"`
+	Channels    []*assets.ChannelReference    `json:"channels,omitempty"`
+	Classifiers []*assets.ClassifierReference `json:"classifiers,omitempty"`
+	Contacts    []*flows.ContactReference     `json:"contacts,omitempty"`
+	Fields      []*assets.FieldReference      `json:"fields,omitempty"`
+	Flows       []*assets.FlowReference       `json:"flows,omitempty"`
+	Groups      []*assets.GroupReference      `json:"groups,omitempty"`
+	Labels      []*assets.LabelReference      `json:"labels,omitempty"`
+	Templates   []*assets.TemplateReference   `json:"templates,omitempty"`
+	Ticketers   []*assets.TicketerReference   `json:"ticketers,omitempty"`
 }
 
 func newDependencies(refs []assets.Reference) *dependencies {
@@ -20,6 +23,8 @@ func newDependencies(refs []assets.Reference) *dependencies {
 		switch typed := r.(type) {
 		case *assets.ChannelReference:
 			d.Channels = append(d.Channels, typed)
+		case *assets.ClassifierReference:
+			d.Classifiers = append(d.Classifiers, typed)
 		case *flows.ContactReference:
 			d.Contacts = append(d.Contacts, typed)
 		case *assets.FieldReference:
@@ -30,6 +35,10 @@ func newDependencies(refs []assets.Reference) *dependencies {
 			d.Groups = append(d.Groups, typed)
 		case *assets.LabelReference:
 			d.Labels = append(d.Labels, typed)
+		case *assets.TemplateReference:
+			d.Templates = append(d.Templates, typed)
+		case *assets.TicketerReference:
+			d.Ticketers = append(d.Ticketers, typed)
 		}
 	}
 	return d
@@ -45,6 +54,14 @@ func (d *dependencies) refresh(sa flows.SessionAssets, missing assets.MissingCal
 			d.Channels[i] = a.Reference()
 		}
 	}
+	for i, ref := range d.Classifiers {
+		a := sa.Classifiers().Get(ref.UUID)
+		if a == nil {
+			missing(ref)
+		} else {
+			d.Classifiers[i] = a.Reference()
+		}
+	}
 	for i, ref := range d.Fields {
 		a := sa.Fields().Get(ref.Key)
 
@@ -82,4 +99,20 @@ func (d *dependencies) refresh(sa flows.SessionAssets, missing assets.MissingCal
 			d.Labels[i] = a.Reference()
 		}
 	}
+	for i, ref := range d.Templates {
+		a := sa.Templates().Get(ref.UUID)
+		if a == nil {
+			missing(ref)
+		} else {
+			d.Templates[i] = a.Reference()
+		}
+	}
+	for i, ref := range d.Ticketers {
+		a := sa.Ticketers().Get(ref.UUID)
+		if a == nil {
+			missing(ref)
+		} else {
+			d.Ticketers[i] = a.Reference()
+		}
+	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
 	"github.com/nyaruka/goflow/utils"
 
 	"github.com/Masterminds/semver"
@@ -28,6 +29,9 @@ type flow struct {
 	expireAfterMinutes int
 	localization       flows.Localization
 	nodes              []flows.Node
+	parameters         flows.ParamSchema
+	inputs             flows.ParamSchema
+	outputs            flows.OutputSchema
 
 	// optional properties not used by engine itself
 	ui           flows.UI
@@ -40,7 +44,7 @@ type flow struct {
 }
 
 // NewFlow creates a new flow
-func NewFlow(uuid assets.FlowUUID, name string, language utils.Language, flowType flows.FlowType, revision int, expireAfterMinutes int, localization flows.Localization, nodes []flows.Node, ui flows.UI) flows.Flow {
+func NewFlow(uuid assets.FlowUUID, name string, language utils.Language, flowType flows.FlowType, revision int, expireAfterMinutes int, localization flows.Localization, nodes []flows.Node, parameters flows.ParamSchema, inputs flows.ParamSchema, outputs flows.OutputSchema, ui flows.UI) flows.Flow {
 	f := &flow{
 		uuid:               uuid,
 		name:               name,
@@ -51,6 +55,9 @@ func NewFlow(uuid assets.FlowUUID, name string, language utils.Language, flowTyp
 		expireAfterMinutes: expireAfterMinutes,
 		localization:       localization,
 		nodes:              nodes,
+		parameters:         parameters,
+		inputs:             inputs,
+		outputs:            outputs,
 		nodeMap:            make(map[flows.NodeUUID]flows.Node, len(nodes)),
 		ui:                 ui,
 	}
@@ -71,6 +78,9 @@ func (f *flow) Type() flows.FlowType                   { return f.flowType }
 func (f *flow) ExpireAfterMinutes() int                { return f.expireAfterMinutes }
 func (f *flow) Nodes() []flows.Node                    { return f.nodes }
 func (f *flow) Localization() flows.Localization       { return f.localization }
+func (f *flow) Parameters() flows.ParamSchema          { return f.parameters }
+func (f *flow) Inputs() flows.ParamSchema              { return f.inputs }
+func (f *flow) Outputs() flows.OutputSchema            { return f.outputs }
 func (f *flow) UI() flows.UI                           { return f.ui }
 func (f *flow) GetNode(uuid flows.NodeUUID) flows.Node { return f.nodeMap[uuid] }
 
@@ -107,6 +117,24 @@ func (f *flow) validate(sa flows.SessionAssets, recursive bool) error {
 		}
 	}
 
+	// if we declare trigger parameters or inputs, expressions can only reference those
+	for _, template := range f.ExtractTemplates() {
+		if f.parameters != nil {
+			for _, key := range flows.ExtractTriggerParamReferences(template) {
+				if f.parameters.Get(key) == nil {
+					return errors.Errorf("template '%s' references undeclared param '%s'", template, key)
+				}
+			}
+		}
+		if f.inputs != nil {
+			for _, key := range flows.ExtractRunParamReferences(template) {
+				if f.inputs.Get(key) == nil {
+					return errors.Errorf("template '%s' references undeclared input '%s'", template, key)
+				}
+			}
+		}
+	}
+
 	// extract all dependencies (assets, contacts)
 	deps := newDependencies(f.ExtractDependencies())
 
@@ -122,6 +150,17 @@ func (f *flow) validate(sa flows.SessionAssets, recursive bool) error {
 			}
 			return errors.Errorf("missing dependencies: %s", strings.Join(depStrings, ","))
 		}
+
+		// check that flows we enter are given the params they declare, and only return outputs they declare
+		for _, node := range f.nodes {
+			for _, action := range node.Actions() {
+				if enterFlow, isEnterFlow := action.(*actions.EnterFlowAction); isEnterFlow {
+					if err := validateEnterFlow(sa, enterFlow); err != nil {
+						return errors.Wrapf(err, "validation failed for action[uuid=%s, type=%s]", action.UUID(), action.Type())
+					}
+				}
+			}
+		}
 	}
 
 	f.validated = true
@@ -143,6 +182,35 @@ func (f *flow) validate(sa flows.SessionAssets, recursive bool) error {
 	return nil
 }
 
+// checks the params and returns of an enter_flow action against the declarations of the flow it enters
+func validateEnterFlow(sa flows.SessionAssets, action *actions.EnterFlowAction) error {
+	child, err := sa.Flows().Get(action.Flow.UUID)
+	if err != nil {
+		return err
+	}
+
+	// flows which don't declare inputs can be entered with any params
+	if child.Inputs() != nil {
+		for _, key := range utils.SortedKeys(action.Params) {
+			if child.Inputs().Get(key) == nil {
+				return errors.Errorf("input '%s' isn't declared by %s", key, child.Reference())
+			}
+		}
+		for _, spec := range child.Inputs() {
+			if _, bound := action.Params[spec.Key]; spec.Required && !bound {
+				return errors.Errorf("missing required input '%s' for %s", spec.Key, child.Reference())
+			}
+		}
+	}
+
+	for _, key := range utils.SortedKeys(action.Returns) {
+		if child.Outputs().Get(key) == nil {
+			return errors.Errorf("output '%s' isn't declared by %s", key, child.Reference())
+		}
+	}
+	return nil
+}
+
 // Resolve resolves the given key when this flow is referenced in an expression
 func (f *flow) Resolve(env utils.Environment, key string) types.XValue {
 	switch strings.ToLower(key) {
@@ -268,13 +336,16 @@ type flowHeader struct {
 type flowEnvelope struct {
 	flowHeader
 
-	Language           utils.Language `json:"language" validate:"required"`
-	Type               flows.FlowType `json:"type" validate:"required,flow_type"`
-	Revision           int            `json:"revision"`
-	ExpireAfterMinutes int            `json:"expire_after_minutes"`
-	Localization       localization   `json:"localization"`
-	Nodes              []*node        `json:"nodes"`
-	UI                 *ui            `json:"_ui,omitempty"`
+	Language           utils.Language     `json:"language" validate:"required"`
+	Type               flows.FlowType     `json:"type" validate:"required,flow_type"`
+	Revision           int                `json:"revision"`
+	ExpireAfterMinutes int                `json:"expire_after_minutes"`
+	Localization       localization       `json:"localization"`
+	Nodes              []*node            `json:"nodes"`
+	Parameters         flows.ParamSchema  `json:"parameters,omitempty" validate:"dive"`
+	Inputs             flows.ParamSchema  `json:"inputs,omitempty" validate:"dive"`
+	Outputs            flows.OutputSchema `json:"outputs,omitempty" validate:"dive"`
+	UI                 *ui                `json:"_ui,omitempty"`
 }
 
 // additional properties that a validated flow can have
@@ -319,7 +390,7 @@ func ReadFlow(data json.RawMessage) (flows.Flow, error) {
 		e.Localization = make(localization)
 	}
 
-	return NewFlow(e.UUID, e.Name, e.Language, e.Type, e.Revision, e.ExpireAfterMinutes, e.Localization, nodes, nil), nil
+	return NewFlow(e.UUID, e.Name, e.Language, e.Type, e.Revision, e.ExpireAfterMinutes, e.Localization, nodes, e.Parameters, e.Inputs, e.Outputs, nil), nil
 }
 
 // MarshalJSON marshals this flow into JSON
@@ -336,6 +407,9 @@ func (f *flow) MarshalJSON() ([]byte, error) {
 		ExpireAfterMinutes: f.expireAfterMinutes,
 		Localization:       f.localization.(localization),
 		Nodes:              make([]*node, len(f.nodes)),
+		Parameters:         f.parameters,
+		Inputs:             f.inputs,
+		Outputs:            f.outputs,
 	}
 
 	if f.ui != nil {
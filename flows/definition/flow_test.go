@@ -40,10 +40,30 @@ var invalidFlows = []struct {
 		"flow_with_invalid_case_exit.json",
 		"validation failed for node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: validation failed for router: case exit 37d8813f-1402-4ad2-9cc2-e9054a96525b is not a valid exit",
 	},
+	{
+		"flow_with_invalid_wait_type.json",
+		"validation failed for node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: wait type 'dial' is not allowed in a flow of type 'messaging'",
+	},
+	{
+		"flow_with_invalid_wait_timeout.json",
+		"validation failed for node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: validation failed for wait: invalid timeout_expression: error evaluating @(datetime_diff(now(), , \"s\")): syntax error at , \"s\")",
+	},
 	{
 		"flow_with_missing_asset.json",
 		"missing dependencies: group[uuid=7be2f40b-38a0-4b06-9e6d-522dca592cc8,name=Registered]",
 	},
+	{
+		"flow_with_undeclared_param.json",
+		"template 'Your order @trigger.params.order_id will arrive on @trigger.params.delivery_date' references undeclared param 'delivery_date'",
+	},
+	{
+		"flow_with_mixed_case_param.json",
+		"template 'Your order @trigger.params.orderId will arrive on @trigger.params.DeliveryDate' references undeclared param 'DeliveryDate'",
+	},
+	{
+		"flow_with_undeclared_run_param.json",
+		"template 'Thanks @run.params.name, your order @run.params.order_id is on its way' references undeclared input 'order_id'",
+	},
 }
 
 func TestFlowValidation(t *testing.T) {
@@ -165,6 +185,7 @@ func TestNewFlow(t *testing.T) {
 						nil,
 						nil,
 						false,
+						nil,
 					),
 				},
 				waits.NewMsgWait(nil, hints.NewImageHint()),
@@ -207,6 +228,9 @@ func TestNewFlow(t *testing.T) {
 				},
 			),
 		},
+		nil, // no parameters
+		nil, // no inputs
+		nil, // no outputs
 		nil, // no UI
 	)
 
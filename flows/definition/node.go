@@ -73,6 +73,24 @@ func (n *node) Validate(flow flows.Flow, seenUUIDs map[utils.UUID]bool) error {
 		}
 	}
 
+	// check the wait, if there is one, is allowed in this flow type
+	if n.Wait() != nil {
+		isValidInType := false
+		for _, allowedType := range n.Wait().AllowedFlowTypes() {
+			if flow.Type() == allowedType {
+				isValidInType = true
+				break
+			}
+		}
+		if !isValidInType {
+			return errors.Errorf("wait type '%s' is not allowed in a flow of type '%s'", n.Wait().Type(), flow.Type())
+		}
+
+		if err := n.Wait().Validate(n.Exits()); err != nil {
+			return errors.Wrap(err, "validation failed for wait")
+		}
+	}
+
 	// check the router if there is one
 	if n.Router() != nil {
 		if err := n.Router().Validate(n.Exits()); err != nil {
@@ -119,7 +137,11 @@ func (n *node) EnumerateDependencies(localization flows.Localization, include fu
 }
 
 // EnumerateResultNames enumerates all result names on this object
-func (n *node) EnumerateResultNames(include func(string)) {}
+func (n *node) EnumerateResultNames(include func(string)) {
+	if wait, hasResultName := n.wait.(interface{ ResultName() string }); hasResultName && wait.ResultName() != "" {
+		include(wait.ResultName())
+	}
+}
 
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
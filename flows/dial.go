@@ -0,0 +1,62 @@
+package flows
+
+import (
+	"strings"
+
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+)
+
+// DialStatus is the outcome of a dial
+type DialStatus string
+
+// possible outcomes of a dial
+const (
+	DialStatusAnswered DialStatus = "answered"
+	DialStatusNoAnswer DialStatus = "no_answer"
+	DialStatusBusy     DialStatus = "busy"
+	DialStatusFailed   DialStatus = "failed"
+)
+
+// Dial describes the outcome of forwarding a voice call to another phone number. It renders as its status in a
+// template, and has the following properties which can be accessed:
+//
+//  * `status` the status of the dial, one of "answered", "no_answer", "busy" or "failed"
+//  * `duration` the duration of the forwarded call in seconds
+type Dial struct {
+	Status   DialStatus `json:"status" validate:"required,oneof=answered no_answer busy failed"`
+	Duration int        `json:"duration"`
+}
+
+// NewDial creates a new dial with the given status and duration
+func NewDial(status DialStatus, duration int) *Dial {
+	return &Dial{Status: status, Duration: duration}
+}
+
+// Resolve resolves the given key when this dial is referenced in an expression
+func (d *Dial) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "status":
+		return types.NewXText(string(d.Status))
+	case "duration":
+		return types.NewXNumberFromInt(d.Duration)
+	}
+
+	return types.NewXResolveError(d, key)
+}
+
+// Describe returns a representation of this type for error messages
+func (d *Dial) Describe() string { return "dial" }
+
+// Reduce is called when this object needs to be reduced to a primitive
+func (d *Dial) Reduce(env utils.Environment) types.XPrimitive {
+	return types.NewXText(string(d.Status))
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (d *Dial) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, d, "status", "duration").ToXJSON(env)
+}
+
+var _ types.XValue = (*Dial)(nil)
+var _ types.XResolvable = (*Dial)(nil)
@@ -10,13 +10,16 @@ import (
 type sessionAssets struct {
 	source assets.AssetSource
 
-	channels  *flows.ChannelAssets
-	fields    *flows.FieldAssets
-	flows     flows.FlowAssets
-	groups    *flows.GroupAssets
-	labels    *flows.LabelAssets
-	locations *flows.LocationAssets
-	resthooks *flows.ResthookAssets
+	channels    *flows.ChannelAssets
+	classifiers *flows.ClassifierAssets
+	fields      *flows.FieldAssets
+	flows       flows.FlowAssets
+	groups      *flows.GroupAssets
+	labels      *flows.LabelAssets
+	locations   *flows.LocationAssets
+	resthooks   *flows.ResthookAssets
+	templates   *flows.TemplateAssets
+	ticketers   *flows.TicketerAssets
 }
 
 var _ flows.SessionAssets = (*sessionAssets)(nil)
@@ -27,6 +30,10 @@ func NewSessionAssets(source assets.AssetSource) (flows.SessionAssets, error) {
 	if err != nil {
 		return nil, err
 	}
+	classifiers, err := source.Classifiers()
+	if err != nil {
+		return nil, err
+	}
 	fields, err := source.Fields()
 	if err != nil {
 		return nil, err
@@ -47,23 +54,37 @@ func NewSessionAssets(source assets.AssetSource) (flows.SessionAssets, error) {
 	if err != nil {
 		return nil, err
 	}
+	templates, err := source.Templates()
+	if err != nil {
+		return nil, err
+	}
+	ticketers, err := source.Ticketers()
+	if err != nil {
+		return nil, err
+	}
 
 	return &sessionAssets{
-		source:    source,
-		channels:  flows.NewChannelAssets(channels),
-		fields:    flows.NewFieldAssets(fields),
-		flows:     definition.NewFlowAssets(source),
-		groups:    flows.NewGroupAssets(groups),
-		labels:    flows.NewLabelAssets(labels),
-		locations: flows.NewLocationAssets(locations),
-		resthooks: flows.NewResthookAssets(resthooks),
+		source:      source,
+		channels:    flows.NewChannelAssets(channels),
+		classifiers: flows.NewClassifierAssets(classifiers),
+		fields:      flows.NewFieldAssets(fields),
+		flows:       definition.NewFlowAssets(source),
+		groups:      flows.NewGroupAssets(groups),
+		labels:      flows.NewLabelAssets(labels),
+		locations:   flows.NewLocationAssets(locations),
+		resthooks:   flows.NewResthookAssets(resthooks),
+		templates:   flows.NewTemplateAssets(templates),
+		ticketers:   flows.NewTicketerAssets(ticketers),
 	}, nil
 }
 
-func (s *sessionAssets) Channels() *flows.ChannelAssets   { return s.channels }
-func (s *sessionAssets) Fields() *flows.FieldAssets       { return s.fields }
-func (s *sessionAssets) Flows() flows.FlowAssets          { return s.flows }
-func (s *sessionAssets) Groups() *flows.GroupAssets       { return s.groups }
-func (s *sessionAssets) Labels() *flows.LabelAssets       { return s.labels }
-func (s *sessionAssets) Locations() *flows.LocationAssets { return s.locations }
-func (s *sessionAssets) Resthooks() *flows.ResthookAssets { return s.resthooks }
+func (s *sessionAssets) Channels() *flows.ChannelAssets       { return s.channels }
+func (s *sessionAssets) Classifiers() *flows.ClassifierAssets { return s.classifiers }
+func (s *sessionAssets) Fields() *flows.FieldAssets           { return s.fields }
+func (s *sessionAssets) Flows() flows.FlowAssets              { return s.flows }
+func (s *sessionAssets) Groups() *flows.GroupAssets           { return s.groups }
+func (s *sessionAssets) Labels() *flows.LabelAssets           { return s.labels }
+func (s *sessionAssets) Locations() *flows.LocationAssets     { return s.locations }
+func (s *sessionAssets) Resthooks() *flows.ResthookAssets     { return s.resthooks }
+func (s *sessionAssets) Templates() *flows.TemplateAssets     { return s.templates }
+func (s *sessionAssets) Ticketers() *flows.TicketerAssets     { return s.ticketers }
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+)
+
+// the first bytes of any gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// MarshalCompact marshals the given session into a compact gzipped encoding of its JSON. This can be read back with
+// Engine.ReadSession just like regular session JSON.
+func MarshalCompact(s flows.Session) ([]byte, error) {
+	sessionJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling session")
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	if _, err := writer.Write(sessionJSON); err != nil {
+		return nil, errors.Wrap(err, "error compressing session")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "error compressing session")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decodes the given session data if it has the compact encoding, otherwise returns it unchanged
+func decodeCompact(data []byte) (json.RawMessage, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decompress session")
+	}
+	defer reader.Close()
+
+	decompressed, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decompress session")
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+)
+
+// DebugCommand tells a stepping debugger how to proceed after it has paused
+type DebugCommand int
+
+const (
+	// DebugStep pauses again at the next stop
+	DebugStep DebugCommand = iota
+
+	// DebugContinue continues until the next breakpoint
+	DebugContinue
+)
+
+// SteppingDebugger is a debugger which pauses at breakpoints on node or action UUIDs, and can then step through
+// execution one action or router decision at a time. Pausing is handled by a callback which is called synchronously
+// with the stop and returns how to proceed. Whether we're stepping is tracked separately for each session so a
+// debugger can be shared by an engine running more than one session, and is reset when each sprint ends.
+type SteppingDebugger struct {
+	breakpoints map[utils.UUID]bool
+	stepping    bool
+	onPause     func(*flows.DebugStop) DebugCommand
+
+	// whether sessions which have paused during their current sprint are stepping
+	sessions map[flows.Session]bool
+	lock     sync.Mutex
+}
+
+// NewSteppingDebugger creates a new stepping debugger. If stepping is true, it pauses at the first stop of each sprint.
+func NewSteppingDebugger(stepping bool, onPause func(*flows.DebugStop) DebugCommand) *SteppingDebugger {
+	return &SteppingDebugger{
+		breakpoints: make(map[utils.UUID]bool),
+		stepping:    stepping,
+		onPause:     onPause,
+		sessions:    make(map[flows.Session]bool),
+	}
+}
+
+// SetStepping sets whether sprints which begin after this pause at their first stop
+func (d *SteppingDebugger) SetStepping(stepping bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.stepping = stepping
+}
+
+// AddBreakpoint adds a breakpoint on the given node or action UUID
+func (d *SteppingDebugger) AddBreakpoint(uuid utils.UUID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.breakpoints[uuid] = true
+}
+
+// RemoveBreakpoint removes the breakpoint on the given node or action UUID
+func (d *SteppingDebugger) RemoveBreakpoint(uuid utils.UUID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.breakpoints, uuid)
+}
+
+// Breakpoints returns the node or action UUIDs with breakpoints in sorted order
+func (d *SteppingDebugger) Breakpoints() []utils.UUID {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	uuids := make([]utils.UUID, 0, len(d.breakpoints))
+	for uuid := range d.breakpoints {
+		uuids = append(uuids, uuid)
+	}
+	sort.Slice(uuids, func(i, j int) bool { return uuids[i] < uuids[j] })
+	return uuids
+}
+
+// Stop is called by the engine at each stop, and pauses if the stop's session is stepping or has hit a breakpoint
+func (d *SteppingDebugger) Stop(stop *flows.DebugStop) {
+	session := stop.Run.Session()
+
+	d.lock.Lock()
+	pause := d.isStepping(session) || d.isBreakpoint(stop)
+	d.lock.Unlock()
+
+	if !pause {
+		return
+	}
+
+	// other sessions can carry on while this one is paused
+	stepping := d.onPause(stop) == DebugStep
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.sessions[session] = stepping
+}
+
+// EndSprint is called by the engine when a sprint of the given session ends, and forgets whether it was stepping
+func (d *SteppingDebugger) EndSprint(session flows.Session) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.sessions, session)
+}
+
+func (d *SteppingDebugger) isStepping(session flows.Session) bool {
+	if stepping, paused := d.sessions[session]; paused {
+		return stepping
+	}
+	return d.stepping
+}
+
+func (d *SteppingDebugger) isBreakpoint(stop *flows.DebugStop) bool {
+	switch stop.Type {
+	case flows.DebugStopNode:
+		return d.breakpoints[utils.UUID(stop.Node.UUID())]
+	case flows.DebugStopAction:
+		return d.breakpoints[utils.UUID(stop.Action.UUID())]
+	}
+	return false
+}
+
+var _ flows.Debugger = (*SteppingDebugger)(nil)
@@ -0,0 +1,137 @@
+package engine_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSteppingDebugger(t *testing.T) {
+	source, err := static.LoadSource("../../test/testdata/flows/two_questions.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	// describes each pause as type and UUID
+	describe := func(stop *flows.DebugStop) string {
+		switch stop.Type {
+		case flows.DebugStopAction:
+			return fmt.Sprintf("action:%s", stop.Action.UUID())
+		case flows.DebugStopExit:
+			return fmt.Sprintf("exit:%s", stop.Route.Exit())
+		}
+		return fmt.Sprintf("%s:%s", stop.Type, stop.Node.UUID())
+	}
+
+	run := func(debugger *engine.SteppingDebugger) {
+		eng := engine.NewBuilder().WithDebugger(debugger).Build()
+		session := eng.NewSession(sessionAssets)
+
+		contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+		_, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+		require.NoError(t, err)
+
+		msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "I like red", nil)
+		_, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err)
+	}
+
+	// stepping from the start pauses at every stop until we continue
+	pauses := make([]string, 0)
+	var exitStop *flows.DebugStop
+	debugger := engine.NewSteppingDebugger(true, func(stop *flows.DebugStop) engine.DebugCommand {
+		pauses = append(pauses, describe(stop))
+		if stop.Type == flows.DebugStopExit {
+			exitStop = stop
+			return engine.DebugContinue
+		}
+		return engine.DebugStep
+	})
+	run(debugger)
+
+	assert.Equal(t, []string{
+		"node:46d51f50-58de-49da-8d13-dadbf322685d",
+		"action:e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+		"router:46d51f50-58de-49da-8d13-dadbf322685d",
+		"exit:598ae7a5-2f81-48f1-afac-595262514aa1",
+	}, pauses)
+
+	// the exit stop tells us how the router made its decision
+	require.NotNil(t, exitStop)
+	assert.Equal(t, "Red", exitStop.Exit().Name())
+	assert.Equal(t, "I like red", *exitStop.Operand)
+	assert.Equal(t, "red", exitStop.Route.Match())
+
+	// without stepping, we only pause at breakpoints
+	pauses = make([]string, 0)
+	debugger = engine.NewSteppingDebugger(false, func(stop *flows.DebugStop) engine.DebugCommand {
+		pauses = append(pauses, describe(stop))
+		return engine.DebugContinue
+	})
+	debugger.AddBreakpoint("e97cd6d5-3354-4dbd-85bc-6c1f87849eec")
+	debugger.AddBreakpoint("11a772f3-3ca2-4429-8b33-20fdcfc2b69e")
+	debugger.AddBreakpoint("a3ad3ead-2a8c-4a1c-9ad4-4a0fa6ba2b45")
+	debugger.RemoveBreakpoint("a3ad3ead-2a8c-4a1c-9ad4-4a0fa6ba2b45")
+	assert.Equal(t, []utils.UUID{"11a772f3-3ca2-4429-8b33-20fdcfc2b69e", "e97cd6d5-3354-4dbd-85bc-6c1f87849eec"}, debugger.Breakpoints())
+
+	run(debugger)
+
+	assert.Equal(t, []string{
+		"action:e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+		"node:11a772f3-3ca2-4429-8b33-20fdcfc2b69e",
+	}, pauses)
+
+	// stepping in one session doesn't make another session on the same engine step
+	pauses = make([]string, 0)
+	debugger = engine.NewSteppingDebugger(false, func(stop *flows.DebugStop) engine.DebugCommand {
+		pauses = append(pauses, describe(stop))
+		return engine.DebugStep
+	})
+	debugger.AddBreakpoint("e97cd6d5-3354-4dbd-85bc-6c1f87849eec")
+
+	eng := engine.NewBuilder().WithDebugger(debugger).Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+
+	session1 := eng.NewSession(sessionAssets)
+	_, err = session1.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	session2 := eng.NewSession(sessionAssets)
+	_, err = session2.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"action:e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+		"action:e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+	}, pauses)
+
+	// and stepping ends with the sprint, so when resumed the first session only pauses at breakpoints again
+	pauses = make([]string, 0)
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "I like red", nil)
+	_, err = session1.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{}, pauses)
+
+	// unless we change whether new sprints begin stepping
+	debugger.SetStepping(true)
+	pauses = make([]string, 0)
+	_, err = session2.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, "router:46d51f50-58de-49da-8d13-dadbf322685d", pauses[0])
+}
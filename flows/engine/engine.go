@@ -2,6 +2,7 @@ package engine
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
@@ -11,18 +12,37 @@ import (
 // an instance of the engine
 type engine struct {
 	httpClient              *utils.HTTPClient
+	webhookService          flows.WebhookService
+	webhookCircuitBreaker   *flows.WebhookCircuitBreaker
+	classifierService       flows.ClassifierService
+	timeSource              utils.TimeSource
+	randSeed                *int64
+	eventHook               flows.EventHook
+	modifierHook            flows.ModifierHook
+	retentionPolicy         *flows.RetentionPolicy
+	debugger                flows.Debugger
 	disableWebhooks         bool
 	maxWebhookResponseBytes int
 	maxStepsPerSprint       int
+	maxEventsPerSprint      int
+	maxWebhookCalls         int
+	maxWebhookBytes         int
+	maxSprintDuration       time.Duration
 }
 
 // NewSession creates a new session
 func (e *engine) NewSession(sa flows.SessionAssets) flows.Session {
+	seed := utils.RandSeed()
+	if e.randSeed != nil {
+		seed = *e.randSeed
+	}
+
 	return &session{
 		engine:     e,
 		env:        utils.NewEnvironmentBuilder().Build(),
 		assets:     sa,
 		status:     flows.SessionStatusActive,
+		seed:       seed,
 		runsByUUID: make(map[flows.RunUUID]flows.FlowRun),
 	}
 }
@@ -32,10 +52,22 @@ func (e *engine) ReadSession(sa flows.SessionAssets, data json.RawMessage, missi
 	return readSession(e, sa, data, missing)
 }
 
-func (e *engine) HTTPClient() *utils.HTTPClient { return e.httpClient }
-func (e *engine) DisableWebhooks() bool         { return e.disableWebhooks }
-func (e *engine) MaxWebhookResponseBytes() int  { return e.maxWebhookResponseBytes }
-func (e *engine) MaxStepsPerSprint() int        { return e.maxStepsPerSprint }
+func (e *engine) HTTPClient() *utils.HTTPClient                       { return e.httpClient }
+func (e *engine) WebhookService() flows.WebhookService                { return e.webhookService }
+func (e *engine) WebhookCircuitBreaker() *flows.WebhookCircuitBreaker { return e.webhookCircuitBreaker }
+func (e *engine) ClassifierService() flows.ClassifierService          { return e.classifierService }
+func (e *engine) TimeSource() utils.TimeSource                        { return e.timeSource }
+func (e *engine) EventHook() flows.EventHook                          { return e.eventHook }
+func (e *engine) ModifierHook() flows.ModifierHook                    { return e.modifierHook }
+func (e *engine) RetentionPolicy() *flows.RetentionPolicy             { return e.retentionPolicy }
+func (e *engine) Debugger() flows.Debugger                            { return e.debugger }
+func (e *engine) DisableWebhooks() bool                               { return e.disableWebhooks }
+func (e *engine) MaxWebhookResponseBytes() int                        { return e.maxWebhookResponseBytes }
+func (e *engine) MaxStepsPerSprint() int                              { return e.maxStepsPerSprint }
+func (e *engine) MaxEventsPerSprint() int                             { return e.maxEventsPerSprint }
+func (e *engine) MaxWebhookCallsPerSprint() int                       { return e.maxWebhookCalls }
+func (e *engine) MaxWebhookBytesPerSprint() int                       { return e.maxWebhookBytes }
+func (e *engine) MaxSprintDuration() time.Duration                    { return e.maxSprintDuration }
 
 var _ flows.Engine = (*engine)(nil)
 
@@ -53,6 +85,9 @@ func NewBuilder() *Builder {
 	return &Builder{
 		eng: &engine{
 			httpClient:              utils.NewHTTPClient("goflow"),
+			webhookService:          flows.NewHTTPWebhookService(),
+			timeSource:              utils.GlobalTimeSource,
+			randSeed:                nil,
 			disableWebhooks:         false,
 			maxWebhookResponseBytes: 10000,
 			maxStepsPerSprint:       100,
@@ -66,6 +101,60 @@ func (b *Builder) WithDefaultUserAgent(userAgent string) *Builder {
 	return b
 }
 
+// WithWebhookService sets the service used to make webhook calls, which by default makes HTTP requests
+func (b *Builder) WithWebhookService(service flows.WebhookService) *Builder {
+	b.eng.webhookService = service
+	return b
+}
+
+// WithWebhookCircuitBreaker sets a circuit breaker to stop webhook calls to hosts which keep failing, which by default isn't set
+func (b *Builder) WithWebhookCircuitBreaker(breaker *flows.WebhookCircuitBreaker) *Builder {
+	b.eng.webhookCircuitBreaker = breaker
+	return b
+}
+
+// WithClassifierService sets the service used to classify text with classifiers, which by default isn't set
+func (b *Builder) WithClassifierService(service flows.ClassifierService) *Builder {
+	b.eng.classifierService = service
+	return b
+}
+
+// WithTimeSource sets the time source used by sessions, e.g. for results, run timestamps, wait timeouts and now()
+func (b *Builder) WithTimeSource(source utils.TimeSource) *Builder {
+	b.eng.timeSource = source
+	return b
+}
+
+// WithRandSeed sets the seed used for the random number generator of new sessions, which otherwise get a random seed
+func (b *Builder) WithRandSeed(seed int64) *Builder {
+	b.eng.randSeed = &seed
+	return b
+}
+
+// WithEventHook sets a function to be called as each event is generated, rather than when the sprint has finished
+func (b *Builder) WithEventHook(hook flows.EventHook) *Builder {
+	b.eng.eventHook = hook
+	return b
+}
+
+// WithModifierHook sets a function to be called as each modifier is generated, rather than when the sprint has finished
+func (b *Builder) WithModifierHook(hook flows.ModifierHook) *Builder {
+	b.eng.modifierHook = hook
+	return b
+}
+
+// WithRetentionPolicy sets the policy which controls how much history from previous sprints is kept in sessions
+func (b *Builder) WithRetentionPolicy(policy *flows.RetentionPolicy) *Builder {
+	b.eng.retentionPolicy = policy
+	return b
+}
+
+// WithDebugger sets a debugger to be notified at each node, action and router decision in sessions
+func (b *Builder) WithDebugger(debugger flows.Debugger) *Builder {
+	b.eng.debugger = debugger
+	return b
+}
+
 // WithDisableWebhooks sets whether webhooks are enabled
 func (b *Builder) WithDisableWebhooks(disable bool) *Builder {
 	b.eng.disableWebhooks = disable
@@ -84,5 +173,29 @@ func (b *Builder) WithMaxStepsPerSprint(max int) *Builder {
 	return b
 }
 
+// WithMaxEventsPerSprint sets the maximum number of events which can be generated in a single sprint, or zero for no limit
+func (b *Builder) WithMaxEventsPerSprint(max int) *Builder {
+	b.eng.maxEventsPerSprint = max
+	return b
+}
+
+// WithMaxWebhookCallsPerSprint sets the maximum number of webhook calls which can be made in a single sprint, or zero for no limit
+func (b *Builder) WithMaxWebhookCallsPerSprint(max int) *Builder {
+	b.eng.maxWebhookCalls = max
+	return b
+}
+
+// WithMaxWebhookBytesPerSprint sets the maximum total size of webhook responses in a single sprint, or zero for no limit
+func (b *Builder) WithMaxWebhookBytesPerSprint(max int) *Builder {
+	b.eng.maxWebhookBytes = max
+	return b
+}
+
+// WithMaxSprintDuration sets the maximum time that a single sprint can take, or zero for no limit
+func (b *Builder) WithMaxSprintDuration(max time.Duration) *Builder {
+	b.eng.maxSprintDuration = max
+	return b
+}
+
 // Build returns the final engine
 func (b *Builder) Build() flows.Engine { return b.eng }
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// CurrentSessionSpecVersion is the session spec version written by this library
+var CurrentSessionSpecVersion = semver.MustParse("1.1")
+
+// sessions written before we started versioning them are considered to be this version
+var unversionedSessionSpecVersion = semver.MustParse("1.0")
+
+// a migration which upgrades a generic session document to the given version
+type sessionMigration struct {
+	version *semver.Version
+	migrate func(map[string]interface{}) error
+}
+
+var sessionMigrations []*sessionMigration
+
+func registerSessionMigration(version string, migrate func(map[string]interface{}) error) {
+	sessionMigrations = append(sessionMigrations, &sessionMigration{version: semver.MustParse(version), migrate: migrate})
+
+	sort.SliceStable(sessionMigrations, func(i, j int) bool {
+		return sessionMigrations[i].version.LessThan(sessionMigrations[j].version)
+	})
+}
+
+func init() {
+	registerSessionMigration("1.1", migrateSessionTo1_1)
+}
+
+// 1.1 requires the session type and the seed for its random number generator
+func migrateSessionTo1_1(s map[string]interface{}) error {
+	if type_, _ := s["type"].(string); type_ == "" {
+		s["type"] = string(flows.FlowTypeMessaging)
+	}
+	if _, hasSeed := s["seed"]; !hasSeed {
+		s["seed"] = legacySessionSeed(s)
+	}
+	return nil
+}
+
+// derives a seed for a session written without one from the UUID of its first run, so that reading the same
+// session more than once always gives it the same random numbers
+func legacySessionSeed(s map[string]interface{}) int64 {
+	var runUUID string
+	if runs, _ := s["runs"].([]interface{}); len(runs) > 0 {
+		if run, _ := runs[0].(map[string]interface{}); run != nil {
+			runUUID, _ = run["uuid"].(string)
+		}
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(runUUID))
+	return int64(hash.Sum64() & utils.MaxRandSeed)
+}
+
+// the set of fields common to all session spec versions
+type sessionHeader struct {
+	SpecVersion *semver.Version `json:"spec_version"`
+}
+
+// upgrades the given session JSON to the current spec version if it was written by an older version of this library
+func migrateSession(data json.RawMessage) (json.RawMessage, error) {
+	header := &sessionHeader{}
+	if err := json.Unmarshal(data, header); err != nil {
+		return nil, errors.Wrap(err, "unable to read session header")
+	}
+
+	version := header.SpecVersion
+	if version == nil {
+		version = unversionedSessionSpecVersion
+	}
+
+	// can't do anything with a newer version than this library supports
+	if version.GreaterThan(CurrentSessionSpecVersion) {
+		return nil, errors.Errorf("session spec version %s is newer than this library (%s)", version, CurrentSessionSpecVersion)
+	}
+	if version.Equal(CurrentSessionSpecVersion) {
+		return data, nil
+	}
+
+	// decode as generic JSON, taking care to preserve numbers like our seed as they are
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	session := make(map[string]interface{})
+	if err := decoder.Decode(&session); err != nil {
+		return nil, errors.Wrap(err, "unable to read session")
+	}
+
+	for _, migration := range sessionMigrations {
+		if migration.version.GreaterThan(version) {
+			if err := migration.migrate(session); err != nil {
+				return nil, errors.Wrapf(err, "unable to migrate session to version %s", migration.version)
+			}
+		}
+	}
+
+	session["spec_version"] = CurrentSessionSpecVersion.String()
+
+	return json.Marshal(session)
+}
@@ -0,0 +1,92 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMigrations(t *testing.T) {
+	source, err := static.LoadSource("../../test/testdata/flows/two_questions.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	eng := engine.NewBuilder().Build()
+
+	// every historic session version should be readable and resumable
+	for _, version := range []string{"1.0", "1.1"} {
+		sessionJSON, err := ioutil.ReadFile("testdata/sessions/v" + version + ".json")
+		require.NoError(t, err)
+
+		session, err := eng.ReadSession(sessionAssets, sessionJSON, assets.PanicOnMissing)
+		require.NoError(t, err, "unable to read session version %s", version)
+
+		assert.Equal(t, flows.FlowTypeMessaging, session.Type(), "type mismatch for session version %s", version)
+		assert.Equal(t, flows.SessionStatusWaiting, session.Status(), "status mismatch for session version %s", version)
+
+		msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+12065551212"), nil, "I like red", nil)
+		_, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err, "unable to resume session version %s", version)
+
+		assert.Equal(t, "Red", session.Runs()[0].Results().Get("favorite_color").Category, "result mismatch for session version %s", version)
+
+		// and once re-saved, will be at the current version and have a seed
+		migrated, err := json.Marshal(session)
+		require.NoError(t, err)
+
+		header := &struct {
+			SpecVersion string `json:"spec_version"`
+			Seed        int64  `json:"seed"`
+		}{}
+		require.NoError(t, json.Unmarshal(migrated, header))
+		assert.Equal(t, engine.CurrentSessionSpecVersion.String(), header.SpecVersion)
+		assert.NotEqual(t, int64(0), header.Seed, "seed missing for session version %s", version)
+	}
+
+	// unversioned sessions were written without a seed, so are given one derived from their first run
+	sessionJSON, err := ioutil.ReadFile("testdata/sessions/v1.0.json")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sessionJSON), `"seed"`)
+
+	seeds := make([]int64, 2)
+	for i := range seeds {
+		session, err := eng.ReadSession(sessionAssets, sessionJSON, assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		marshaled, err := json.Marshal(session)
+		require.NoError(t, err)
+
+		header := &struct {
+			Seed int64 `json:"seed"`
+		}{}
+		require.NoError(t, json.Unmarshal(marshaled, header))
+		seeds[i] = header.Seed
+	}
+	assert.Equal(t, seeds[0], seeds[1], "seed of unversioned session should be the same each time it's read")
+
+	// the seed of a versioned session is preserved exactly
+	sessionJSON, err = ioutil.ReadFile("testdata/sessions/v1.1.json")
+	require.NoError(t, err)
+	session, err := eng.ReadSession(sessionAssets, sessionJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	marshaled, err := json.Marshal(session)
+	require.NoError(t, err)
+	assert.Contains(t, string(marshaled), `"seed":123456789`)
+
+	// can't read sessions from the future
+	_, err = eng.ReadSession(sessionAssets, json.RawMessage(`{"spec_version": "2.0", "type": "messaging"}`), assets.PanicOnMissing)
+	assert.EqualError(t, err, "session spec version 2.0.0 is newer than this library (1.1.0)")
+}
@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// RunRemap describes where a run in a session was moved to in the new revision of its flow, and whether it can be
+// resumed in the remapped session
+type RunRemap struct {
+	RunUUID   flows.RunUUID  `json:"run_uuid"`
+	FromNode  flows.NodeUUID `json:"from_node_uuid"`
+	ToNode    flows.NodeUUID `json:"to_node_uuid,omitempty"`
+	Resumable bool           `json:"resumable"`
+	Problem   string         `json:"problem,omitempty"`
+}
+
+// SessionRemap is the result of remapping a waiting session onto a new revision of a flow
+type SessionRemap struct {
+	Runs    []*RunRemap     `json:"runs"`
+	Session json.RawMessage `json:"session"`
+	Events  []flows.Event   `json:"events"`
+}
+
+// Resumable returns whether all the active runs in the session could be remapped, and so the session can be resumed
+func (r *SessionRemap) Resumable() bool {
+	for _, run := range r.Runs {
+		if !run.Resumable {
+			return false
+		}
+	}
+	return true
+}
+
+// RemapSession remaps the active runs of a waiting session which are in the given flow, onto the nodes of the given new
+// revision of that flow. Each run's current node is mapped using the explicit node mappings if one exists, then by
+// matching node UUIDs, and finally by matching the result name of its router. A waiting run begins waiting again on
+// its new node, so things like its timeout are taken from the new revision. The session should have been read with
+// the assets it was created with, and the returned session JSON should be read with assets which include the new
+// revision of the flow. If any run can't be remapped, then all active runs in the returned session are interrupted and
+// an error event is logged for each run which couldn't be remapped. Events logged by remapping are also returned so
+// that they can be handled like the events of a sprint.
+func RemapSession(s flows.Session, newFlow flows.Flow, nodeMappings map[flows.NodeUUID]flows.NodeUUID) (*SessionRemap, error) {
+	if s.Status() != flows.SessionStatusWaiting {
+		return nil, errors.Errorf("only waiting sessions can be remapped, session has status '%s'", s.Status())
+	}
+
+	remap := &SessionRemap{Runs: make([]*RunRemap, 0)}
+	locations := make(map[flows.RunUUID]flows.Node)
+
+	for _, run := range s.Runs() {
+		if run.Status() != flows.RunStatusActive && run.Status() != flows.RunStatusWaiting {
+			continue
+		}
+		if len(run.Path()) == 0 {
+			return nil, errors.Errorf("run %s has no location as path is empty", run.UUID())
+		}
+
+		runRemap := &RunRemap{RunUUID: run.UUID(), FromNode: run.Path()[len(run.Path())-1].NodeUUID()}
+		remap.Runs = append(remap.Runs, runRemap)
+
+		if run.Flow().UUID() != newFlow.UUID() {
+			// runs in other flows stay where they are
+			runRemap.ToNode = runRemap.FromNode
+			runRemap.Resumable = true
+			continue
+		}
+
+		var oldWait flows.Wait
+		if run.Status() == flows.RunStatusWaiting {
+			oldWait = s.Wait()
+		}
+
+		newNode, problem := remapNode(run.Flow().GetNode(runRemap.FromNode), runRemap.FromNode, oldWait, newFlow, nodeMappings)
+		if newNode != nil {
+			runRemap.ToNode = newNode.UUID()
+			runRemap.Resumable = true
+			locations[run.UUID()] = newNode
+		} else {
+			runRemap.Problem = problem
+		}
+	}
+
+	var remapped *session
+	var remapSprint *sprint
+	var err error
+
+	if remap.Resumable() {
+		if remapped, err = relocateRuns(s, locations); err != nil {
+			return nil, err
+		}
+		remapSprint = newSprintForSession(remapped)
+		remapped.beginWaits(remapSprint, remap, locations)
+	}
+
+	// if any run couldn't be remapped or begin waiting on its new node, then the whole session is interrupted instead
+	if !remap.Resumable() {
+		if remapped, err = copySession(s, nil); err != nil {
+			return nil, err
+		}
+		remapSprint = newSprintForSession(remapped)
+		remapped.interrupt(remapSprint, remap)
+	}
+
+	if remap.Session, err = json.Marshal(remapped); err != nil {
+		return nil, errors.Wrap(err, "error marshaling remapped session")
+	}
+	remap.Events = remapSprint.Events()
+
+	return remap, nil
+}
+
+// finds the node in the new flow which is equivalent to the given old node, or returns a description of why there isn't one
+func remapNode(oldNode flows.Node, oldNodeUUID flows.NodeUUID, oldWait flows.Wait, newFlow flows.Flow, nodeMappings map[flows.NodeUUID]flows.NodeUUID) (flows.Node, string) {
+	var newNode flows.Node
+
+	if mapped, hasMapping := nodeMappings[oldNodeUUID]; hasMapping {
+		if newNode = newFlow.GetNode(mapped); newNode == nil {
+			return nil, fmt.Sprintf("mapped node %s doesn't exist in new flow", mapped)
+		}
+	} else if newNode = newFlow.GetNode(oldNodeUUID); newNode == nil {
+		if oldNode == nil || oldNode.Router() == nil || oldNode.Router().ResultName() == "" {
+			return nil, "node doesn't exist in new flow and has no result name to match on"
+		}
+
+		resultKey := utils.Snakify(oldNode.Router().ResultName())
+
+		for _, candidate := range newFlow.Nodes() {
+			if candidate.Router() != nil && utils.Snakify(candidate.Router().ResultName()) == resultKey {
+				if newNode != nil {
+					return nil, fmt.Sprintf("multiple nodes in new flow match result name '%s'", oldNode.Router().ResultName())
+				}
+				newNode = candidate
+			}
+		}
+		if newNode == nil {
+			return nil, fmt.Sprintf("no node in new flow matches result name '%s'", oldNode.Router().ResultName())
+		}
+	}
+
+	// the run will be resumed by having the new node pick an exit, so it needs to be waiting in the same way
+	if newNode.Router() == nil {
+		return nil, fmt.Sprintf("node %s in new flow has no router", newNode.UUID())
+	}
+	if oldWait != nil && (newNode.Wait() == nil || newNode.Wait().Type() != oldWait.Type()) {
+		return nil, fmt.Sprintf("node %s in new flow doesn't have a %s wait", newNode.UUID(), oldWait.Type())
+	}
+	if oldWait == nil && oldNode != nil && oldNode.Router() != nil && newNode.Router().Type() != oldNode.Router().Type() {
+		return nil, fmt.Sprintf("node %s in new flow doesn't have a %s router", newNode.UUID(), oldNode.Router().Type())
+	}
+
+	return newNode, ""
+}
+
+// creates a copy of the given session, optionally rewriting its JSON first
+func copySession(s flows.Session, rewrite func(map[string]json.RawMessage) error) (*session, error) {
+	sessionJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling session")
+	}
+
+	if rewrite != nil {
+		sessionFields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(sessionJSON, &sessionFields); err != nil {
+			return nil, err
+		}
+		if err := rewrite(sessionFields); err != nil {
+			return nil, err
+		}
+		if sessionJSON, err = json.Marshal(sessionFields); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionCopy, err := readSession(s.Engine(), s.Assets(), sessionJSON, assets.IgnoreMissing)
+	if err != nil {
+		return nil, errors.Wrap(err, "error copying session")
+	}
+	return sessionCopy.(*session), nil
+}
+
+// creates a copy of the given session where the last step of each of the given runs is at the given node
+func relocateRuns(s flows.Session, locations map[flows.RunUUID]flows.Node) (*session, error) {
+	return copySession(s, func(sessionFields map[string]json.RawMessage) error {
+		runsJSON := make([]json.RawMessage, 0)
+		if err := json.Unmarshal(sessionFields["runs"], &runsJSON); err != nil {
+			return err
+		}
+
+		var err error
+		for i := range runsJSON {
+			run := &struct {
+				UUID flows.RunUUID                `json:"uuid"`
+				Path []map[string]json.RawMessage `json:"path"`
+			}{}
+			if err := json.Unmarshal(runsJSON[i], run); err != nil {
+				return err
+			}
+
+			node, relocated := locations[run.UUID]
+			if !relocated {
+				continue
+			}
+
+			runFields := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(runsJSON[i], &runFields); err != nil {
+				return err
+			}
+
+			run.Path[len(run.Path)-1]["node_uuid"], _ = json.Marshal(node.UUID())
+
+			if runFields["path"], err = json.Marshal(run.Path); err != nil {
+				return err
+			}
+			if runsJSON[i], err = json.Marshal(runFields); err != nil {
+				return err
+			}
+		}
+
+		sessionFields["runs"], err = json.Marshal(runsJSON)
+		return err
+	})
+}
+
+// begins the waits of the new nodes of any relocated waiting runs, so that things like timeouts are set by the new nodes
+func (s *session) beginWaits(sprint flows.Sprint, remap *SessionRemap, locations map[flows.RunUUID]flows.Node) {
+	for _, runRemap := range remap.Runs {
+		node, relocated := locations[runRemap.RunUUID]
+		run := s.runsByUUID[runRemap.RunUUID]
+		if !relocated || run.Status() != flows.RunStatusWaiting {
+			continue
+		}
+
+		step := run.Path()[len(run.Path())-1]
+		logEvent := func(e flows.Event) {
+			run.LogEvent(step, e)
+			sprint.LogEvent(e)
+		}
+
+		// like when visiting a node, we wait on a copy of the wait so its state isn't shared with other sessions
+		wait, err := copyWait(node.Wait())
+		if err != nil {
+			runRemap.Resumable = false
+			runRemap.Problem = fmt.Sprintf("unable to begin wait on node %s in new flow: %s", node.UUID(), err)
+			continue
+		}
+
+		if wait.Begin(run, logEvent) {
+			s.wait = wait
+		} else {
+			runRemap.Resumable = false
+			runRemap.Problem = fmt.Sprintf("unable to begin wait on node %s in new flow", node.UUID())
+		}
+	}
+}
+
+// interrupts all the active runs in this session, logging an error event for each run which couldn't be remapped
+func (s *session) interrupt(sprint flows.Sprint, remap *SessionRemap) {
+	for _, runRemap := range remap.Runs {
+		run := s.runsByUUID[runRemap.RunUUID]
+
+		if runRemap.Problem != "" {
+			step := run.Path()[len(run.Path())-1]
+			event := events.NewErrorEventf("session interrupted as run couldn't be remapped: %s", runRemap.Problem)
+			run.LogEvent(step, event)
+			sprint.LogEvent(event)
+		}
+
+		// none of the runs can be resumed now
+		runRemap.Resumable = false
+	}
+
+	for _, run := range s.runs {
+		if run.Status() == flows.RunStatusActive || run.Status() == flows.RunStatusWaiting {
+			run.Exit(flows.RunStatusInterrupted)
+		}
+	}
+	s.status = flows.SessionStatusCompleted
+	s.wait = nil
+}
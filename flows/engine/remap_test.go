@@ -0,0 +1,160 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemapSession(t *testing.T) {
+	oldAssetsJSON, err := ioutil.ReadFile("testdata/timeout_test.json")
+	require.NoError(t, err)
+
+	flowUUID := assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02")
+	oldNodeUUID := flows.NodeUUID("46d51f50-58de-49da-8d13-dadbf322685d")
+	newNodeUUID := flows.NodeUUID("a58be63b-907d-4a1a-856b-0bb5579d7507")
+
+	readAssets := func(assetsJSON string) flows.SessionAssets {
+		source, err := static.NewSource([]byte(assetsJSON))
+		require.NoError(t, err)
+		sa, err := engine.NewSessionAssets(source)
+		require.NoError(t, err)
+		return sa
+	}
+
+	now := time.Date(2018, 9, 13, 13, 36, 30, 123456789, time.UTC)
+	eng := engine.NewBuilder().WithTimeSource(utils.NewFixedTimeSource(now)).Build()
+
+	// starts a session with the given original flow which will be waiting at the question node
+	startSession := func(uuid assets.FlowUUID) flows.Session {
+		sa := readAssets(string(oldAssetsJSON))
+		flow, err := sa.Flows().Get(uuid)
+		require.NoError(t, err)
+
+		session := eng.NewSession(sa)
+		_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), flows.NewEmptyContact(sa, "Joe", "eng", nil), nil))
+		require.NoError(t, err)
+		require.Equal(t, flows.SessionStatusWaiting, session.Status())
+		return session
+	}
+
+	// a new revision of the flow where the question node has a new UUID and a longer timeout
+	newAssetsJSON := strings.Replace(string(oldAssetsJSON), string(oldNodeUUID), string(newNodeUUID), -1)
+	newAssetsJSON = strings.Replace(newAssetsJSON, `"timeout": 600`, `"timeout": 1200`, -1)
+	newAssets := readAssets(newAssetsJSON)
+	newFlow, err := newAssets.Flows().Get(flowUUID)
+	require.NoError(t, err)
+
+	// which can be matched by the result name of its router
+	session := startSession(flowUUID)
+	remap, err := engine.RemapSession(session, newFlow, nil)
+	require.NoError(t, err)
+
+	assert.True(t, remap.Resumable())
+	assert.Equal(t, []*engine.RunRemap{
+		{RunUUID: session.Runs()[0].UUID(), FromNode: oldNodeUUID, ToNode: newNodeUUID, Resumable: true},
+	}, remap.Runs)
+
+	// and the remapped session has begun waiting again with the new timeout
+	remapped, err := eng.ReadSession(newAssets, remap.Session, assets.PanicOnMissing)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Second*1200), *remapped.Wait().TimeoutOn())
+	require.Equal(t, 1, len(remap.Events))
+	assert.Equal(t, events.TypeMsgWait, remap.Events[0].Type())
+	assert.Equal(t, remap.Events[0], remapped.Runs()[0].Events()[len(remapped.Runs()[0].Events())-1])
+
+	// without beginning the wait of the new flow itself which other sessions could be using
+	assert.Nil(t, newFlow.GetNode(newNodeUUID).Wait().TimeoutOn())
+
+	// and can be resumed with the new revision
+
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "red", nil)
+	_, err = remapped.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusCompleted, remapped.Status())
+	assert.Equal(t, "Red", remapped.Runs()[0].Results().Get("favorite_color").Category)
+	assert.Equal(t, newNodeUUID, remapped.Runs()[0].Path()[0].NodeUUID())
+
+	// if the result name has changed too, the run can't be remapped
+	renamedAssets := readAssets(strings.Replace(newAssetsJSON, `"Favorite Color"`, `"Colour"`, -1))
+	renamedFlow, err := renamedAssets.Flows().Get(flowUUID)
+	require.NoError(t, err)
+
+	session = startSession(flowUUID)
+	remap, err = engine.RemapSession(session, renamedFlow, nil)
+	require.NoError(t, err)
+
+	assert.False(t, remap.Resumable())
+	assert.Equal(t, []*engine.RunRemap{
+		{RunUUID: session.Runs()[0].UUID(), FromNode: oldNodeUUID, Problem: "no node in new flow matches result name 'Favorite Color'"},
+	}, remap.Runs)
+
+	// in which case the remapped session has its runs interrupted
+	interrupted, err := eng.ReadSession(renamedAssets, remap.Session, assets.PanicOnMissing)
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, interrupted.Status())
+	assert.Nil(t, interrupted.Wait())
+	assert.Equal(t, flows.RunStatusInterrupted, interrupted.Runs()[0].Status())
+	assert.NotNil(t, interrupted.Runs()[0].ExitedOn())
+
+	// with an error event recording why
+	require.Equal(t, 1, len(remap.Events))
+	assert.Equal(t, "session interrupted as run couldn't be remapped: no node in new flow matches result name 'Favorite Color'", remap.Events[0].(*events.ErrorEvent).Text)
+	assert.Equal(t, events.TypeError, interrupted.Runs()[0].Events()[len(interrupted.Runs()[0].Events())-1].Type())
+
+	// and the original session is unchanged
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, flows.RunStatusWaiting, session.Runs()[0].Status())
+
+	// unless we provide an explicit mapping
+	remap, err = engine.RemapSession(session, renamedFlow, map[flows.NodeUUID]flows.NodeUUID{oldNodeUUID: newNodeUUID})
+	require.NoError(t, err)
+	assert.True(t, remap.Resumable())
+	assert.Equal(t, newNodeUUID, remap.Runs[0].ToNode)
+
+	// mappings have to be to nodes which exist and wait in the same way
+	remap, err = engine.RemapSession(session, renamedFlow, map[flows.NodeUUID]flows.NodeUUID{oldNodeUUID: "11a772f3-3ca2-4429-8b33-20fdcfc2b69e"})
+	require.NoError(t, err)
+	assert.False(t, remap.Resumable())
+	assert.Equal(t, "node 11a772f3-3ca2-4429-8b33-20fdcfc2b69e in new flow has no router", remap.Runs[0].Problem)
+
+	remap, err = engine.RemapSession(session, renamedFlow, map[flows.NodeUUID]flows.NodeUUID{oldNodeUUID: "33b27d8c-3d49-4384-9d3b-5e8d2f1c5ed4"})
+	require.NoError(t, err)
+	assert.Equal(t, "mapped node 33b27d8c-3d49-4384-9d3b-5e8d2f1c5ed4 doesn't exist in new flow", remap.Runs[0].Problem)
+
+	// if a session has runs in other flows, they can't be resumed either if the session is interrupted
+	session = startSession("b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")
+	remap, err = engine.RemapSession(session, renamedFlow, nil)
+	require.NoError(t, err)
+
+	parentNodeUUID := flows.NodeUUID("c0ee1c2e-3b4f-4d5c-8f2a-3e1f4a5b6c7d")
+	assert.Equal(t, []*engine.RunRemap{
+		{RunUUID: session.Runs()[0].UUID(), FromNode: parentNodeUUID, ToNode: parentNodeUUID, Resumable: false},
+		{RunUUID: session.Runs()[1].UUID(), FromNode: oldNodeUUID, Problem: "no node in new flow matches result name 'Favorite Color'"},
+	}, remap.Runs)
+
+	// only waiting sessions can be remapped
+	_, err = engine.RemapSession(remapped, newFlow, nil)
+	assert.EqualError(t, err, "only waiting sessions can be remapped, session has status 'completed'")
+
+	// check the remap itself can be marshaled for reporting
+	remapJSON, err := json.Marshal(remap.Runs)
+	require.NoError(t, err)
+	assert.Contains(t, string(remapJSON), `"resumable":false`)
+}
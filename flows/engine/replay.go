@@ -0,0 +1,318 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Repro describes the trigger and resumes needed to reproduce a session
+type Repro struct {
+	Trigger flows.Trigger  `json:"trigger"`
+	Resumes []flows.Resume `json:"resumes"`
+}
+
+type reproEnvelope struct {
+	Trigger json.RawMessage   `json:"trigger" validate:"required"`
+	Resumes []json.RawMessage `json:"resumes"`
+}
+
+// ReadRepro reads a repro from the given JSON
+func ReadRepro(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (*Repro, error) {
+	e := &reproEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, errors.Wrap(err, "unable to read repro")
+	}
+
+	repro := &Repro{Resumes: make([]flows.Resume, len(e.Resumes))}
+	var err error
+
+	if repro.Trigger, err = triggers.ReadTrigger(sessionAssets, e.Trigger, missing); err != nil {
+		return nil, errors.Wrap(err, "unable to read trigger")
+	}
+	for i := range e.Resumes {
+		if repro.Resumes[i], err = resumes.ReadResume(sessionAssets, e.Resumes[i], missing); err != nil {
+			return nil, errors.Wrapf(err, "unable to read resume %d", i)
+		}
+	}
+	return repro, nil
+}
+
+// ReplayConfig controls the clock and seeds used when replaying a repro, and whether webhooks are really called, which
+// by default they aren't so that a replay doesn't have side effects and doesn't depend on the responses of live services
+type ReplayConfig struct {
+	StartTime    time.Time
+	Seed         int64
+	LiveWebhooks bool
+}
+
+// DefaultReplayConfig is the replay config used when none is provided
+var DefaultReplayConfig = &ReplayConfig{
+	StartTime: time.Date(2018, 7, 6, 12, 30, 0, 123456789, time.UTC),
+	Seed:      123456,
+}
+
+// SprintOutput is the session and events produced by a single sprint of a replay
+type SprintOutput struct {
+	Session json.RawMessage   `json:"session"`
+	Events  []json.RawMessage `json:"events"`
+}
+
+// Replay re-runs the given repro against the given assets, returning the output of each sprint. The clock, UUID
+// generator and random seed are all fixed for the duration of the replay so that the same repro will always produce the
+// same output. Because the UUID generator is process-wide, replays shouldn't be run concurrently. It's an error for the
+// repro or the sessions it produces to reference assets which don't exist.
+func Replay(sessionAssets flows.SessionAssets, repro *Repro, config *ReplayConfig) ([]*SprintOutput, error) {
+	if config == nil {
+		config = DefaultReplayConfig
+	}
+
+	// read our own copy of the trigger and resumes since a session can modify them
+	reproJSON, err := json.Marshal(repro)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling repro")
+	}
+	missing, checkMissing := CollectMissing()
+
+	repro, err = ReadRepro(sessionAssets, reproJSON, missing)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMissing(); err != nil {
+		return nil, errors.Wrap(err, "unable to read repro")
+	}
+
+	// the clock and random seed belong to the engine but UUIDs are generated process-wide
+	previousUUIDs := utils.SetUUIDGenerator(utils.NewSeededUUID4Generator(config.Seed))
+	defer utils.SetUUIDGenerator(previousUUIDs)
+
+	eng := NewBuilder().
+		WithDefaultUserAgent("goflow-replay").
+		WithTimeSource(utils.NewSequentialTimeSource(config.StartTime)).
+		WithRandSeed(config.Seed).
+		WithDisableWebhooks(!config.LiveWebhooks).
+		Build()
+
+	session := eng.NewSession(sessionAssets)
+
+	sprint, err := session.Start(repro.Trigger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting session")
+	}
+
+	outputs := make([]*SprintOutput, 0, len(repro.Resumes)+1)
+
+	for r, resume := range repro.Resumes {
+		output, err := newSprintOutput(session, sprint)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+
+		// reload the session from its JSON just as a caller would between sprints
+		if session, err = eng.ReadSession(sessionAssets, output.Session, missing); err != nil {
+			return nil, errors.Wrap(err, "error reading session")
+		}
+		if err := checkMissing(); err != nil {
+			return nil, errors.Wrap(err, "error reading session")
+		}
+
+		if session.Wait() == nil {
+			return nil, errors.Errorf("session isn't waiting but repro has %d unused resumes", len(repro.Resumes)-r)
+		}
+
+		if sprint, err = session.Resume(resume); err != nil {
+			return nil, errors.Wrapf(err, "error resuming session with resume %d", r)
+		}
+	}
+
+	output, err := newSprintOutput(session, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(outputs, output), nil
+}
+
+// CollectMissing returns a missing callback which records the assets it's called with, and a function which returns an
+// error if there were any
+func CollectMissing() (assets.MissingCallback, func() error) {
+	missing := make([]string, 0)
+	callback := func(a assets.Reference) { missing = append(missing, a.String()) }
+	check := func() error {
+		if len(missing) > 0 {
+			return errors.Errorf("missing assets: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+	return callback, check
+}
+
+func newSprintOutput(session flows.Session, sprint flows.Sprint) (*SprintOutput, error) {
+	sessionJSON, err := utils.JSONMarshal(session)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling session")
+	}
+
+	eventsJSON := make([]json.RawMessage, len(sprint.Events()))
+	for i, event := range sprint.Events() {
+		if eventsJSON[i], err = utils.JSONMarshal(event); err != nil {
+			return nil, errors.Wrap(err, "error marshaling event")
+		}
+	}
+
+	return &SprintOutput{Session: sessionJSON, Events: eventsJSON}, nil
+}
+
+// the sides of a replay comparison which can be missing a value
+const (
+	ReplayMissingExpected = "expected"
+	ReplayMissingActual   = "actual"
+)
+
+// ReplayDifference is a difference between the recorded and replayed output of a sprint. If one side doesn't have a
+// value at the path at all, as opposed to having a null value, then Missing is that side.
+type ReplayDifference struct {
+	Sprint   int         `json:"sprint"`
+	Path     string      `json:"path"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+	Missing  string      `json:"missing,omitempty"`
+}
+
+func (d *ReplayDifference) String() string {
+	expected, _ := json.Marshal(d.Expected)
+	actual, _ := json.Marshal(d.Actual)
+
+	switch d.Missing {
+	case ReplayMissingExpected:
+		return fmt.Sprintf("sprint[%d] %s: expected nothing, got %s", d.Sprint, d.Path, actual)
+	case ReplayMissingActual:
+		return fmt.Sprintf("sprint[%d] %s: expected %s, got nothing", d.Sprint, d.Path, expected)
+	}
+	return fmt.Sprintf("sprint[%d] %s: expected %s, got %s", d.Sprint, d.Path, expected, actual)
+}
+
+// CompareReplay compares the expected output of a replay with the actual output, returning any differences
+func CompareReplay(expected []*SprintOutput, actual []*SprintOutput) ([]*ReplayDifference, error) {
+	expectedGeneric, err := outputsToGeneric(expected)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read expected output")
+	}
+	actualGeneric, err := outputsToGeneric(actual)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read actual output")
+	}
+
+	differences := make([]*ReplayDifference, 0)
+
+	for s := 0; s < len(expectedGeneric) || s < len(actualGeneric); s++ {
+		onDiff := func(path string, e, a interface{}, missing string) {
+			differences = append(differences, &ReplayDifference{Sprint: s, Path: path, Expected: e, Actual: a, Missing: missing})
+		}
+
+		if s >= len(actualGeneric) {
+			onDiff("", expectedGeneric[s], nil, ReplayMissingActual)
+		} else if s >= len(expectedGeneric) {
+			onDiff("", nil, actualGeneric[s], ReplayMissingExpected)
+		} else {
+			compareJSON(expectedGeneric[s], actualGeneric[s], "", onDiff)
+		}
+	}
+
+	return differences, nil
+}
+
+// converts sprint outputs to generic JSON values so they can be compared structurally, with numbers kept as their JSON
+// text so that large integers like seeds don't lose precision
+func outputsToGeneric(outputs []*SprintOutput) ([]interface{}, error) {
+	asJSON, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(asJSON))
+	decoder.UseNumber()
+
+	var generic []interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// recursively compares two generic JSON values, calling onDiff with the path of each difference and which side, if any,
+// is missing a value
+func compareJSON(expected interface{}, actual interface{}, path string, onDiff func(string, interface{}, interface{}, string)) {
+	switch typedExpected := expected.(type) {
+	case map[string]interface{}:
+		typedActual, isMap := actual.(map[string]interface{})
+		if !isMap {
+			break
+		}
+
+		keys := make([]string, 0, len(typedExpected))
+		for k := range typedExpected {
+			keys = append(keys, k)
+		}
+		for k := range typedActual {
+			if _, seen := typedExpected[k]; !seen {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			keyPath := k
+			if path != "" {
+				keyPath = path + "." + k
+			}
+
+			e, inExpected := typedExpected[k]
+			a, inActual := typedActual[k]
+			if !inActual {
+				onDiff(keyPath, e, nil, ReplayMissingActual)
+			} else if !inExpected {
+				onDiff(keyPath, nil, a, ReplayMissingExpected)
+			} else {
+				compareJSON(e, a, keyPath, onDiff)
+			}
+		}
+		return
+
+	case []interface{}:
+		typedActual, isSlice := actual.([]interface{})
+		if !isSlice {
+			break
+		}
+
+		for i := 0; i < len(typedExpected) || i < len(typedActual); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+			if i >= len(typedActual) {
+				onDiff(itemPath, typedExpected[i], nil, ReplayMissingActual)
+			} else if i >= len(typedExpected) {
+				onDiff(itemPath, nil, typedActual[i], ReplayMissingExpected)
+			} else {
+				compareJSON(typedExpected[i], typedActual[i], itemPath, onDiff)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		onDiff(path, expected, actual, "")
+	}
+}
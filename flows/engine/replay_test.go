@@ -0,0 +1,156 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reproJSON = `{
+	"trigger": {
+		"type": "manual",
+		"flow": {"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "name": "Question With Timeout"},
+		"contact": {
+			"uuid": "5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f",
+			"name": "Ryan Lewis",
+			"language": "eng",
+			"urns": ["tel:+12065551212"],
+			"created_on": "2018-06-20T11:40:30.123456789-00:00"
+		},
+		"triggered_on": "2018-10-20T09:49:31.23456789Z"
+	},
+	"resumes": [
+		{
+			"type": "msg",
+			"msg": {"uuid": "9bf91c2b-ce58-4cef-aacc-281e03f69ab5", "urn": "tel:+12065551212", "text": "I like red"},
+			"resumed_on": "2018-10-20T09:50:31.23456789Z"
+		}
+	]
+}`
+
+func TestReplay(t *testing.T) {
+	assetsJSON, err := ioutil.ReadFile("testdata/timeout_test.json")
+	require.NoError(t, err)
+
+	source, err := static.NewSource(assetsJSON)
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	repro, err := engine.ReadRepro(sessionAssets, json.RawMessage(reproJSON), assets.PanicOnMissing)
+	require.NoError(t, err)
+	assert.Equal(t, "manual", repro.Trigger.Type())
+	assert.Equal(t, 1, len(repro.Resumes))
+
+	outputs1, err := engine.Replay(sessionAssets, repro, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(outputs1))
+	assert.Equal(t, 2, len(outputs1[0].Events))
+	assert.Equal(t, 3, len(outputs1[1].Events))
+
+	// replaying again gives exactly the same output
+	outputs2, err := engine.Replay(sessionAssets, repro, nil)
+	require.NoError(t, err)
+
+	differences, err := engine.CompareReplay(outputs1, outputs2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(differences))
+
+	// but a different seed doesn't
+	outputs3, err := engine.Replay(sessionAssets, repro, &engine.ReplayConfig{StartTime: engine.DefaultReplayConfig.StartTime, Seed: 789})
+	require.NoError(t, err)
+
+	differences, err = engine.CompareReplay(outputs1, outputs3)
+	require.NoError(t, err)
+	assert.True(t, len(differences) > 0)
+	assert.Equal(t, 0, differences[0].Sprint)
+	assert.Equal(t, "events[0].msg.uuid", differences[0].Path)
+
+	// check differences in the number of sprints
+	differences, err = engine.CompareReplay(outputs1, outputs1[:1])
+	require.NoError(t, err)
+	require.Equal(t, 1, len(differences))
+	assert.Equal(t, 1, differences[0].Sprint)
+	assert.Equal(t, "", differences[0].Path)
+	assert.Nil(t, differences[0].Actual)
+	assert.Equal(t, engine.ReplayMissingActual, differences[0].Missing)
+
+	// check a specific difference in an event
+	outputs2[1].Events[0] = json.RawMessage(`{"type": "msg_received", "created_on": "2018-07-06T12:30:13.123456789Z", "msg": {"text": "I like blue"}}`)
+
+	differences, err = engine.CompareReplay(outputs1, outputs2)
+	require.NoError(t, err)
+	assert.Equal(t, 4, len(differences))
+	assert.Equal(t, "sprint[1] events[0].msg.text: expected \"I like red\", got \"I like blue\"", differences[0].String())
+	assert.Equal(t, "sprint[1] events[0].msg.urn: expected \"tel:+12065551212\", got nothing", differences[1].String())
+	assert.Equal(t, engine.ReplayMissingActual, differences[1].Missing)
+
+	// a null value isn't the same as a missing one
+	outputs2[1].Events[0] = json.RawMessage(`{"type": "msg_received", "created_on": "2018-07-06T12:30:13.123456789Z", "msg": {"text": "I like blue", "urn": null}}`)
+
+	differences, err = engine.CompareReplay(outputs1, outputs2)
+	require.NoError(t, err)
+	assert.Equal(t, "sprint[1] events[0].msg.urn: expected \"tel:+12065551212\", got null", differences[1].String())
+	assert.Equal(t, "", differences[1].Missing)
+
+	// numbers are compared exactly, even if they're too big to be represented exactly as floats
+	differences, err = engine.CompareReplay(
+		[]*engine.SprintOutput{{Session: json.RawMessage(`{"seed": 3550330175404308500}`), Events: []json.RawMessage{}}},
+		[]*engine.SprintOutput{{Session: json.RawMessage(`{"seed": 3550330175404308501}`), Events: []json.RawMessage{}}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(differences))
+	assert.Equal(t, "sprint[0] session.seed: expected 3550330175404308500, got 3550330175404308501", differences[0].String())
+
+	// error if repro has more resumes than the session can use
+	repro.Resumes = append(repro.Resumes, repro.Resumes[0], repro.Resumes[0])
+	_, err = engine.Replay(sessionAssets, repro, nil)
+	assert.EqualError(t, err, "session isn't waiting but repro has 2 unused resumes")
+
+	// error if repro references assets which no longer exist
+	oldSource, err := static.NewSource([]byte(strings.Replace(string(assetsJSON), "{", `{"groups": [{"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Testers"}],`, 1)))
+	require.NoError(t, err)
+	oldAssets, err := engine.NewSessionAssets(oldSource)
+	require.NoError(t, err)
+
+	withGroupJSON := strings.Replace(reproJSON, `"urns": ["tel:+12065551212"],`, `"urns": ["tel:+12065551212"], "groups": [{"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Testers"}],`, 1)
+	repro, err = engine.ReadRepro(oldAssets, json.RawMessage(withGroupJSON), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	_, err = engine.Replay(sessionAssets, repro, nil)
+	assert.EqualError(t, err, "unable to read repro: missing assets: group[uuid=b7cf0d83-f1c9-411c-96fd-c511a4cfa86d,name=Testers]")
+}
+
+func TestReplayRestoresUUIDGenerator(t *testing.T) {
+	assetsJSON, err := ioutil.ReadFile("testdata/timeout_test.json")
+	require.NoError(t, err)
+
+	source, err := static.NewSource(assetsJSON)
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	repro, err := engine.ReadRepro(sessionAssets, json.RawMessage(reproJSON), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	defer utils.SetUUIDGenerator(utils.DefaultUUIDGenerator)
+	utils.SetUUIDGenerator(utils.NewSeededUUID4Generator(1234))
+	expected := utils.NewSeededUUID4Generator(1234)
+
+	assert.Equal(t, expected.Next(), utils.NewUUID())
+
+	_, err = engine.Replay(sessionAssets, repro, nil)
+	require.NoError(t, err)
+
+	// our generator carries on where it left off
+	assert.Equal(t, expected.Next(), utils.NewUUID())
+}
@@ -0,0 +1,194 @@
+package engine_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	source, err := static.LoadSource("testdata/retention_test.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	// two engines with the same seed, one of which prunes history
+	fullEng := engine.NewBuilder().WithRandSeed(123).Build()
+	prunedEng := engine.NewBuilder().WithRandSeed(123).WithRetentionPolicy(&flows.RetentionPolicy{
+		MaxStepsPerRun:         2,
+		DropSprintEvents:       true,
+		SummarizeCompletedRuns: true,
+	}).Build()
+
+	contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+	trigger := triggers.NewManualTrigger(nil, flow.Reference(), contact, nil)
+
+	fullSession := fullEng.NewSession(sessionAssets)
+	_, err = fullSession.Start(trigger)
+	require.NoError(t, err)
+
+	prunedSession := prunedEng.NewSession(sessionAssets)
+	_, err = prunedSession.Start(trigger)
+	require.NoError(t, err)
+
+	var lastSprint flows.Sprint
+	fullExits := make([]flows.ExitUUID, 0)
+	prunedExits := make([]flows.ExitUUID, 0)
+
+	for i := 0; i < 6; i++ {
+		msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, fmt.Sprintf("answer %d", i), nil)
+
+		_, err = fullSession.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err)
+
+		// the pruned session is saved and reloaded between sprints using the compact encoding
+		compact, err := engine.MarshalCompact(prunedSession)
+		require.NoError(t, err)
+		prunedSession, err = prunedEng.ReadSession(sessionAssets, compact, assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		lastSprint, err = prunedSession.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err)
+
+		fullPath := fullSession.Runs()[0].Path()
+		prunedPath := prunedSession.Runs()[0].Path()
+		fullExits = append(fullExits, fullPath[len(fullPath)-3].ExitUUID())
+		prunedExits = append(prunedExits, prunedPath[len(prunedPath)-3].ExitUUID())
+	}
+
+	// random_once still behaves the same, taking each of its non-default exits once
+	assert.Equal(t, fullExits, prunedExits)
+	assert.ElementsMatch(t, []flows.ExitUUID{"c70fe86c-9aac-4cc2-a5cb-d35cbe3fed6e", "cee79a7f-51fd-414a-a9fb-f9c1f1baf186"}, prunedExits[:2])
+	for _, exit := range prunedExits[2:] {
+		assert.Equal(t, flows.ExitUUID("78ae8f05-f92e-43b2-a886-406eaea1b8e0"), exit)
+	}
+
+	// but the pruned session only has the parent run and its new child, with its previous child replaced by a summary
+	assert.Equal(t, 8, len(fullSession.Runs()))
+	assert.Equal(t, 2, len(prunedSession.Runs()))
+	assert.Equal(t, prunedSession.Runs()[1], prunedSession.GetCurrentChild(prunedSession.Runs()[0]))
+
+	prunedJSON, err := json.Marshal(prunedSession)
+	require.NoError(t, err)
+	saved := &struct {
+		ChildSummaries map[flows.RunUUID]struct {
+			Results flows.Results `json:"results"`
+		} `json:"child_summaries"`
+	}{}
+	require.NoError(t, json.Unmarshal(prunedJSON, saved))
+	assert.Equal(t, "answer 4", saved.ChildSummaries[prunedSession.Runs()[0].UUID()].Results.Get("answer").Value)
+
+	// a shorter path, and only the events from the last sprint
+	assert.Equal(t, 14, len(fullSession.Runs()[0].Path()))
+	assert.Equal(t, 6, len(prunedSession.Runs()[0].Path()))
+	assert.Equal(t, 28, len(fullSession.Runs()[0].Events()))
+	assert.Equal(t, 4, len(prunedSession.Runs()[0].Events()))
+
+	// and the child run can still be referenced
+	lastMsg := lastSprint.Events()[len(lastSprint.Events())-2].(*events.MsgCreatedEvent)
+	assert.Equal(t, "You said answer 5, what next?", lastMsg.Msg.Text())
+
+	// compact encoding is smaller than the regular JSON
+	fullJSON, err := json.Marshal(fullSession)
+	require.NoError(t, err)
+	compact, err := engine.MarshalCompact(fullSession)
+	require.NoError(t, err)
+	assert.True(t, len(compact) < len(fullJSON))
+}
+
+func TestRetentionPolicyChildSummaries(t *testing.T) {
+	source, err := static.LoadSource("testdata/retention_test.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("e4b3a5b1-2e3c-4f8a-9d37-0b4c38d5a2f1"))
+	require.NoError(t, err)
+
+	eng := engine.NewBuilder().WithRetentionPolicy(&flows.RetentionPolicy{SummarizeCompletedRuns: true}).Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+
+	session := eng.NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	var sprint flows.Sprint
+	for i, text := range []string{"yes", "ok", "bye"} {
+		// save and reload between sprints so that the child summary has to survive being marshaled
+		compact, err := engine.MarshalCompact(session)
+		require.NoError(t, err)
+		session, err = eng.ReadSession(sessionAssets, compact, assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, text, nil)
+		sprint, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err)
+
+		// the child run is kept until the end of the sprint after the one it completed in
+		if i == 0 {
+			assert.Equal(t, 2, len(session.Runs()))
+		} else {
+			assert.Equal(t, 1, len(session.Runs()))
+		}
+	}
+
+	// the last message was created after the child run was removed, so @child comes from its summary
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	msgEvent := sprint.Events()[len(sprint.Events())-1].(*events.MsgCreatedEvent)
+	assert.Equal(t, "And you still said yes", msgEvent.Msg.Text())
+}
+
+func TestRetentionPolicyKeepsHistoryOfErroredSprints(t *testing.T) {
+	source, err := static.LoadSource("testdata/retention_test.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	eng := engine.NewBuilder().WithRetentionPolicy(&flows.RetentionPolicy{
+		MaxStepsPerRun:         1,
+		DropSprintEvents:       true,
+		SummarizeCompletedRuns: true,
+	}).Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+
+	session := eng.NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	numSteps := len(session.Runs()[0].Path())
+	numEvents := len(session.Runs()[0].Events())
+
+	// resume with a context that has already been cancelled so that the sprint errors
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "answer", nil)
+	_, err = session.ResumeContext(ctx, resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	// nothing from before the sprint has been pruned
+	assert.Equal(t, flows.SessionStatusErrored, session.Status())
+	assert.Equal(t, 2, len(session.Runs()))
+	assert.Equal(t, numSteps, len(session.Runs()[0].Path()))
+	assert.True(t, len(session.Runs()[0].Events()) > numEvents)
+}
@@ -1,17 +1,23 @@
 package engine
 
 import (
+	"context"
 	"encoding/json"
+	"math/rand"
+	"time"
 
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/inputs"
+	"github.com/nyaruka/goflow/flows/resumes"
 	"github.com/nyaruka/goflow/flows/runs"
 	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/flows/waits"
 	"github.com/nyaruka/goflow/utils"
 
+	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
 )
 
@@ -20,6 +26,8 @@ type pushedFlow struct {
 	flow      flows.Flow
 	parentRun flows.FlowRun
 	terminal  bool
+	params    types.XValue
+	returns   map[string]string
 }
 
 type session struct {
@@ -34,11 +42,19 @@ type session struct {
 	status  flows.SessionStatus
 	wait    flows.Wait
 	input   flows.Input
+	seed    int64
+
+	// summaries of completed child runs which have been removed by the retention policy, by the UUID of their parent
+	childSummaries map[flows.RunUUID]flows.RunSummary
 
 	// state which is temporary to each call
+	ctx        context.Context
+	rand       *rand.Rand
+	budget     *flows.SprintBudget
 	runsByUUID map[flows.RunUUID]flows.FlowRun
 	pushedFlow *pushedFlow
 	parentRun  flows.RunSummary
+	resume     flows.Resume
 
 	engine flows.Engine
 }
@@ -58,8 +74,11 @@ func (s *session) SetContact(contact *flows.Contact) { s.contact = contact }
 func (s *session) Input() flows.Input         { return s.input }
 func (s *session) SetInput(input flows.Input) { s.input = input }
 
-func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool) {
-	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal}
+// CurrentResume returns the resume which most recently resumed this session, if any
+func (s *session) CurrentResume() flows.Resume { return s.resume }
+
+func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool, params types.XValue, returns map[string]string) {
+	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal, params: params, returns: returns}
 }
 
 func (s *session) Runs() []flows.FlowRun { return s.runs }
@@ -76,13 +95,18 @@ func (s *session) addRun(run flows.FlowRun) {
 	s.runsByUUID[run.UUID()] = run
 }
 
-func (s *session) GetCurrentChild(run flows.FlowRun) flows.FlowRun {
+func (s *session) GetCurrentChild(run flows.FlowRun) flows.RunSummary {
 	// the current child of a run, is the last added run which has that run as its parent
 	for r := len(s.runs) - 1; r >= 0; r-- {
 		if s.runs[r].ParentInSession() == run {
 			return s.runs[r]
 		}
 	}
+
+	// or if that run has been removed from the session, the summary of it
+	if summary, exists := s.childSummaries[run.UUID()]; exists {
+		return summary
+	}
 	return nil
 }
 
@@ -106,14 +130,48 @@ func (s *session) waitingRun() flows.FlowRun {
 
 func (s *session) Engine() flows.Engine { return s.engine }
 
+// SprintContext returns the context of the current sprint, which is used to cancel long running operations
+func (s *session) SprintContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// Now returns the current time according to the engine's time source
+func (s *session) Now() time.Time { return s.engine.TimeSource().Now() }
+
+// Rand returns the random number generator of this session, which is seeded from the session's seed
+func (s *session) Rand() *rand.Rand {
+	if s.rand == nil {
+		s.rand = utils.NewSeededRand(s.seed)
+	}
+	return s.rand
+}
+
+// SprintBudget returns the budget which tracks the resources used by the current sprint
+func (s *session) SprintBudget() *flows.SprintBudget {
+	if s.budget == nil {
+		s.budget = flows.NewSprintBudget(s.engine)
+	}
+	return s.budget
+}
+
 //------------------------------------------------------------------------------------------
 // Flow execution
 //------------------------------------------------------------------------------------------
 
 // Start initializes this session with the given trigger and runs the flow to the first wait
 func (s *session) Start(trigger flows.Trigger) (flows.Sprint, error) {
-	sprint := NewEmptySprint()
+	return s.StartContext(context.Background(), trigger)
+}
+
+// StartContext is like Start but stops execution if the given context is cancelled or its deadline is exceeded
+func (s *session) StartContext(ctx context.Context, trigger flows.Trigger) (flows.Sprint, error) {
+	sprint := newSprintForSession(s)
 	s.trigger = trigger
+	s.beginSprint(ctx)
+	defer s.endSprint()
 
 	if err := s.prepareForSprint(); err != nil {
 		return sprint, err
@@ -133,7 +191,14 @@ func (s *session) Start(trigger flows.Trigger) (flows.Sprint, error) {
 
 // Resume tries to resume a waiting session
 func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
-	sprint := NewEmptySprint()
+	return s.ResumeContext(context.Background(), resume)
+}
+
+// ResumeContext is like Resume but stops execution if the given context is cancelled or its deadline is exceeded
+func (s *session) ResumeContext(ctx context.Context, resume flows.Resume) (flows.Sprint, error) {
+	sprint := newSprintForSession(s)
+	s.beginSprint(ctx)
+	defer s.endSprint()
 
 	if err := s.prepareForSprint(); err != nil {
 		return sprint, err
@@ -148,6 +213,9 @@ func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
 		return sprint, errors.Errorf("session doesn't contain any runs which are waiting")
 	}
 
+	// remember how much history came from previous sprints so it can be pruned once this sprint has succeeded
+	history := s.recordHistory()
+
 	// check flow is valid and has everything it needs to run
 	if err := waitingRun.Flow().ValidateRecursively(s.Assets()); err != nil {
 		return sprint, errors.Wrapf(err, "validation failed for flow[uuid=%s]", waitingRun.Flow().UUID())
@@ -162,9 +230,125 @@ func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
 		sprint.LogEvent(events.NewErrorEvent(err))
 	}
 
+	// an errored session keeps all its history so that what went wrong can be investigated
+	if s.status != flows.SessionStatusErrored {
+		s.pruneHistory(history)
+	}
+
 	return sprint, nil
 }
 
+// sets up the temporary state used during a sprint
+func (s *session) beginSprint(ctx context.Context) {
+	s.ctx = ctx
+	s.rand = utils.NewSeededRand(s.seed)
+	s.budget = flows.NewSprintBudget(s.engine)
+}
+
+// clears the temporary state used during a sprint, and moves our seed on so that the next sprint gets a different
+// but still deterministic sequence of random numbers
+func (s *session) endSprint() {
+	if debugger := s.engine.Debugger(); debugger != nil {
+		debugger.EndSprint(s)
+	}
+
+	s.ctx = nil
+	s.seed = utils.RandSeedFrom(s.rand)
+	s.rand = nil
+	s.budget = nil
+}
+
+// the history a session had before a sprint, which can be pruned once that sprint has succeeded
+type sprintHistory struct {
+	exited    map[flows.RunUUID]bool
+	numSteps  map[flows.RunUUID]int
+	numEvents map[flows.RunUUID]int
+}
+
+// records the history of this session before a sprint, if the engine's retention policy will need it
+func (s *session) recordHistory() *sprintHistory {
+	if s.engine.RetentionPolicy() == nil {
+		return nil
+	}
+
+	h := &sprintHistory{
+		exited:    make(map[flows.RunUUID]bool, len(s.runs)),
+		numSteps:  make(map[flows.RunUUID]int, len(s.runs)),
+		numEvents: make(map[flows.RunUUID]int, len(s.runs)),
+	}
+	for _, run := range s.runs {
+		h.exited[run.UUID()] = run.Status() != flows.RunStatusActive && run.Status() != flows.RunStatusWaiting
+		h.numSteps[run.UUID()] = len(run.Path())
+		h.numEvents[run.UUID()] = len(run.Events())
+	}
+	return h
+}
+
+// prunes the given history from previous sprints according to the engine's retention policy
+func (s *session) pruneHistory(h *sprintHistory) {
+	policy := s.engine.RetentionPolicy()
+	if policy == nil {
+		return
+	}
+
+	if policy.SummarizeCompletedRuns {
+		// runs which exited in previous sprints are only kept if they're the ancestors of other kept runs
+		kept := make(map[flows.RunUUID]bool)
+		for _, run := range s.runs {
+			if !h.exited[run.UUID()] {
+				kept[run.UUID()] = true
+				for _, ancestor := range run.Ancestors() {
+					kept[ancestor.UUID()] = true
+				}
+			}
+		}
+
+		summaries := make(map[flows.RunUUID]flows.RunSummary)
+		for parentUUID, summary := range s.childSummaries {
+			if kept[parentUUID] {
+				summaries[parentUUID] = summary
+			}
+		}
+
+		keptRuns := make([]flows.FlowRun, 0, len(kept))
+		for _, run := range s.runs {
+			if kept[run.UUID()] {
+				keptRuns = append(keptRuns, run)
+				continue
+			}
+
+			delete(s.runsByUUID, run.UUID())
+
+			// removed runs are replaced by summaries if they can still be referenced as the child of a kept run, and
+			// as runs are in the order they were created, later children replace earlier ones
+			if parent := run.ParentInSession(); parent != nil && kept[parent.UUID()] {
+				summaries[parent.UUID()] = run.Snapshot()
+			}
+		}
+		s.runs = keptRuns
+		s.childSummaries = summaries
+	}
+
+	for _, run := range s.runs {
+		numSteps, existed := h.numSteps[run.UUID()]
+		if !existed {
+			continue // runs created in this sprint don't have any previous history
+		}
+
+		if policy.SummarizeCompletedRuns && h.exited[run.UUID()] {
+			run.PrunePath(0)
+			run.PruneEvents(0)
+			continue
+		}
+		if policy.MaxStepsPerRun > 0 {
+			run.PrunePath(policy.MaxStepsPerRun + len(run.Path()) - numSteps)
+		}
+		if policy.DropSprintEvents {
+			run.PruneEvents(len(run.Events()) - h.numEvents[run.UUID()])
+		}
+	}
+}
+
 // prepares the session for starting/resuming
 func (s *session) prepareForSprint() error {
 	if s.parentRun == nil {
@@ -190,12 +374,15 @@ func (s *session) tryToResume(sprint flows.Sprint, waitingRun flows.FlowRun, res
 	}
 
 	// try to end our wait which will return and log an error if it can't be ended with this resume
-	if err := s.wait.End(resume, node); err != nil {
+	wait := s.wait
+	if err := wait.End(waitingRun, resume, node); err != nil {
 		sprint.LogEvent(events.NewErrorEvent(err))
 		return nil
 	}
+	previousResume := s.resume
 	s.wait = nil
 	s.status = flows.SessionStatusActive
+	s.resume = resume
 
 	logEvent := func(e flows.Event) {
 		waitingRun.LogEvent(step, e)
@@ -207,6 +394,27 @@ func (s *session) tryToResume(sprint flows.Sprint, waitingRun flows.FlowRun, res
 		return err
 	}
 
+	// some waits can reject the input they were resumed with, and either keep waiting or take a dedicated exit
+	if validatingWait, isValidating := wait.(flows.WaitWithValidation); isValidating {
+		keepWaiting, route := validatingWait.ValidateResume(waitingRun, resume, node, logEvent)
+		if keepWaiting {
+			waitingRun.SetStatus(flows.RunStatusWaiting)
+			s.wait = wait
+			s.status = flows.SessionStatusWaiting
+			s.resume = previousResume
+			return nil
+		}
+
+		if route.Exit() != "" {
+			operand := route.Match()
+			_, destination, err := s.leaveNode(sprint, waitingRun, node, step, &operand, route, logEvent)
+			if err != nil {
+				return err
+			}
+			return s.continueUntilWait(sprint, waitingRun, destination, step, nil)
+		}
+	}
+
 	destination, err := s.findResumeDestination(sprint, waitingRun)
 	if err != nil {
 		return err
@@ -233,7 +441,7 @@ func (s *session) findResumeDestination(sprint flows.Sprint, run flows.FlowRun)
 	}
 
 	// see if this node can now pick a destination
-	step, destination, err := s.pickNodeExit(run, node, step, logEvent)
+	step, destination, err := s.pickNodeExit(sprint, run, node, step, logEvent)
 	if err != nil {
 		return noDestination, err
 	}
@@ -257,7 +465,7 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 
 			// create a new run for it
 			flow := s.pushedFlow.flow
-			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun)
+			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun, s.pushedFlow.params, s.pushedFlow.returns)
 			s.addRun(currentRun)
 
 			// our destination is the first node in that flow... if such a node exists
@@ -286,6 +494,10 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 
 				// as long as we didn't error, we can try to resume it
 				if childRun.Status() != flows.RunStatusErrored {
+					if childRun.Status() == flows.RunStatusCompleted {
+						s.returnOutputs(sprint, childRun, currentRun)
+					}
+
 					if destination, err = s.findResumeDestination(sprint, currentRun); err != nil {
 						fatalError(sprint, currentRun, step, errors.Errorf("can't resume run as node no longer exists"))
 					}
@@ -312,9 +524,13 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 		if destination != noDestination {
 			numNewSteps++
 
-			if numNewSteps > s.Engine().MaxStepsPerSprint() {
-				// we've hit the step limit - usually a sign of a loop
-				fatalError(sprint, currentRun, step, errors.Errorf("step limit exceeded, stopping execution before entering '%s'", destination))
+			if err := s.SprintContext().Err(); err != nil {
+				// our caller has cancelled this sprint or its deadline has passed
+				fatalError(sprint, currentRun, step, errors.Wrapf(err, "sprint interrupted, stopping execution before entering '%s'", destination))
+				destination = noDestination
+			} else if exceeded := s.SprintBudget().Check(numNewSteps, len(sprint.Events()), destination); exceeded != nil {
+				// we've used up one of our budgets - hitting the step limit is usually a sign of a loop
+				fatalError(sprint, currentRun, step, exceeded)
 				destination = noDestination
 			} else {
 				node := currentRun.Flow().GetNode(destination)
@@ -339,6 +555,51 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 	}
 }
 
+// saves the outputs of a completed child run as results on its parent run. Like all results, these are stored as text
+// so outputs of other types are checked against their declared type and then converted to text.
+func (s *session) returnOutputs(sprint flows.Sprint, childRun flows.FlowRun, parentRun flows.FlowRun) {
+	if len(childRun.Returns()) == 0 {
+		return
+	}
+
+	step, _, err := parentRun.PathLocation()
+	if err != nil {
+		sprint.LogEvent(events.NewErrorEvent(errors.Wrapf(err, "unable to return outputs of %s", childRun.Flow().Reference())))
+		return
+	}
+	logEvent := func(e flows.Event) {
+		parentRun.LogEvent(step, e)
+		sprint.LogEvent(e)
+	}
+
+	for _, key := range utils.SortedKeys(childRun.Returns()) {
+		output := childRun.Flow().Outputs().Get(key)
+		if output == nil {
+			logEvent(events.NewErrorEventf("output '%s' isn't declared by %s", key, childRun.Flow().Reference()))
+			continue
+		}
+
+		value, err := output.Evaluate(childRun)
+		if err != nil {
+			logEvent(events.NewErrorEvent(err))
+			continue
+		}
+
+		var text types.XText
+		if value != nil {
+			var xerr types.XError
+			if text, xerr = types.ToXText(s.Environment(), value); xerr != nil {
+				logEvent(events.NewErrorEvent(xerr))
+				continue
+			}
+		}
+
+		result := flows.NewResult(childRun.Returns()[key], text.Native(), "", "", step.NodeUUID(), nil, nil, s.Now())
+		parentRun.SaveResult(result)
+		logEvent(events.NewRunResultChangedEvent(result))
+	}
+}
+
 // visits the given node, creating a step in our current run path
 func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.Node, trigger flows.Trigger) (flows.Step, flows.NodeUUID, error) {
 	step := run.CreateStep(node)
@@ -347,6 +608,8 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 		sprint.LogEvent(e)
 	}
 
+	s.debug(&flows.DebugStop{Type: flows.DebugStopNode, Sprint: sprint, Run: run, Node: node, Step: step})
+
 	// this might be the first run of the session in which case a trigger might need to initialize the run
 	if trigger != nil {
 		if err := trigger.InitializeRun(run, logEvent); err != nil {
@@ -357,6 +620,8 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 	// execute our node's actions
 	if node.Actions() != nil {
 		for _, action := range node.Actions() {
+			s.debug(&flows.DebugStop{Type: flows.DebugStopAction, Sprint: sprint, Run: run, Node: node, Step: step, Action: action})
+
 			if err := action.Execute(run, step, sprint.LogModifier, logEvent); err != nil {
 				return step, noDestination, errors.Wrapf(err, "error executing action[type=%s,uuid=%s]", action.Type(), action.UUID())
 			}
@@ -375,8 +640,13 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 	}
 
 	// our node might have wait
-	wait := node.Wait()
-	if wait != nil {
+	if node.Wait() != nil {
+		// waits keep the state of the session waiting on them, so we wait on a copy of the wait in the flow definition
+		// which can be shared by other sessions
+		wait, err := copyWait(node.Wait())
+		if err != nil {
+			return step, noDestination, err
+		}
 
 		// waits have the option to skip themselves
 		if wait.Begin(run, logEvent) {
@@ -390,11 +660,20 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 	}
 
 	// use our node's router to determine where to go next
-	return s.pickNodeExit(run, node, step, logEvent)
+	return s.pickNodeExit(sprint, run, node, step, logEvent)
+}
+
+// copies the given wait so that beginning it doesn't change the original
+func copyWait(wait flows.Wait) (flows.Wait, error) {
+	marshaled, err := json.Marshal(wait)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to copy %s wait", wait.Type())
+	}
+	return waits.ReadWait(marshaled)
 }
 
 // picks the exit to use on the given node
-func (s *session) pickNodeExit(run flows.FlowRun, node flows.Node, step flows.Step, logEvent flows.EventCallback) (flows.Step, flows.NodeUUID, error) {
+func (s *session) pickNodeExit(sprint flows.Sprint, run flows.FlowRun, node flows.Node, step flows.Step, logEvent flows.EventCallback) (flows.Step, flows.NodeUUID, error) {
 	var err error
 
 	var operand *string
@@ -402,17 +681,27 @@ func (s *session) pickNodeExit(run flows.FlowRun, node flows.Node, step flows.St
 	router := node.Router()
 
 	// we have a router, have it determine our exit
-	var exitUUID flows.ExitUUID
 	if router != nil {
+		s.debug(&flows.DebugStop{Type: flows.DebugStopRouter, Sprint: sprint, Run: run, Node: node, Step: step})
+
 		if operand, route, err = router.PickRoute(run, node.Exits(), step); err != nil {
 			return nil, noDestination, errors.Wrapf(err, "error routing from node[uuid=%s]", node.UUID())
 		}
-		exitUUID = route.Exit()
 	} else if len(node.Exits()) > 0 {
 		// no router, pick our first exit if we have one
-		exitUUID = node.Exits()[0].UUID()
+		route = flows.NewRoute(node.Exits()[0].UUID(), "", nil)
 	}
 
+	return s.leaveNode(sprint, run, node, step, operand, route, logEvent)
+}
+
+// leaves the given node by the given route, saving a result if the node's router has a result name
+func (s *session) leaveNode(sprint flows.Sprint, run flows.FlowRun, node flows.Node, step flows.Step, operand *string, route flows.Route, logEvent flows.EventCallback) (flows.Step, flows.NodeUUID, error) {
+	router := node.Router()
+	exitUUID := route.Exit()
+
+	s.debug(&flows.DebugStop{Type: flows.DebugStopExit, Sprint: sprint, Run: run, Node: node, Step: step, Operand: operand, Route: route})
+
 	step.Leave(exitUUID)
 
 	// look up our actual exit and localized name
@@ -447,7 +736,7 @@ func (s *session) pickNodeExit(run flows.FlowRun, node flows.Node, step flows.St
 		if route.Extra() != nil {
 			extraJSON, _ = json.Marshal(route.Extra())
 		}
-		result := flows.NewResult(router.ResultName(), route.Match(), exit.Name(), localizedExitName, step.NodeUUID(), operand, extraJSON, utils.Now())
+		result := flows.NewResult(router.ResultName(), route.Match(), exit.Name(), localizedExitName, step.NodeUUID(), operand, extraJSON, s.Now())
 		run.SaveResult(result)
 		logEvent(events.NewRunResultChangedEvent(result))
 	}
@@ -457,6 +746,13 @@ func (s *session) pickNodeExit(run flows.FlowRun, node flows.Node, step flows.St
 
 const noDestination = flows.NodeUUID("")
 
+// notifies the engine's debugger, if there is one, that execution has reached the given point
+func (s *session) debug(stop *flows.DebugStop) {
+	if debugger := s.engine.Debugger(); debugger != nil {
+		debugger.Stop(stop)
+	}
+}
+
 // utility to log a fatal error event
 func fatalError(sprint flows.Sprint, run flows.FlowRun, step flows.Step, err error) {
 	event := events.NewFatalErrorEvent(err)
@@ -472,7 +768,8 @@ func fatalError(sprint flows.Sprint, run flows.FlowRun, step flows.Step, err err
 //------------------------------------------------------------------------------------------
 
 type sessionEnvelope struct {
-	Type        flows.FlowType      `json:"type"` // TODO validate:"required"`
+	SpecVersion *semver.Version     `json:"spec_version" validate:"required"`
+	Type        flows.FlowType      `json:"type" validate:"required"`
 	Environment json.RawMessage     `json:"environment"`
 	Trigger     json.RawMessage     `json:"trigger" validate:"required"`
 	Contact     *json.RawMessage    `json:"contact,omitempty"`
@@ -480,6 +777,10 @@ type sessionEnvelope struct {
 	Status      flows.SessionStatus `json:"status" validate:"required"`
 	Wait        json.RawMessage     `json:"wait,omitempty"`
 	Input       json.RawMessage     `json:"input,omitempty" validate:"omitempty"`
+	Resume      json.RawMessage     `json:"resume,omitempty" validate:"omitempty"`
+	Seed        int64               `json:"seed"`
+
+	ChildSummaries map[flows.RunUUID]json.RawMessage `json:"child_summaries,omitempty"`
 }
 
 // ReadSession decodes a session from the passed in JSON
@@ -487,6 +788,15 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 	e := &sessionEnvelope{}
 	var err error
 
+	if data, err = decodeCompact(data); err != nil {
+		return nil, err
+	}
+
+	// upgrade sessions written by older versions of this library
+	if data, err = migrateSession(data); err != nil {
+		return nil, err
+	}
+
 	if err = utils.UnmarshalAndValidate(data, e); err != nil {
 		return nil, errors.Wrap(err, "unable to read session")
 	}
@@ -496,6 +806,7 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 		assets:     sessionAssets,
 		type_:      e.Type,
 		status:     e.Status,
+		seed:       e.Seed,
 		runsByUUID: make(map[flows.RunUUID]flows.FlowRun),
 	}
 
@@ -528,7 +839,17 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 		s.addRun(run)
 	}
 
-	// and our wait and input
+	// and the summaries of child runs which have been removed
+	if e.ChildSummaries != nil {
+		s.childSummaries = make(map[flows.RunUUID]flows.RunSummary, len(e.ChildSummaries))
+		for parentUUID, data := range e.ChildSummaries {
+			if s.childSummaries[parentUUID], err = runs.ReadRunSummary(s.Assets(), data, missing); err != nil {
+				return nil, errors.Wrapf(err, "unable to read summary of child of run %s", parentUUID)
+			}
+		}
+	}
+
+	// and our wait, input and the resume which last resumed us
 	if e.Wait != nil {
 		s.wait, err = waits.ReadWait(e.Wait)
 		if err != nil {
@@ -540,6 +861,11 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 			return nil, errors.Wrap(err, "unable to read input")
 		}
 	}
+	if e.Resume != nil {
+		if s.resume, err = resumes.ReadResume(s.Assets(), e.Resume, missing); err != nil {
+			return nil, errors.Wrap(err, "unable to read resume")
+		}
+	}
 
 	// TODO more and don't limit to sessions being read
 	// perform some structural validation
@@ -553,8 +879,10 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 // MarshalJSON marshals this session into JSON
 func (s *session) MarshalJSON() ([]byte, error) {
 	e := &sessionEnvelope{
-		Type:   s.type_,
-		Status: s.status,
+		SpecVersion: CurrentSessionSpecVersion,
+		Type:        s.type_,
+		Status:      s.status,
+		Seed:        s.seed,
 	}
 	var err error
 
@@ -585,6 +913,11 @@ func (s *session) MarshalJSON() ([]byte, error) {
 			return nil, err
 		}
 	}
+	if s.resume != nil {
+		if e.Resume, err = json.Marshal(s.resume); err != nil {
+			return nil, err
+		}
+	}
 
 	e.Runs = make([]json.RawMessage, len(s.runs))
 	for i := range s.runs {
@@ -594,5 +927,14 @@ func (s *session) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if len(s.childSummaries) > 0 {
+		e.ChildSummaries = make(map[flows.RunUUID]json.RawMessage, len(s.childSummaries))
+		for parentUUID, summary := range s.childSummaries {
+			if e.ChildSummaries[parentUUID], err = json.Marshal(summary); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return json.Marshal(e)
 }
@@ -1,9 +1,11 @@
 package engine_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"testing"
 	"time"
 
@@ -136,11 +138,11 @@ func TestContextToJSON(t *testing.T) {
 		{"contact.urns[0]", `{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"}`},
 		{"contact.fields", `{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null}`},
 		{"contact.fields.age", `23`},
-		{"contact", `{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"}`},
+		{"contact", `{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","tickets":[],"timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"}`},
 		{"input", `{"attachments":[{"content_type":"image/jpeg","url":"http://s3.amazon.com/bucket/test.jpg"},{"content_type":"audio/mp3","url":"http://s3.amazon.com/bucket/test.mp3"}],"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2017-12-31T11:35:10.035757-02:00","text":"Hi there","type":"msg","urn":{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},"uuid":"9bf91c2b-ce58-4cef-aacc-281e03f69ab5"}`},
-		{"run", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"},"created_on":"2018-04-11T13:24:30.123456Z","exited_on":"2018-04-11T13:24:30.123456Z","flow":{"name":"Registration","revision":123,"uuid":"50c3706e-fedb-42c0-8eab-dda3335714b7"},"results":{"2factor":{"category":"","category_localized":"","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"2Factor","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"34634624463525"},"favorite_color":{"category":"Red","category_localized":"Red","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Favorite Color","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"red"},"phone_number":{"category":"","category_localized":"","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Phone Number","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"+12344563452"},"webhook":{"category":"Success","category_localized":"Success","created_on":"2018-04-11T13:24:30.123456Z","input":"GET http://127.0.0.1:49992/?content=%7B%22results%22%3A%5B%7B%22state%22%3A%22WA%22%7D%2C%7B%22state%22%3A%22IN%22%7D%5D%7D","name":"webhook","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"200"}},"status":"completed","uuid":"d2f852ec-7b4e-457f-ae7f-f8b243c49ff5"}`},
-		{"child", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"},"flow":{"name":"Collect Age","revision":0,"uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},"results":{"age":{"category":"Youth","category_localized":"Youth","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Age","node_uuid":"d9dba561-b5ee-4f62-ba44-60c4dc242b84","value":"23"}},"status":"completed","uuid":"8720f157-ca1c-432f-9c0b-2014ddc77094"}`},
-		{"parent", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-01-01T12:00:00.000000Z","fields":{"activation_token":null,"age":33,"gender":"Female","join_date":null,"not_set":null},"groups":[],"language":"spa","name":"Jasmine","timezone":null,"urns":[{"display":"097 911 1222","path":"+593979111222","scheme":"tel"}],"uuid":"c59b0033-e748-4240-9d4c-e85eb6800151"},"flow":{"name":"Parent","revision":0,"uuid":"fece6eac-9127-4343-9269-56e88f391562"},"results":{"role":{"category":"Reporter","category_localized":"Reporter","created_on":"2000-01-01T00:00:00.000000Z","input":"a reporter","name":"Role","node_uuid":"385cb848-5043-448e-9123-05cbcf26ad74","value":"reporter"}},"status":"active","uuid":"4213ac47-93fd-48c4-af12-7da8218ef09d"}`},
+		{"run", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","tickets":[],"timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"},"created_on":"2018-04-11T13:24:30.123456Z","exited_on":"2018-04-11T13:24:30.123456Z","flow":{"name":"Registration","revision":123,"uuid":"50c3706e-fedb-42c0-8eab-dda3335714b7"},"results":{"2factor":{"category":"","category_localized":"","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"2Factor","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"34634624463525"},"favorite_color":{"category":"Red","category_localized":"Red","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Favorite Color","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"red"},"phone_number":{"category":"","category_localized":"","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Phone Number","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"+12344563452"},"webhook":{"category":"Success","category_localized":"Success","created_on":"2018-04-11T13:24:30.123456Z","input":"GET http://127.0.0.1:49992/?content=%7B%22results%22%3A%5B%7B%22state%22%3A%22WA%22%7D%2C%7B%22state%22%3A%22IN%22%7D%5D%7D","name":"webhook","node_uuid":"f5bb9b7a-7b5e-45c3-8f0e-61b4e95edf03","value":"200"}},"status":"completed","uuid":"d2f852ec-7b4e-457f-ae7f-f8b243c49ff5"}`},
+		{"child", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-06-20T11:40:30.123456Z","fields":{"activation_token":"AACC55","age":23,"gender":"Male","join_date":"2017-12-02T00:00:00.000000-02:00","not_set":null},"groups":[{"name":"Testers","uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},{"name":"Males","uuid":"4f1f98fc-27a7-4a69-bbdb-24744ba739a9"}],"language":"eng","name":"Ryan Lewis","tickets":[],"timezone":"America/Guayaquil","urns":[{"display":"(206) 555-1212","path":"+12065551212","scheme":"tel"},{"display":"nyaruka","path":"54784326227","scheme":"twitterid"},{"display":"foo@bar.com","path":"foo@bar.com","scheme":"mailto"}],"uuid":"5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"},"flow":{"name":"Collect Age","revision":0,"uuid":"b7cf0d83-f1c9-411c-96fd-c511a4cfa86d"},"results":{"age":{"category":"Youth","category_localized":"Youth","created_on":"2018-04-11T13:24:30.123456Z","input":null,"name":"Age","node_uuid":"d9dba561-b5ee-4f62-ba44-60c4dc242b84","value":"23"}},"status":"completed","uuid":"8720f157-ca1c-432f-9c0b-2014ddc77094"}`},
+		{"parent", `{"contact":{"channel":{"address":"+12345671111","name":"My Android Phone","uuid":"57f1078f-88aa-46f4-a59a-948a5739c03d"},"created_on":"2018-01-01T12:00:00.000000Z","fields":{"activation_token":null,"age":33,"gender":"Female","join_date":null,"not_set":null},"groups":[],"language":"spa","name":"Jasmine","tickets":[],"timezone":null,"urns":[{"display":"097 911 1222","path":"+593979111222","scheme":"tel"}],"uuid":"c59b0033-e748-4240-9d4c-e85eb6800151"},"flow":{"name":"Parent","revision":0,"uuid":"fece6eac-9127-4343-9269-56e88f391562"},"results":{"role":{"category":"Reporter","category_localized":"Reporter","created_on":"2000-01-01T00:00:00.000000Z","input":"a reporter","name":"Role","node_uuid":"385cb848-5043-448e-9123-05cbcf26ad74","value":"reporter"}},"status":"active","uuid":"4213ac47-93fd-48c4-af12-7da8218ef09d"}`},
 		{"trigger", `{"params":{"source":"website","address":{"state":"WA"}},"type":"flow_action"}`},
 	}
 
@@ -269,8 +271,373 @@ func TestWaitTimeout(t *testing.T) {
 	require.Equal(t, 2, len(run.Path()))
 	require.Equal(t, 5, len(run.Events()))
 
+	// the resume is saved with the session so that it's still available when it's reloaded
+	sessionJSON, err := json.Marshal(session)
+	require.NoError(t, err)
+	session, err = session.Engine().ReadSession(session.Assets(), sessionJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+	require.NotNil(t, session.CurrentResume())
+	assert.Equal(t, "wait_timeout", session.CurrentResume().Type())
+
 	result := run.Results().Get("favorite_color")
 	require.Equal(t, "Timeout", result.Category)
 	require.Equal(t, "2018-04-11T13:34:40.123456Z", result.Value)
 	require.Nil(t, result.Input)
 }
+
+func TestCancelledSprint(t *testing.T) {
+	sessionAssets, err := ioutil.ReadFile("testdata/timeout_test.json")
+	require.NoError(t, err)
+
+	session, err := test.CreateSession(json.RawMessage(sessionAssets), "")
+	require.NoError(t, err)
+
+	flow, err := session.Assets().Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	contact := flows.NewEmptyContact(session.Assets(), "Joe", "eng", nil)
+	trigger := triggers.NewManualTrigger(nil, flow.Reference(), contact, nil)
+
+	// start the session with a context that has already been cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sprint, err := session.StartContext(ctx, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusErrored, session.Status())
+	assert.Equal(t, flows.RunStatusErrored, session.Runs()[0].Status())
+	assert.Equal(t, 0, len(session.Runs()[0].Path()))
+	assert.Equal(t, context.Background(), session.SprintContext())
+
+	require.Equal(t, 1, len(sprint.Events()))
+	errorEvent := sprint.Events()[0].(*events.ErrorEvent)
+	assert.True(t, errorEvent.Fatal)
+	assert.Equal(t, "sprint interrupted, stopping execution before entering '46d51f50-58de-49da-8d13-dadbf322685d': context canceled", errorEvent.Text)
+
+	// a session started normally can be interrupted when resumed with an expired context
+	session, err = test.CreateSession(json.RawMessage(sessionAssets), "")
+	require.NoError(t, err)
+
+	_, err = session.Start(trigger)
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "red", nil)
+	sprint, err = session.ResumeContext(ctx, resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusErrored, session.Status())
+	assert.Equal(t, flows.RunStatusErrored, session.Runs()[0].Status())
+	assert.Equal(t, "error", sprint.Events()[len(sprint.Events())-1].Type())
+	assert.Contains(t, sprint.Events()[len(sprint.Events())-1].(*events.ErrorEvent).Text, "context deadline exceeded")
+}
+
+func TestSessionTimeAndRand(t *testing.T) {
+	assetsJSON := `{
+		"flows": [
+			{
+				"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02",
+				"name": "Random",
+				"spec_version": "12.0",
+				"language": "eng",
+				"type": "messaging",
+				"nodes": [
+					{
+						"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+						"wait": {"type": "msg", "timeout": 600},
+						"router": {"type": "random", "result_name": "Pick"},
+						"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+					}
+				]
+			}
+		]
+	}`
+	source, err := static.NewSource([]byte(assetsJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	t1 := time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)
+	t2 := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// two engines with their own clocks but the same seed
+	eng1 := engine.NewBuilder().WithTimeSource(utils.NewFixedTimeSource(t1)).WithRandSeed(123).Build()
+	eng2 := engine.NewBuilder().WithTimeSource(utils.NewFixedTimeSource(t2)).WithRandSeed(123).Build()
+
+	runSession := func(eng flows.Engine) (flows.Session, flows.Sprint) {
+		session := eng.NewSession(sessionAssets)
+		contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+		sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+		require.NoError(t, err)
+
+		// save and reload the session before resuming it
+		sessionJSON, err := json.Marshal(session)
+		require.NoError(t, err)
+		assert.NotContains(t, string(sessionJSON), `"seed":123`)
+
+		session, err = eng.ReadSession(sessionAssets, sessionJSON, assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "hi", nil)
+		_, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+		require.NoError(t, err)
+
+		return session, sprint
+	}
+
+	session1, sprint1 := runSession(eng1)
+	session2, _ := runSession(eng2)
+
+	// each session uses its own clock
+	assert.Equal(t, t1, session1.Now())
+	assert.Equal(t, t2, session2.Now())
+	assert.Equal(t, t1, session1.Runs()[0].CreatedOn())
+	assert.Equal(t, t2, session2.Runs()[0].CreatedOn())
+
+	waitEvent := sprint1.Events()[0].(*events.MsgWaitEvent)
+	assert.Equal(t, t1, waitEvent.CreatedOn())
+	assert.Equal(t, t1.Add(time.Minute*10), *waitEvent.TimeoutOn)
+
+	// but the same seed gives the same random results, even after being saved and reloaded
+	result1 := session1.Runs()[0].Results().Get("pick")
+	result2 := session2.Runs()[0].Results().Get("pick")
+	assert.Equal(t, result1.Value, result2.Value)
+	assert.Equal(t, t1, result1.CreatedOn)
+	assert.Equal(t, t2, result2.CreatedOn)
+
+	// and functions like rand() and now() use them too
+	now, err := session1.Runs()[0].EvaluateTemplate("@(now())")
+	require.NoError(t, err)
+	assert.Equal(t, "2018-04-11T13:24:30.123456Z", now)
+
+	rand1, err := session1.Runs()[0].EvaluateTemplate("@(rand())")
+	require.NoError(t, err)
+	rand2, err := session2.Runs()[0].EvaluateTemplate("@(rand())")
+	require.NoError(t, err)
+	assert.Equal(t, rand1, rand2)
+}
+
+func TestEventAndModifierHooks(t *testing.T) {
+	assetsJSON := `{
+		"flows": [
+			{
+				"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02",
+				"name": "Hooks",
+				"spec_version": "12.0",
+				"language": "eng",
+				"type": "messaging",
+				"nodes": [
+					{
+						"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+						"actions": [
+							{"uuid": "d2f852ec-7b4e-457f-ae7f-f8b243c49ff5", "type": "set_contact_name", "name": "Bob"},
+							{"uuid": "e97cd6d5-3354-4dbd-85bc-6c1f87849eec", "type": "send_msg", "text": "Hi @contact.name"}
+						],
+						"wait": {"type": "msg"},
+						"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+					}
+				]
+			}
+		]
+	}`
+	source, err := static.NewSource([]byte(assetsJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	hookedEvents := make([]flows.Event, 0)
+	hookedModifiers := make([]flows.Modifier, 0)
+	var session flows.Session
+
+	eng := engine.NewBuilder().
+		WithEventHook(func(s flows.Session, e flows.Event) {
+			// hooks are called with the session while the sprint is still running
+			assert.Equal(t, session, s)
+			assert.Equal(t, flows.SessionStatusActive, s.Status())
+			hookedEvents = append(hookedEvents, e)
+		}).
+		WithModifierHook(func(s flows.Session, m flows.Modifier) {
+			assert.Equal(t, session, s)
+			hookedModifiers = append(hookedModifiers, m)
+		}).
+		Build()
+
+	session = eng.NewSession(sessionAssets)
+	contact := flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil)
+	sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, sprint.Events(), hookedEvents)
+	assert.Equal(t, sprint.Modifiers(), hookedModifiers)
+	assert.Equal(t, 1, len(hookedModifiers))
+	assert.Equal(t, []string{"contact_name_changed", "msg_created", "msg_wait"}, eventTypes(hookedEvents))
+
+	// events from resumes are passed to the hooks too
+	hookedEvents = hookedEvents[:0]
+
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+18005555777"), nil, "hi", nil)
+	sprint, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, sprint.Events(), hookedEvents)
+
+	// but sessions from engines without hooks are unaffected
+	session = engine.NewBuilder().Build().NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil), nil))
+	require.NoError(t, err)
+}
+
+func eventTypes(evts []flows.Event) []string {
+	types := make([]string, len(evts))
+	for i := range evts {
+		types[i] = evts[i].Type()
+	}
+	return types
+}
+
+func TestSprintBudgets(t *testing.T) {
+	assetsJSON := `{
+		"flows": [
+			{
+				"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02",
+				"name": "Webhooks",
+				"spec_version": "12.0",
+				"language": "eng",
+				"type": "messaging",
+				"nodes": [
+					{
+						"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+						"actions": [{"uuid": "d2f852ec-7b4e-457f-ae7f-f8b243c49ff5", "type": "call_webhook", "method": "GET", "url": "http://example.com/1"}],
+						"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1", "destination_node_uuid": "11a772f3-3ca2-4429-8b33-20fdcfc2b69e"}]
+					},
+					{
+						"uuid": "11a772f3-3ca2-4429-8b33-20fdcfc2b69e",
+						"actions": [{"uuid": "e97cd6d5-3354-4dbd-85bc-6c1f87849eec", "type": "call_webhook", "method": "GET", "url": "http://example.com/2"}],
+						"exits": [{"uuid": "c70fe86c-9aac-4cc2-a5cb-d35cbe3fed6e", "destination_node_uuid": "091decfb-c9b0-4dcf-954e-04927f119fc8"}]
+					},
+					{
+						"uuid": "091decfb-c9b0-4dcf-954e-04927f119fc8",
+						"actions": [{"uuid": "ec0cbd0a-0aaa-4cdd-8ce9-a0430b83d500", "type": "call_webhook", "method": "GET", "url": "http://example.com/3"}],
+						"exits": [{"uuid": "78ae8f05-f92e-43b2-a886-406eaea1b8e0", "destination_node_uuid": "a58be63b-907d-4a1a-856b-0bb5579d7507"}]
+					},
+					{
+						"uuid": "a58be63b-907d-4a1a-856b-0bb5579d7507",
+						"actions": [{"uuid": "d2a4052a-3fa9-4608-ab3e-5b9631440447", "type": "send_msg", "text": "Done"}],
+						"wait": {"type": "msg"},
+						"exits": [{"uuid": "cee79a7f-51fd-414a-a9fb-f9c1f1baf186"}]
+					}
+				]
+			}
+		]
+	}`
+	source, err := static.NewSource([]byte(assetsJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get(assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+
+	tcs := []struct {
+		configure func(*engine.Builder) *engine.Builder
+		status    flows.SessionStatus
+		errors    []string
+		code      string
+	}{
+		{
+			configure: func(b *engine.Builder) *engine.Builder { return b },
+			status:    flows.SessionStatusWaiting,
+		},
+		{
+			configure: func(b *engine.Builder) *engine.Builder { return b.WithMaxWebhookCallsPerSprint(2) },
+			status:    flows.SessionStatusErrored,
+			errors: []string{
+				"webhook call limit of 2 exceeded, call not made",
+				"webhook call limit exceeded, stopping execution before entering 'a58be63b-907d-4a1a-856b-0bb5579d7507'",
+			},
+			code: "webhook_call_limit_exceeded",
+		},
+		{
+			configure: func(b *engine.Builder) *engine.Builder { return b.WithMaxWebhookBytesPerSprint(20) },
+			status:    flows.SessionStatusErrored,
+			errors:    []string{"webhook response bytes limit exceeded, stopping execution before entering '091decfb-c9b0-4dcf-954e-04927f119fc8'"},
+			code:      "webhook_bytes_limit_exceeded",
+		},
+		{
+			configure: func(b *engine.Builder) *engine.Builder { return b.WithMaxEventsPerSprint(2) },
+			status:    flows.SessionStatusErrored,
+			errors:    []string{"event limit exceeded, stopping execution before entering 'a58be63b-907d-4a1a-856b-0bb5579d7507'"},
+			code:      "event_limit_exceeded",
+		},
+		{
+			configure: func(b *engine.Builder) *engine.Builder {
+				// every webhook call takes 60ms
+				slowWebhooks := flows.WebhookServiceFunc(func(s flows.Session, r *http.Request, resthook string) (*http.Response, string, error) {
+					time.Sleep(time.Millisecond * 60)
+					return flows.NewMockWebhookService(200, `{"ok": true}`).Call(s, r, resthook)
+				})
+				return b.WithWebhookService(slowWebhooks).WithMaxSprintDuration(time.Millisecond * 90)
+			},
+			status: flows.SessionStatusErrored,
+			errors: []string{"sprint duration limit exceeded, stopping execution before entering '091decfb-c9b0-4dcf-954e-04927f119fc8'"},
+			code:   "duration_limit_exceeded",
+		},
+		{
+			configure: func(b *engine.Builder) *engine.Builder {
+				// the engine's clock moves a minute with every webhook call but that isn't how long the sprint takes
+				clock := &testClock{now: time.Date(2018, 4, 11, 13, 24, 30, 0, time.UTC)}
+				fastWebhooks := flows.WebhookServiceFunc(func(s flows.Session, r *http.Request, resthook string) (*http.Response, string, error) {
+					clock.now = clock.now.Add(time.Minute)
+					return flows.NewMockWebhookService(200, `{"ok": true}`).Call(s, r, resthook)
+				})
+				return b.WithTimeSource(clock).WithWebhookService(fastWebhooks).WithMaxSprintDuration(time.Second * 90)
+			},
+			status: flows.SessionStatusWaiting,
+		},
+	}
+
+	for i, tc := range tcs {
+		webhooks := flows.NewMockWebhookService(200, `{"ok": true}`)
+		eng := tc.configure(engine.NewBuilder().WithWebhookService(webhooks)).Build()
+
+		session := eng.NewSession(sessionAssets)
+		sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), flows.NewEmptyContact(sessionAssets, "Joe", "eng", nil), nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.status, session.Status(), "status mismatch in test case #%d", i)
+
+		errorTexts := make([]string, 0)
+		code := ""
+		for _, e := range sprint.Events() {
+			if errorEvent, isError := e.(*events.ErrorEvent); isError {
+				errorTexts = append(errorTexts, errorEvent.Text)
+				if errorEvent.Fatal {
+					code = errorEvent.Code
+				}
+			}
+		}
+		if tc.errors == nil {
+			tc.errors = []string{}
+		}
+		assert.Equal(t, tc.errors, errorTexts, "errors mismatch in test case #%d", i)
+		assert.Equal(t, tc.code, code, "error code mismatch in test case #%d", i)
+	}
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
@@ -1,12 +1,22 @@
 package engine
 
 import (
+	"time"
+
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
 )
 
 type sprint struct {
 	modifiers []flows.Modifier
 	events    []flows.Event
+
+	// optional clock used to re-stamp events as they're logged
+	now func() time.Time
+
+	// optional callbacks invoked as each modifier or event is logged
+	onModifier flows.ModifierCallback
+	onEvent    flows.EventCallback
 }
 
 // NewEmptySprint creates a new sprint
@@ -25,15 +35,49 @@ func NewSprint(modifiers []flows.Modifier, events []flows.Event) flows.Sprint {
 	}
 }
 
+// creates a new sprint for the given session which stamps events with the session's clock and passes modifiers and
+// events to the engine's hooks as they're logged
+func newSprintForSession(session flows.Session) *sprint {
+	s := &sprint{
+		modifiers: make([]flows.Modifier, 0),
+		events:    make([]flows.Event, 0),
+	}
+
+	// events are created with the global time so only need re-stamping if the engine has its own time source
+	if session.Engine().TimeSource() != utils.GlobalTimeSource {
+		s.now = session.Now
+	}
+
+	if hook := session.Engine().ModifierHook(); hook != nil {
+		s.onModifier = func(m flows.Modifier) { hook(session, m) }
+	}
+	if hook := session.Engine().EventHook(); hook != nil {
+		s.onEvent = func(e flows.Event) { hook(session, e) }
+	}
+	return s
+}
+
 func (s *sprint) Modifiers() []flows.Modifier { return s.modifiers }
 func (s *sprint) Events() []flows.Event       { return s.events }
 
 func (s *sprint) LogModifier(m flows.Modifier) {
 	s.modifiers = append(s.modifiers, m)
+
+	if s.onModifier != nil {
+		s.onModifier(m)
+	}
 }
 
 func (s *sprint) LogEvent(e flows.Event) {
+	if s.now != nil {
+		e.SetCreatedOn(s.now())
+	}
+
 	s.events = append(s.events, e)
+
+	if s.onEvent != nil {
+		s.onEvent(e)
+	}
 }
 
 var _ flows.Sprint = (*sprint)(nil)
@@ -24,7 +24,7 @@ type BaseEvent struct {
 	StepUUID_  flows.StepUUID `json:"step_uuid,omitempty" validate:"omitempty,uuid4"`
 }
 
-// NewBaseEvent creates a new base event
+// NewBaseEvent creates a new base event. Events logged by a session with its own time source are re-stamped with it.
 func NewBaseEvent(typeName string) BaseEvent {
 	return BaseEvent{Type_: typeName, CreatedOn_: utils.Now()}
 }
@@ -35,6 +35,9 @@ func (e *BaseEvent) Type() string { return e.Type_ }
 // CreatedOn returns the created on time of this event
 func (e *BaseEvent) CreatedOn() time.Time { return e.CreatedOn_ }
 
+// SetCreatedOn sets the created on time of this event
+func (e *BaseEvent) SetCreatedOn(createdOn time.Time) { e.CreatedOn_ = createdOn }
+
 // StepUUID returns the UUID of the step in the path where this event occured
 func (e *BaseEvent) StepUUID() flows.StepUUID { return e.StepUUID_ }
 
@@ -79,6 +79,21 @@ func TestEventMarshaling(t *testing.T) {
 				]
 			}`,
 		},
+		{
+			events.NewContactConsentChangedEvent(
+				assets.NewChannelReference(assets.ChannelUUID("57f1078f-88aa-46f4-a59a-948a5739c03d"), "WhatsApp"),
+				flows.ConsentStatusGranted,
+			),
+			`{
+				"channel": {
+					"name": "WhatsApp",
+					"uuid": "57f1078f-88aa-46f4-a59a-948a5739c03d"
+				},
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"status": "granted",
+				"type": "contact_consent_changed"
+			}`,
+		},
 		{
 			events.NewContactFieldChangedEvent(
 				gender,
@@ -211,6 +226,27 @@ func TestEventMarshaling(t *testing.T) {
 				]
 			}`,
 		},
+		{
+			events.NewDialEndedEvent(flows.NewDial(flows.DialStatusAnswered, 15)),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"dial": {
+					"duration": 15,
+					"status": "answered"
+				},
+				"type": "dial_ended"
+			}`,
+		},
+		{
+			events.NewDialWaitEvent(urns.URN("tel:+593979123456"), 60, 7200),
+			`{
+				"call_limit_seconds": 7200,
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"dial_limit_seconds": 60,
+				"type": "dial_wait",
+				"urn": "tel:+593979123456"
+			}`,
+		},
 		{
 			events.NewEnvironmentRefreshedEvent(session.Environment()),
 			`{
@@ -235,7 +271,36 @@ func TestEventMarshaling(t *testing.T) {
 			}`,
 		},
 		{
-			events.NewIVRCreatedEvent(flows.NewMsgOut(urns.URN("tel:+12345678900"), assets.NewChannelReference(assets.ChannelUUID("57f1078f-88aa-46f4-a59a-948a5739c03d"), "My Android Phone"), "Hi there", nil, nil)),
+			events.NewExternalEventReceivedEvent("payment_confirmed", "ORD-1234", json.RawMessage(`{"status":"paid"}`)),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"event": "payment_confirmed",
+				"key": "ORD-1234",
+				"payload": {
+					"status": "paid"
+				},
+				"type": "external_event_received"
+			}`,
+		},
+		{
+			events.NewExternalEventWaitEvent("payment_confirmed", "ORD-1234", nil),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"event": "payment_confirmed",
+				"key": "ORD-1234",
+				"type": "external_event_wait"
+			}`,
+		},
+		{
+			events.NewTimerWaitEvent(time.Date(2018, 10, 20, 9, 0, 0, 0, time.UTC)),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"timeout_on": "2018-10-20T09:00:00Z",
+				"type": "timer_wait"
+			}`,
+		},
+		{
+			events.NewIVRCreatedEvent(flows.NewMsgOut(urns.URN("tel:+12345678900"), assets.NewChannelReference(assets.ChannelUUID("57f1078f-88aa-46f4-a59a-948a5739c03d"), "My Android Phone"), "Hi there", nil, nil, nil)),
 			`{
 				"created_on": "2018-10-18T14:20:30.000123456Z",
 				"msg": {
@@ -250,6 +315,15 @@ func TestEventMarshaling(t *testing.T) {
 				"type": "ivr_created"
 			}`,
 		},
+		{
+			events.NewMsgRejectedEvent("digits", 1),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"hint_type": "digits",
+				"retries_remaining": 1,
+				"type": "msg_rejected"
+			}`,
+		},
 		{
 			events.NewSessionTriggeredEvent(
 				assets.NewFlowReference(assets.FlowUUID("e4d441f0-24e3-4627-85fb-1e99e733baf0"), "Collect Age"),
@@ -290,6 +364,33 @@ func TestEventMarshaling(t *testing.T) {
 				]
 			}`,
 		},
+		{
+			events.NewTicketOpenedEvent(
+				flows.NewTicket(
+					assets.NewTicketerReference(assets.TicketerUUID("19dc6346-9623-4fe4-be80-538d493ecdf5"), "Support Tickets"),
+					"Need help",
+					"Where are my cookies?",
+					"Orders",
+					time.Date(2018, 10, 18, 14, 20, 30, 123456, time.UTC),
+				),
+			),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket": {
+					"body": "Where are my cookies?",
+					"opened_on": "2018-10-18T14:20:30.000123456Z",
+					"status": "open",
+					"subject": "Need help",
+					"ticketer": {
+						"name": "Support Tickets",
+						"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5"
+					},
+					"topic": "Orders",
+					"uuid": "20cc4181-48cf-4344-9751-99419796decd"
+				},
+				"type": "ticket_opened"
+			}`,
+		},
 	}
 
 	for _, tc := range eventTests {
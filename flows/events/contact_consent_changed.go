@@ -0,0 +1,39 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeContactConsentChanged, func() flows.Event { return &ContactConsentChangedEvent{} })
+}
+
+// TypeContactConsentChanged is the type of our contact consent changed event
+const TypeContactConsentChanged string = "contact_consent_changed"
+
+// ContactConsentChangedEvent events are created when a contact grants or revokes consent to be messaged on a channel.
+//
+//   {
+//     "type": "contact_consent_changed",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "channel": {"uuid": "57f1078f-88aa-46f4-a59a-948a5739c03d", "name": "WhatsApp"},
+//     "status": "granted"
+//   }
+//
+// @event contact_consent_changed
+type ContactConsentChangedEvent struct {
+	BaseEvent
+
+	Channel *assets.ChannelReference `json:"channel" validate:"required,dive"`
+	Status  flows.ConsentStatus      `json:"status" validate:"required,eq=granted|eq=revoked"`
+}
+
+// NewContactConsentChangedEvent returns a new contact consent changed event
+func NewContactConsentChangedEvent(channel *assets.ChannelReference, status flows.ConsentStatus) *ContactConsentChangedEvent {
+	return &ContactConsentChangedEvent{
+		BaseEvent: NewBaseEvent(TypeContactConsentChanged),
+		Channel:   channel,
+		Status:    status,
+	}
+}
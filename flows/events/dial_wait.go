@@ -0,0 +1,47 @@
+package events
+
+import (
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeDialWait, func() flows.Event { return &DialWaitEvent{} })
+}
+
+// TypeDialWait is the type of our dial wait event
+const TypeDialWait string = "dial_wait"
+
+// DialWaitEvent events are created when a voice flow pauses waiting for the caller to be connected to another phone
+// number. The caller should dial the URN, giving up if it isn't answered within the dial limit, and hanging up the
+// forwarded call if it lasts longer than the call limit. The session should then be resumed with a
+// [resume:dial] resume describing the outcome.
+//
+//   {
+//     "type": "dial_wait",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "urn": "tel:+593979123456",
+//     "dial_limit_seconds": 60,
+//     "call_limit_seconds": 7200
+//   }
+//
+// @event dial_wait
+type DialWaitEvent struct {
+	BaseEvent
+
+	URN              urns.URN `json:"urn" validate:"required,urn"`
+	DialLimitSeconds int      `json:"dial_limit_seconds"`
+	CallLimitSeconds int      `json:"call_limit_seconds"`
+}
+
+// NewDialWaitEvent returns a new dial wait for the passed in URN and limits
+func NewDialWaitEvent(urn urns.URN, dialLimitSeconds, callLimitSeconds int) *DialWaitEvent {
+	return &DialWaitEvent{
+		BaseEvent:        NewBaseEvent(TypeDialWait),
+		URN:              urn,
+		DialLimitSeconds: dialLimitSeconds,
+		CallLimitSeconds: callLimitSeconds,
+	}
+}
+
+var _ flows.Event = (*DialWaitEvent)(nil)
@@ -25,6 +25,7 @@ type ErrorEvent struct {
 	BaseEvent
 
 	Text  string `json:"text" validate:"required"`
+	Code  string `json:"code,omitempty"`
 	Fatal bool   `json:"fatal"`
 }
 
@@ -44,11 +45,16 @@ func NewErrorEventf(format string, a ...interface{}) *ErrorEvent {
 	}
 }
 
-// NewFatalErrorEvent returns a new fatal error event for the passed in error
+// NewFatalErrorEvent returns a new fatal error event for the passed in error. If the error has a code, e.g. because
+// a sprint budget was exceeded, that is included in the event.
 func NewFatalErrorEvent(err error) *ErrorEvent {
-	return &ErrorEvent{
+	event := &ErrorEvent{
 		BaseEvent: NewBaseEvent(TypeError),
 		Text:      err.Error(),
 		Fatal:     true,
 	}
+	if coded, isCoded := err.(interface{ Code() string }); isCoded {
+		event.Code = coded.Code()
+	}
+	return event
 }
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeExternalEventReceived, func() flows.Event { return &ExternalEventReceivedEvent{} })
+}
+
+// TypeExternalEventReceived is the type of our external event received event
+const TypeExternalEventReceived string = "external_event_received"
+
+// ExternalEventReceivedEvent events are created when a session waiting on an external event is resumed by that event.
+//
+//   {
+//     "type": "external_event_received",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "event": "payment_confirmed",
+//     "key": "ORD-1234",
+//     "payload": {"status": "paid", "amount": 25.50}
+//   }
+//
+// @event external_event_received
+type ExternalEventReceivedEvent struct {
+	BaseEvent
+
+	Event   string          `json:"event" validate:"required"`
+	Key     string          `json:"key" validate:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewExternalEventReceivedEvent returns a new external event received event
+func NewExternalEventReceivedEvent(event string, key string, payload json.RawMessage) *ExternalEventReceivedEvent {
+	return &ExternalEventReceivedEvent{
+		BaseEvent: NewBaseEvent(TypeExternalEventReceived),
+		Event:     event,
+		Key:       key,
+		Payload:   payload,
+	}
+}
+
+var _ flows.Event = (*ExternalEventReceivedEvent)(nil)
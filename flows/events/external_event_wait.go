@@ -0,0 +1,47 @@
+package events
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeExternalEventWait, func() flows.Event { return &ExternalEventWaitEvent{} })
+}
+
+// TypeExternalEventWait is the type of our external event wait event
+const TypeExternalEventWait string = "external_event_wait"
+
+// ExternalEventWaitEvent events are created when a flow pauses waiting for an event from a third party, such as a
+// payment confirmation. The caller should resume the session with an [resume:external_event] resume when an event
+// with the given name and correlation key arrives. If a timeout is set, then the caller should resume the session
+// after the timeout if the event hasn't arrived.
+//
+//   {
+//     "type": "external_event_wait",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "event": "payment_confirmed",
+//     "key": "ORD-1234"
+//   }
+//
+// @event external_event_wait
+type ExternalEventWaitEvent struct {
+	BaseEvent
+
+	Event     string     `json:"event" validate:"required"`
+	Key       string     `json:"key" validate:"required"`
+	TimeoutOn *time.Time `json:"timeout_on,omitempty"`
+}
+
+// NewExternalEventWaitEvent returns a new external event wait for the given event name and correlation key
+func NewExternalEventWaitEvent(event string, key string, timeoutOn *time.Time) *ExternalEventWaitEvent {
+	return &ExternalEventWaitEvent{
+		BaseEvent: NewBaseEvent(TypeExternalEventWait),
+		Event:     event,
+		Key:       key,
+		TimeoutOn: timeoutOn,
+	}
+}
+
+var _ flows.Event = (*ExternalEventWaitEvent)(nil)
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeMsgRejected, func() flows.Event { return &MsgRejectedEvent{} })
+}
+
+// TypeMsgRejected is the type of our msg rejected event
+const TypeMsgRejected string = "msg_rejected"
+
+// MsgRejectedEvent events are created when a message wait which enforces its hint is resumed with a message that
+// doesn't satisfy that hint, e.g. a reply which isn't 4 digits when a 4 digit PIN was requested. If there are retries
+// remaining, the contact is re-prompted and the flow continues waiting, otherwise the flow takes the wait's dedicated exit.
+//
+//   {
+//     "type": "msg_rejected",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "hint_type": "digits",
+//     "retries_remaining": 1
+//   }
+//
+// @event msg_rejected
+type MsgRejectedEvent struct {
+	BaseEvent
+
+	HintType         string `json:"hint_type" validate:"required"`
+	RetriesRemaining int    `json:"retries_remaining"`
+}
+
+// NewMsgRejectedEvent returns a new msg rejected event for the given hint type
+func NewMsgRejectedEvent(hintType string, retriesRemaining int) *MsgRejectedEvent {
+	return &MsgRejectedEvent{
+		BaseEvent:        NewBaseEvent(TypeMsgRejected),
+		HintType:         hintType,
+		RetriesRemaining: retriesRemaining,
+	}
+}
+
+var _ flows.Event = (*MsgRejectedEvent)(nil)
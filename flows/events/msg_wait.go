@@ -14,13 +14,13 @@ func init() {
 const TypeMsgWait string = "msg_wait"
 
 // MsgWaitEvent events are created when a flow pauses waiting for a response from
-// a contact. If a timeout is set, then the caller should resume the flow after
-// the number of seconds in the timeout to resume it.
+// a contact. If a timeout is set, then the caller should resume the flow with a
+// wait timeout once the time in `timeout_on` has passed.
 //
 //   {
 //     "type": "msg_wait",
 //     "created_on": "2006-01-02T15:04:05Z",
-//     "timeout": 300
+//     "timeout_on": "2006-01-02T15:09:05Z"
 //   }
 //
 // @event msg_wait
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeTicketClosed, func() flows.Event { return &TicketClosedEvent{} })
+}
+
+// TypeTicketClosed is the type of our ticket closed event
+const TypeTicketClosed string = "ticket_closed"
+
+// TicketClosedEvent events are created when a session is resumed because an agent closed a ticket.
+//
+//   {
+//     "type": "ticket_closed",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket": {
+//       "uuid": "78d1fe0d-7e39-461e-81c3-a6a25f15ed69",
+//       "ticketer": {"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5", "name": "Support Tickets"},
+//       "subject": "Need help",
+//       "body": "Where are my cookies?",
+//       "topic": "Orders",
+//       "status": "closed",
+//       "opened_on": "2006-01-02T15:04:05Z"
+//     }
+//   }
+//
+// @event ticket_closed
+type TicketClosedEvent struct {
+	BaseEvent
+
+	Ticket *flows.Ticket `json:"ticket" validate:"required,dive"`
+}
+
+// NewTicketClosedEvent returns a new ticket closed event
+func NewTicketClosedEvent(ticket *flows.Ticket) *TicketClosedEvent {
+	return &TicketClosedEvent{
+		BaseEvent: NewBaseEvent(TypeTicketClosed),
+		Ticket:    ticket,
+	}
+}
+
+var _ flows.Event = (*TicketClosedEvent)(nil)
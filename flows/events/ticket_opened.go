@@ -0,0 +1,47 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeTicketOpened, func() flows.Event { return &TicketOpenedEvent{} })
+}
+
+// TypeTicketOpened is the type of our ticket opened event
+const TypeTicketOpened string = "ticket_opened"
+
+// TicketOpenedEvent events are created when a ticket is opened to hand the contact off to a human agent. The caller
+// should open the ticket with the ticketer, and when an agent closes it, resume any session waiting on it with a
+// [resume:ticket_closed] resume.
+//
+//   {
+//     "type": "ticket_opened",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket": {
+//       "uuid": "78d1fe0d-7e39-461e-81c3-a6a25f15ed69",
+//       "ticketer": {"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5", "name": "Support Tickets"},
+//       "subject": "Need help",
+//       "body": "Where are my cookies?",
+//       "topic": "Orders",
+//       "status": "open",
+//       "opened_on": "2006-01-02T15:04:05Z"
+//     }
+//   }
+//
+// @event ticket_opened
+type TicketOpenedEvent struct {
+	BaseEvent
+
+	Ticket *flows.Ticket `json:"ticket" validate:"required,dive"`
+}
+
+// NewTicketOpenedEvent returns a new ticket opened event
+func NewTicketOpenedEvent(ticket *flows.Ticket) *TicketOpenedEvent {
+	return &TicketOpenedEvent{
+		BaseEvent: NewBaseEvent(TypeTicketOpened),
+		Ticket:    ticket,
+	}
+}
+
+var _ flows.Event = (*TicketOpenedEvent)(nil)
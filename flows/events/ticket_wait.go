@@ -0,0 +1,43 @@
+package events
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeTicketWait, func() flows.Event { return &TicketWaitEvent{} })
+}
+
+// TypeTicketWait is the type of our ticket wait event
+const TypeTicketWait string = "ticket_wait"
+
+// TicketWaitEvent events are created when a flow pauses waiting for an agent to close a ticket. The caller should resume
+// the session with a [resume:ticket_closed] resume when the ticket with the given UUID is closed. If a timeout is set,
+// then the caller should resume the session after the timeout if the ticket hasn't been closed.
+//
+//   {
+//     "type": "ticket_wait",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "78d1fe0d-7e39-461e-81c3-a6a25f15ed69"
+//   }
+//
+// @event ticket_wait
+type TicketWaitEvent struct {
+	BaseEvent
+
+	TicketUUID flows.TicketUUID `json:"ticket_uuid" validate:"required,uuid4"`
+	TimeoutOn  *time.Time       `json:"timeout_on,omitempty"`
+}
+
+// NewTicketWaitEvent returns a new ticket wait for the ticket with the given UUID
+func NewTicketWaitEvent(ticketUUID flows.TicketUUID, timeoutOn *time.Time) *TicketWaitEvent {
+	return &TicketWaitEvent{
+		BaseEvent:  NewBaseEvent(TypeTicketWait),
+		TicketUUID: ticketUUID,
+		TimeoutOn:  timeoutOn,
+	}
+}
+
+var _ flows.Event = (*TicketWaitEvent)(nil)
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	RegisterType(TypeTimerWait, func() flows.Event { return &TimerWaitEvent{} })
+}
+
+// TypeTimerWait is the type of our timer wait event
+const TypeTimerWait string = "timer_wait"
+
+// TimerWaitEvent events are created when a flow pauses for a delay. The caller should resume the session with a
+// [resume:wait_timeout] resume once the timeout has passed. Messages from the contact don't end the wait.
+//
+//   {
+//     "type": "timer_wait",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "timeout_on": "2006-01-04T15:04:05Z"
+//   }
+//
+// @event timer_wait
+type TimerWaitEvent struct {
+	BaseEvent
+
+	TimeoutOn time.Time `json:"timeout_on" validate:"required"`
+}
+
+// NewTimerWaitEvent returns a new timer wait which times out at the given time
+func NewTimerWaitEvent(timeoutOn time.Time) *TimerWaitEvent {
+	return &TimerWaitEvent{
+		BaseEvent: NewBaseEvent(TypeTimerWait),
+		TimeoutOn: timeoutOn,
+	}
+}
+
+var _ flows.Event = (*TimerWaitEvent)(nil)
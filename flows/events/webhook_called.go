@@ -15,7 +15,8 @@ const TypeWebhookCalled string = "webhook_called"
 
 // WebhookCalledEvent events are created when a webhook is called. The event contains
 // the URL and the status of the response, as well as a full dump of the
-// request and response.
+// request and response. If the call was retried, the outcome of each attempt is included
+// as `attempts`, and the rest of the event describes the final attempt.
 //
 //   {
 //     "type": "webhook_called",
@@ -32,14 +33,15 @@ const TypeWebhookCalled string = "webhook_called"
 type WebhookCalledEvent struct {
 	BaseEvent
 
-	URL         string              `json:"url" validate:"required"`
-	Resthook    string              `json:"resthook,omitempty"`
-	Status      flows.WebhookStatus `json:"status" validate:"required"`
-	StatusCode  int                 `json:"status_code,omitempty"`
-	ElapsedMS   int                 `json:"elapsed_ms"`
-	Request     string              `json:"request" validate:"required"`
-	Response    string              `json:"response,omitempty"`
-	BodyIgnored bool                `json:"body_ignored,omitempty"`
+	URL         string                  `json:"url" validate:"required"`
+	Resthook    string                  `json:"resthook,omitempty"`
+	Status      flows.WebhookStatus     `json:"status" validate:"required"`
+	StatusCode  int                     `json:"status_code,omitempty"`
+	ElapsedMS   int                     `json:"elapsed_ms"`
+	Request     string                  `json:"request" validate:"required"`
+	Response    string                  `json:"response,omitempty"`
+	BodyIgnored bool                    `json:"body_ignored,omitempty"`
+	Attempts    []*flows.WebhookAttempt `json:"attempts,omitempty" validate:"omitempty,dive"`
 }
 
 // NewWebhookCalledEvent returns a new webhook called event
@@ -54,5 +56,6 @@ func NewWebhookCalledEvent(webhook *flows.WebhookCall) *WebhookCalledEvent {
 		Request:     webhook.Request(),
 		Response:    webhook.Response(),
 		BodyIgnored: webhook.BodyIgnored(),
+		Attempts:    webhook.Attempts(),
 	}
 }
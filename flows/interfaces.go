@@ -1,7 +1,9 @@
 package flows
 
 import (
+	"context"
 	"encoding/json"
+	"math/rand"
 	"time"
 
 	"github.com/nyaruka/goflow/assets"
@@ -112,12 +114,15 @@ type FlowAssets interface {
 // SessionAssets is the assets available to a session
 type SessionAssets interface {
 	Channels() *ChannelAssets
+	Classifiers() *ClassifierAssets
 	Fields() *FieldAssets
 	Flows() FlowAssets
 	Groups() *GroupAssets
 	Labels() *LabelAssets
 	Locations() *LocationAssets
 	Resthooks() *ResthookAssets
+	Templates() *TemplateAssets
+	Ticketers() *TicketerAssets
 }
 
 type Localizable interface {
@@ -159,6 +164,9 @@ type Flow interface {
 	Type() FlowType
 	ExpireAfterMinutes() int
 	Localization() Localization
+	Parameters() ParamSchema
+	Inputs() ParamSchema
+	Outputs() OutputSchema
 
 	// optional spec properties
 	UI() UI
@@ -223,13 +231,25 @@ type Wait interface {
 
 	Timeout() *int
 	TimeoutOn() *time.Time
+	AllowedFlowTypes() []FlowType
+	Validate([]Exit) error
 
 	Begin(FlowRun, EventCallback) bool
-	End(Resume, Node) error
+	End(FlowRun, Resume, Node) error
+}
+
+// WaitWithValidation is special case of wait that can reject the input it was resumed with, and either keep waiting
+// or route the run to a dedicated exit
+type WaitWithValidation interface {
+	Wait
+
+	ValidateResume(FlowRun, Resume, Node, EventCallback) (bool, Route)
 }
 
 type Hint interface {
 	utils.Typed
+
+	Accepts(*MsgIn) bool
 }
 
 // Localization provide a way to get the translations for a specific language
@@ -268,7 +288,8 @@ type Position interface {
 // accessed in expressions:
 //
 //  * `type` the type of the trigger, one of "manual" or "flow"
-//  * `params` the parameters passed to the trigger
+//  * `params` the parameters passed to the trigger, e.g. the referrer of a referral channel event
+//  * `schedule` the schedule which fired, for schedule triggers
 //
 // Examples:
 //
@@ -300,9 +321,23 @@ type TriggerWithRun interface {
 	RunSummary() json.RawMessage
 }
 
-// Resume represents something which can resume a session with the flow engine
+// Resume represents something which can resume a session with the flow engine. The resume of the current sprint can be
+// accessed in expressions and has the following properties:
+//
+//  * `type` the type of the resume, e.g. "msg" or "dial"
+//  * `resumed_on` the time when the session was resumed
+//  * `dial` the outcome of the dial, for dial resumes
+//
+// Examples:
+//
+//   @resume.type -> msg
+//   @(json(resume)) -> {"resumed_on":"2017-12-31T11:35:10.035757-02:00","type":"msg"}
+//
+// @context resume
 type Resume interface {
 	utils.Typed
+	types.XValue
+	types.XResolvable
 
 	Apply(FlowRun, EventCallback) error
 
@@ -321,11 +356,15 @@ type Modifier interface {
 // ModifierCallback is a callback invoked when a modifier has been generated
 type ModifierCallback func(Modifier)
 
+// ModifierHook is a callback invoked by the engine as each modifier is generated in a session
+type ModifierHook func(Session, Modifier)
+
 // Event describes a state change
 type Event interface {
 	utils.Typed
 
 	CreatedOn() time.Time
+	SetCreatedOn(time.Time)
 	StepUUID() StepUUID
 	SetStepUUID(StepUUID)
 }
@@ -333,6 +372,9 @@ type Event interface {
 // EventCallback is a callback invoked when an event has been generated
 type EventCallback func(Event)
 
+// EventHook is a callback invoked by the engine as each event is generated in a session
+type EventHook func(Session, Event)
+
 // Input describes input from the contact and currently we only support one type of input: `msg`. Any input has the following
 // properties which can be accessed:
 //
@@ -382,9 +424,21 @@ type Engine interface {
 	ReadSession(SessionAssets, json.RawMessage, assets.MissingCallback) (Session, error)
 
 	HTTPClient() *utils.HTTPClient
+	WebhookService() WebhookService
+	WebhookCircuitBreaker() *WebhookCircuitBreaker
+	ClassifierService() ClassifierService
+	TimeSource() utils.TimeSource
+	EventHook() EventHook
+	ModifierHook() ModifierHook
+	RetentionPolicy() *RetentionPolicy
+	Debugger() Debugger
 	DisableWebhooks() bool
 	MaxWebhookResponseBytes() int
 	MaxStepsPerSprint() int
+	MaxEventsPerSprint() int
+	MaxWebhookCallsPerSprint() int
+	MaxWebhookBytesPerSprint() int
+	MaxSprintDuration() time.Duration
 }
 
 // Sprint is an interaction with the engine - i.e. a start or resume of a session
@@ -410,17 +464,24 @@ type Session interface {
 
 	Input() Input
 	SetInput(Input)
+	CurrentResume() Resume
 
 	Status() SessionStatus
 	Trigger() Trigger
-	PushFlow(Flow, FlowRun, bool)
+	PushFlow(Flow, FlowRun, bool, types.XValue, map[string]string)
 	Wait() Wait
 
 	Start(Trigger) (Sprint, error)
+	StartContext(context.Context, Trigger) (Sprint, error)
 	Resume(Resume) (Sprint, error)
+	ResumeContext(context.Context, Resume) (Sprint, error)
+	SprintContext() context.Context
+	SprintBudget() *SprintBudget
+	Now() time.Time
+	Rand() *rand.Rand
 	Runs() []FlowRun
 	GetRun(RunUUID) (FlowRun, error)
-	GetCurrentChild(FlowRun) FlowRun
+	GetCurrentChild(FlowRun) RunSummary
 	ParentRun() RunSummary
 
 	Engine() Engine
@@ -454,6 +515,7 @@ type RunEnvironment interface {
 //  * `input` the [input](#context:input) of the current run
 //  * `results` the results that have been saved for this run
 //  * `results.[snaked_result_name]` the value of the specific result, e.g. `results.age`
+//  * `params` the params the run was entered with by an [action:enter_flow] action
 //
 // Examples:
 //
@@ -468,6 +530,8 @@ type FlowRun interface {
 	Environment() RunEnvironment
 	Session() Session
 	Context() types.XValue
+	Params() types.XValue
+	Returns() map[string]string
 	SaveResult(*Result)
 	SetStatus(RunStatus)
 
@@ -477,7 +541,9 @@ type FlowRun interface {
 	CreateStep(Node) Step
 	Path() []Step
 	PathLocation() (Step, Node, error)
+	PrunePath(int)
 	Events() []Event
+	PruneEvents(int)
 
 	EvaluateTemplateValue(template string) (types.XValue, error)
 	EvaluateTemplate(template string) (string, error)
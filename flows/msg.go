@@ -27,9 +27,32 @@ type MsgIn struct {
 type MsgOut struct {
 	BaseMsg
 
-	QuickReplies_ []string `json:"quick_replies,omitempty"`
+	QuickReplies_ []string       `json:"quick_replies,omitempty"`
+	Templating_   *MsgTemplating `json:"templating,omitempty"`
 }
 
+// MsgTemplating describes the template an outgoing message was created from, and the variables which were substituted
+// into it, so that the channel can send it as that template
+type MsgTemplating struct {
+	Template_  *assets.TemplateReference `json:"template" validate:"required,dive"`
+	Language_  utils.Language            `json:"language" validate:"required,language"`
+	Variables_ []string                  `json:"variables,omitempty"`
+}
+
+// NewMsgTemplating creates a new message templating object
+func NewMsgTemplating(template *assets.TemplateReference, language utils.Language, variables []string) *MsgTemplating {
+	return &MsgTemplating{Template_: template, Language_: language, Variables_: variables}
+}
+
+// Template returns the template this msg was created from
+func (t *MsgTemplating) Template() *assets.TemplateReference { return t.Template_ }
+
+// Language returns the language of the template translation which was used
+func (t *MsgTemplating) Language() utils.Language { return t.Language_ }
+
+// Variables returns the evaluated variables which were substituted into the template
+func (t *MsgTemplating) Variables() []string { return t.Variables_ }
+
 // NewMsgIn creates a new incoming message
 func NewMsgIn(uuid MsgUUID, urn urns.URN, channel *assets.ChannelReference, text string, attachments []Attachment) *MsgIn {
 	return &MsgIn{
@@ -44,7 +67,7 @@ func NewMsgIn(uuid MsgUUID, urn urns.URN, channel *assets.ChannelReference, text
 }
 
 // NewMsgOut creates a new outgoing message
-func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, attachments []Attachment, quickReplies []string) *MsgOut {
+func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, attachments []Attachment, quickReplies []string, templating *MsgTemplating) *MsgOut {
 	return &MsgOut{
 		BaseMsg: BaseMsg{
 			UUID_:        MsgUUID(utils.NewUUID()),
@@ -54,6 +77,7 @@ func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, atta
 			Attachments_: attachments,
 		},
 		QuickReplies_: quickReplies,
+		Templating_:   templating,
 	}
 }
 
@@ -86,3 +110,6 @@ func (m *MsgIn) SetExternalID(id string) { m.ExternalID_ = id }
 
 // QuickReplies returns the quick replies of this outgoing message
 func (m *MsgOut) QuickReplies() []string { return m.QuickReplies_ }
+
+// Templating returns the templating of this outgoing message, if it was created from a template
+func (m *MsgOut) Templating() *MsgTemplating { return m.Templating_ }
@@ -0,0 +1,186 @@
+package flows
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	utils.Validator.RegisterAlias("param_type", "eq=text|eq=number|eq=datetime|eq=boolean")
+}
+
+// ParamType is the type of value a declared parameter must have
+type ParamType string
+
+// the types a declared parameter can have
+const (
+	ParamTypeText     ParamType = "text"
+	ParamTypeNumber   ParamType = "number"
+	ParamTypeDatetime ParamType = "datetime"
+	ParamTypeBoolean  ParamType = "boolean"
+)
+
+// ParamSpec declares a parameter which a flow can be started with
+type ParamSpec struct {
+	Key      string    `json:"key" validate:"required"`
+	Type     ParamType `json:"type" validate:"required,param_type"`
+	Required bool      `json:"required,omitempty"`
+}
+
+// NewParamSpec creates a new parameter declaration
+func NewParamSpec(key string, type_ ParamType, required bool) *ParamSpec {
+	return &ParamSpec{Key: key, Type: type_, Required: required}
+}
+
+// ParamSchema is the set of parameters declared by a flow
+type ParamSchema []*ParamSpec
+
+// Get gets the declaration of the parameter with the given key, or nil if there isn't one
+func (s ParamSchema) Get(key string) *ParamSpec {
+	for _, p := range s {
+		if p.Key == key {
+			return p
+		}
+	}
+	return nil
+}
+
+// Coerce checks the given params against this schema and returns them as a map with each value converted to its
+// declared type. It's an error for a required parameter to be missing, or for a parameter to not be declared.
+func (s ParamSchema) Coerce(env utils.Environment, params types.XValue) (types.XMap, error) {
+	values := make(map[string]json.RawMessage)
+
+	if params != nil {
+		marshaled, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(marshaled, &values); err != nil {
+			return nil, errors.New("params must be an object")
+		}
+	}
+
+	// check params in a consistent order so errors are predictable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	coerced := types.NewEmptyXMap()
+
+	for _, key := range keys {
+		spec := s.Get(key)
+		if spec == nil {
+			return nil, errors.Errorf("param '%s' isn't declared by the flow", key)
+		}
+
+		value := types.JSONToXValue(values[key])
+		if value == nil {
+			continue
+		}
+
+		typed, err := coerceParam(env, spec.Type, value)
+		if err != nil {
+			return nil, errors.Errorf("param '%s' must be a %s", key, spec.Type)
+		}
+		coerced.Put(key, typed)
+	}
+
+	// a required param which is null or empty is as good as missing
+	for _, spec := range s {
+		value := coerced.Resolve(env, spec.Key)
+		if spec.Required && (types.IsXError(value) || types.IsEmpty(value)) {
+			return nil, errors.Errorf("missing required param '%s'", spec.Key)
+		}
+	}
+
+	return coerced, nil
+}
+
+// OutputSpec declares a value which a flow returns to its parent when it completes. The value is a template which is
+// evaluated in the context of the completed run.
+type OutputSpec struct {
+	Key   string    `json:"key" validate:"required"`
+	Type  ParamType `json:"type" validate:"required,param_type"`
+	Value string    `json:"value" validate:"required"`
+}
+
+// NewOutputSpec creates a new output declaration
+func NewOutputSpec(key string, type_ ParamType, value string) *OutputSpec {
+	return &OutputSpec{Key: key, Type: type_, Value: value}
+}
+
+// OutputSchema is the set of outputs declared by a flow
+type OutputSchema []*OutputSpec
+
+// Get gets the declaration of the output with the given key, or nil if there isn't one
+func (s OutputSchema) Get(key string) *OutputSpec {
+	for _, o := range s {
+		if o.Key == key {
+			return o
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the value of the output with the given key in the context of the given run, and converts it to
+// its declared type
+func (o *OutputSpec) Evaluate(run FlowRun) (types.XValue, error) {
+	value, err := run.EvaluateTemplateValue(o.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to evaluate output '%s'", o.Key)
+	}
+	if value == nil || types.IsEmpty(value) {
+		return nil, nil
+	}
+
+	typed, err := coerceParam(run.Environment(), o.Type, value)
+	if err != nil {
+		return nil, errors.Errorf("output '%s' must be a %s", o.Key, o.Type)
+	}
+	return typed, nil
+}
+
+// converts the given value to the given parameter type
+func coerceParam(env utils.Environment, type_ ParamType, value types.XValue) (types.XValue, error) {
+	var xerr types.XError
+	var typed types.XValue
+
+	switch type_ {
+	case ParamTypeText:
+		typed, xerr = types.ToXText(env, value)
+	case ParamTypeNumber:
+		typed, xerr = types.ToXNumber(env, value)
+	case ParamTypeDatetime:
+		typed, xerr = types.ToXDateTime(env, value)
+	case ParamTypeBoolean:
+		// only accept actual booleans or their text equivalents rather than anything truthy
+		if boolean, isBoolean := value.(types.XBoolean); isBoolean {
+			return boolean, nil
+		}
+		var text types.XText
+		if text, xerr = types.ToXText(env, value); xerr != nil {
+			return nil, xerr
+		}
+		switch strings.ToLower(text.Native()) {
+		case "true":
+			typed = types.XBooleanTrue
+		case "false":
+			typed = types.XBooleanFalse
+		default:
+			return nil, errors.New("not a boolean")
+		}
+	}
+
+	if xerr != nil {
+		return nil, xerr
+	}
+	return typed, nil
+}
@@ -2,9 +2,11 @@ package resumes
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/triggers"
@@ -66,6 +68,31 @@ func (r *baseResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) erro
 	return nil
 }
 
+// Resolve resolves the given key when this resume is referenced in an expression
+func (r *baseResume) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "type":
+		return types.NewXText(r.type_)
+	case "resumed_on":
+		return types.NewXDateTime(r.resumedOn)
+	}
+
+	return types.NewXResolveError(r, key)
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (r *baseResume) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, r, "type", "resumed_on").ToXJSON(env)
+}
+
+// Describe returns a representation of this type for error messages
+func (r *baseResume) Describe() string { return "resume" }
+
+// Reduce is called when this object needs to be reduced to a primitive
+func (r *baseResume) Reduce(env utils.Environment) types.XPrimitive {
+	return types.NewXText(r.type_)
+}
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
@@ -5,8 +5,11 @@ import (
 
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,4 +29,68 @@ func TestReadResume(t *testing.T) {
 	// error if we don't recognize action type
 	_, err = resumes.ReadResume(sessionAssets, []byte(`{"type": "do_the_foo", "foo": "bar"}`), missing)
 	assert.EqualError(t, err, "unknown type: 'do_the_foo'")
+
+	// modifiers with nothing left to change because of missing assets are dropped from contact changed resumes
+	resume, err := resumes.ReadResume(sessionAssets, []byte(`{
+		"type": "contact_changed",
+		"modifiers": [
+			{"type": "groups", "modification": "add", "groups": [{"uuid": "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "name": "VIP"}]},
+			{"type": "name", "name": "Bob"}
+		],
+		"resumed_on": "2018-10-11T15:00:00Z"
+	}`), missing)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(resume.(*resumes.ContactChangedResume).Modifiers()))
+	assert.Equal(t, assets.NewGroupReference(assets.GroupUUID("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"), "VIP"), missingAssets[len(missingAssets)-1])
+
+	// but they must have at least one modifier
+	_, err = resumes.ReadResume(sessionAssets, []byte(`{"type": "contact_changed", "modifiers": [], "resumed_on": "2018-10-11T15:00:00Z"}`), missing)
+	assert.EqualError(t, err, "field 'modifiers' must have a minimum of 1 items")
+
+	// ticket closed resumes must have a valid ticket
+	resume, err = resumes.ReadResume(sessionAssets, []byte(`{
+		"type": "ticket_closed",
+		"ticket": {
+			"uuid": "78d1fe0d-7e39-461e-81c3-a6a25f15ed69",
+			"ticketer": {"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5", "name": "Support Tickets"},
+			"subject": "Need help",
+			"status": "closed",
+			"opened_on": "2018-10-11T14:00:00Z"
+		},
+		"resumed_on": "2018-10-11T15:00:00Z"
+	}`), missing)
+	require.NoError(t, err)
+	assert.Equal(t, "Need help", resume.(*resumes.TicketClosedResume).Ticket().Subject)
+
+	_, err = resumes.ReadResume(sessionAssets, []byte(`{"type": "ticket_closed", "resumed_on": "2018-10-11T15:00:00Z"}`), missing)
+	assert.EqualError(t, err, "field 'ticket' is required")
+}
+
+func TestTicketClosedResume(t *testing.T) {
+	source, err := static.LoadSource("../../test/testdata/flows/ticket.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("c1a2b3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d")
+	require.NoError(t, err)
+
+	eng := engine.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", nil)
+
+	session := eng.NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+
+	// resume with a copy of the ticket that the contact opened
+	opened := *session.Contact().Tickets().LastOpen()
+	resume := resumes.NewTicketClosedResume(nil, nil, &opened)
+	sprint, err := session.Resume(resume)
+	require.NoError(t, err)
+
+	// the contact's ticket is closed but the resume's own ticket isn't changed
+	assert.Equal(t, flows.TicketStatusClosed, session.Contact().Tickets().Get(opened.UUID).Status)
+	assert.Equal(t, flows.TicketStatusOpen, resume.Ticket().Status)
+	assert.Equal(t, flows.TicketStatusClosed, sprint.Events()[0].(*events.TicketClosedEvent).Ticket.Status)
 }
@@ -0,0 +1,139 @@
+package resumes
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions/modifiers"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeContactChanged, readContactChangedResume)
+}
+
+// TypeContactChanged is the type for resuming a session when the contact has been changed outside of the flow
+const TypeContactChanged string = "contact_changed"
+
+// ContactChangedResume is used when the contact of a waiting session has been changed outside of the flow, e.g. by an
+// operator adding them to a group. The modifiers describing the changes are applied to the contact, and the session
+// keeps waiting unless the wait has `reroute_on_contact_change` set and its node's router now matches one of its cases.
+//
+//   {
+//     "type": "contact_changed",
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z",
+//       "language": "fra",
+//       "fields": {"gender": {"text": "Male"}},
+//       "groups": []
+//     },
+//     "modifiers": [
+//       {
+//         "type": "groups",
+//         "modification": "add",
+//         "groups": [{"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Testers"}]
+//       }
+//     ],
+//     "resumed_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @resume contact_changed
+type ContactChangedResume struct {
+	baseResume
+	modifiers []flows.Modifier
+}
+
+// NewContactChangedResume creates a new contact changed resume with the passed in values
+func NewContactChangedResume(env utils.Environment, contact *flows.Contact, modifiers []flows.Modifier) *ContactChangedResume {
+	return &ContactChangedResume{
+		baseResume: newBaseResume(TypeContactChanged, env, contact),
+		modifiers:  modifiers,
+	}
+}
+
+// Modifiers returns the modifiers describing the changes to the contact
+func (r *ContactChangedResume) Modifiers() []flows.Modifier { return r.modifiers }
+
+// Apply applies our state changes and saves any events to the run
+func (r *ContactChangedResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) error {
+	if err := r.baseResume.Apply(run, logEvent); err != nil {
+		return err
+	}
+
+	contact := run.Session().Contact()
+	if contact == nil {
+		logEvent(events.NewErrorEventf("can't apply contact changes in session without a contact"))
+		return nil
+	}
+
+	for _, mod := range r.modifiers {
+		mod.Apply(run.Session().Environment(), run.Session().Assets(), contact, logEvent)
+	}
+	return nil
+}
+
+var _ flows.Resume = (*ContactChangedResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type contactChangedResumeEnvelope struct {
+	baseResumeEnvelope
+
+	Modifiers []json.RawMessage `json:"modifiers" validate:"required,min=1"`
+}
+
+func readContactChangedResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &contactChangedResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &ContactChangedResume{
+		modifiers: make([]flows.Modifier, 0, len(e.Modifiers)),
+	}
+
+	for _, m := range e.Modifiers {
+		mod, err := modifiers.ReadModifier(sessionAssets, m, missing)
+		if err == modifiers.ErrNoModifier {
+			continue // modifier has nothing left to change without its missing assets
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read modifier")
+		}
+		r.modifiers = append(r.modifiers, mod)
+	}
+
+	if err := r.unmarshal(sessionAssets, &e.baseResumeEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *ContactChangedResume) MarshalJSON() ([]byte, error) {
+	e := &contactChangedResumeEnvelope{
+		Modifiers: make([]json.RawMessage, len(r.modifiers)),
+	}
+
+	var err error
+	for i, mod := range r.modifiers {
+		if e.Modifiers[i], err = json.Marshal(mod); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.marshal(&e.baseResumeEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,120 @@
+package resumes
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	RegisterType(TypeDial, readDialResume)
+}
+
+// TypeDial is the type for resuming a session after a dial
+const TypeDial string = "dial"
+
+// DialResume is used when a session waiting on a dial is resumed with the outcome of that dial
+//
+//   {
+//     "type": "dial",
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z",
+//       "language": "fra",
+//       "fields": {"gender": {"text": "Male"}},
+//       "groups": []
+//     },
+//     "dial": {
+//       "status": "answered",
+//       "duration": 15
+//     },
+//     "resumed_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @resume dial
+type DialResume struct {
+	baseResume
+	dial *flows.Dial
+}
+
+// NewDialResume creates a new dial resume with the passed in values
+func NewDialResume(env utils.Environment, contact *flows.Contact, dial *flows.Dial) *DialResume {
+	return &DialResume{
+		baseResume: newBaseResume(TypeDial, env, contact),
+		dial:       dial,
+	}
+}
+
+// Dial returns the outcome of the dial
+func (r *DialResume) Dial() *flows.Dial { return r.dial }
+
+// Apply applies our state changes and saves any events to the run
+func (r *DialResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) error {
+	// clear the last input
+	run.Session().SetInput(nil)
+	logEvent(events.NewDialEndedEvent(r.dial))
+
+	return r.baseResume.Apply(run, logEvent)
+}
+
+// Resolve resolves the given key when this resume is referenced in an expression
+func (r *DialResume) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "dial":
+		return r.dial
+	}
+
+	return r.baseResume.Resolve(env, key)
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (r *DialResume) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, r, "type", "resumed_on", "dial").ToXJSON(env)
+}
+
+var _ flows.Resume = (*DialResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type dialResumeEnvelope struct {
+	baseResumeEnvelope
+	Dial *flows.Dial `json:"dial" validate:"required"`
+}
+
+func readDialResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &dialResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &DialResume{
+		dial: e.Dial,
+	}
+
+	if err := r.unmarshal(sessionAssets, &e.baseResumeEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *DialResume) MarshalJSON() ([]byte, error) {
+	e := &dialResumeEnvelope{
+		Dial: r.dial,
+	}
+
+	if err := r.marshal(&e.baseResumeEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,157 @@
+package resumes
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	RegisterType(TypeExternalEvent, readExternalEventResume)
+}
+
+// TypeExternalEvent is the type for resuming a session with an event from a third party
+const TypeExternalEvent string = "external_event"
+
+// ExternalEventResume is used when a session waiting on an external event is resumed by that event arriving. The event
+// name and correlation key must match those of the wait. If the wait has a result name, the payload is saved as the extra
+// of a result with that name, with the key as its value.
+//
+//   {
+//     "type": "external_event",
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z",
+//       "language": "fra",
+//       "fields": {"gender": {"text": "Male"}},
+//       "groups": []
+//     },
+//     "event": "payment_confirmed",
+//     "key": "ORD-1234",
+//     "payload": {"status": "paid", "amount": 25.50},
+//     "resumed_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @resume external_event
+type ExternalEventResume struct {
+	baseResume
+	event   string
+	key     string
+	payload json.RawMessage
+}
+
+// NewExternalEventResume creates a new external event resume with the passed in values
+func NewExternalEventResume(env utils.Environment, contact *flows.Contact, event string, key string, payload json.RawMessage) *ExternalEventResume {
+	return &ExternalEventResume{
+		baseResume: newBaseResume(TypeExternalEvent, env, contact),
+		event:      event,
+		key:        key,
+		payload:    payload,
+	}
+}
+
+// Event returns the name of the event
+func (r *ExternalEventResume) Event() string { return r.event }
+
+// Key returns the correlation key of the event
+func (r *ExternalEventResume) Key() string { return r.key }
+
+// Payload returns the JSON payload of the event
+func (r *ExternalEventResume) Payload() json.RawMessage { return r.payload }
+
+// Apply applies our state changes and saves any events to the run
+func (r *ExternalEventResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) error {
+	if err := r.baseResume.Apply(run, logEvent); err != nil {
+		return err
+	}
+
+	// clear the last input
+	run.Session().SetInput(nil)
+	logEvent(events.NewExternalEventReceivedEvent(r.event, r.key, r.payload))
+
+	// save the event as a result if the wait at the node we were waiting at names one
+	step, node, err := run.PathLocation()
+	if err != nil {
+		return err
+	}
+
+	wait, hasResultName := node.Wait().(interface{ ResultName() string })
+	if hasResultName && wait.ResultName() != "" {
+		result := flows.NewResult(wait.ResultName(), r.key, "", "", step.NodeUUID(), nil, r.payload, run.Session().Now())
+		run.SaveResult(result)
+		logEvent(events.NewRunResultChangedEvent(result))
+	}
+	return nil
+}
+
+// Resolve resolves the given key when this resume is referenced in an expression
+func (r *ExternalEventResume) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "event":
+		return types.NewXText(r.event)
+	case "key":
+		return types.NewXText(r.key)
+	case "payload":
+		return types.JSONToXValue(r.payload)
+	}
+
+	return r.baseResume.Resolve(env, key)
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (r *ExternalEventResume) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, r, "type", "resumed_on", "event", "key", "payload").ToXJSON(env)
+}
+
+var _ flows.Resume = (*ExternalEventResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type externalEventResumeEnvelope struct {
+	baseResumeEnvelope
+	Event   string          `json:"event" validate:"required"`
+	Key     string          `json:"key" validate:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func readExternalEventResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &externalEventResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &ExternalEventResume{
+		event:   e.Event,
+		key:     e.Key,
+		payload: e.Payload,
+	}
+
+	if err := r.unmarshal(sessionAssets, &e.baseResumeEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *ExternalEventResume) MarshalJSON() ([]byte, error) {
+	e := &externalEventResumeEnvelope{
+		Event:   r.event,
+		Key:     r.key,
+		Payload: r.payload,
+	}
+
+	if err := r.marshal(&e.baseResumeEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
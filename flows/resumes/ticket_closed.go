@@ -0,0 +1,139 @@
+package resumes
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	RegisterType(TypeTicketClosed, readTicketClosedResume)
+}
+
+// TypeTicketClosed is the type for resuming a session when a ticket has been closed by an agent
+const TypeTicketClosed string = "ticket_closed"
+
+// TicketClosedResume is used when a session waiting on a ticket is resumed because an agent closed that ticket. The
+// contact's ticket is marked as closed and can be accessed as `@resume.ticket`.
+//
+//   {
+//     "type": "ticket_closed",
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z",
+//       "language": "fra",
+//       "fields": {"gender": {"text": "Male"}},
+//       "groups": []
+//     },
+//     "ticket": {
+//       "uuid": "78d1fe0d-7e39-461e-81c3-a6a25f15ed69",
+//       "ticketer": {"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5", "name": "Support Tickets"},
+//       "subject": "Need help",
+//       "body": "Where are my cookies?",
+//       "status": "closed",
+//       "opened_on": "2000-01-01T00:00:00.000000000-00:00"
+//     },
+//     "resumed_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @resume ticket_closed
+type TicketClosedResume struct {
+	baseResume
+	ticket *flows.Ticket
+}
+
+// NewTicketClosedResume creates a new ticket closed resume with the passed in values
+func NewTicketClosedResume(env utils.Environment, contact *flows.Contact, ticket *flows.Ticket) *TicketClosedResume {
+	return &TicketClosedResume{
+		baseResume: newBaseResume(TypeTicketClosed, env, contact),
+		ticket:     ticket,
+	}
+}
+
+// Ticket returns the ticket that was closed
+func (r *TicketClosedResume) Ticket() *flows.Ticket { return r.ticket }
+
+// Apply applies our state changes and saves any events to the run
+func (r *TicketClosedResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) error {
+	if err := r.baseResume.Apply(run, logEvent); err != nil {
+		return err
+	}
+
+	// clear the last input
+	run.Session().SetInput(nil)
+
+	// close the contact's own record of the ticket, or a copy of ours if they don't have one
+	closed := *r.ticket
+	ticket := &closed
+	if contact := run.Session().Contact(); contact != nil {
+		if contactTicket := contact.Tickets().Get(r.ticket.UUID); contactTicket != nil {
+			ticket = contactTicket
+		}
+	}
+	ticket.Status = flows.TicketStatusClosed
+
+	logEvent(events.NewTicketClosedEvent(ticket))
+	return nil
+}
+
+// Resolve resolves the given key when this resume is referenced in an expression
+func (r *TicketClosedResume) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "ticket":
+		return r.ticket
+	}
+
+	return r.baseResume.Resolve(env, key)
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (r *TicketClosedResume) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, r, "type", "resumed_on", "ticket").ToXJSON(env)
+}
+
+var _ flows.Resume = (*TicketClosedResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type ticketClosedResumeEnvelope struct {
+	baseResumeEnvelope
+	Ticket *flows.Ticket `json:"ticket" validate:"required,dive"`
+}
+
+func readTicketClosedResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &ticketClosedResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &TicketClosedResume{
+		ticket: e.Ticket,
+	}
+
+	if err := r.unmarshal(sessionAssets, &e.baseResumeEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *TicketClosedResume) MarshalJSON() ([]byte, error) {
+	e := &ticketClosedResumeEnvelope{
+		Ticket: r.ticket,
+	}
+
+	if err := r.marshal(&e.baseResumeEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,21 @@
+package flows
+
+// RetentionPolicy controls how much history is kept in a session between sprints, as sessions which loop through flows
+// for a long time can otherwise grow without limit. History is only pruned from previous sprints once a sprint has
+// succeeded, so the session still contains everything generated by the last sprint, and a session which errors keeps
+// all of its history. The zero value keeps everything.
+type RetentionPolicy struct {
+	// MaxStepsPerRun is the number of steps from previous sprints kept in the path of each run, though the most recent step for each
+	// different node exit is always kept so that routers like random_once continue to work. Zero means no limit.
+	MaxStepsPerRun int
+
+	// DropSprintEvents drops run events generated in previous sprints, as callers will already have received them
+	DropSprintEvents bool
+
+	// SummarizeCompletedRuns replaces runs which exited in previous sprints with summaries of them, i.e. their UUID,
+	// flow, contact, status and results, dropping their paths and events. The summary of the most recent child of each
+	// remaining run is kept so it can still be referenced as @child, and summaries of other runs are dropped. Runs which
+	// are the ancestors of remaining runs are kept so they can still be referenced as @parent, but without their events
+	// and with their paths pruned to the most recent step for each node exit.
+	SummarizeCompletedRuns bool
+}
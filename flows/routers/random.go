@@ -36,7 +36,7 @@ func (r *RandomRouter) PickRoute(run flows.FlowRun, exits []flows.Exit, step flo
 	}
 
 	// pick a random exit
-	rand := utils.RandDecimal()
+	rand := utils.RandDecimalFrom(run.Session().Rand())
 	exitNum := rand.Mul(decimal.New(int64(len(exits)), 0)).IntPart()
 	return nil, flows.NewRoute(exits[exitNum].UUID(), rand.String(), nil), nil
 }
@@ -76,7 +76,7 @@ func (r *RandomOnceRouter) PickRoute(run flows.FlowRun, exits []flows.Exit, step
 	}
 
 	// ok, now pick one randomly
-	rand := utils.RandDecimal()
+	rand := utils.RandDecimalFrom(run.Session().Rand())
 	exitNum := rand.Mul(decimal.New(int64(len(validExits)), 0)).IntPart()
 	return nil, flows.NewRoute(validExits[exitNum], rand.String(), nil), nil
 }
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -41,7 +42,10 @@ var XTESTS = map[string]functions.XFunction{
 	"has_value": functions.OneArgFunction(HasValue),
 
 	"has_group":          functions.TwoArgFunction(HasGroup),
+	"has_intent":         functions.ThreeArgFunction(HasIntent),
+	"has_top_intent":     functions.ThreeArgFunction(HasTopIntent),
 	"has_wait_timed_out": functions.OneArgFunction(HasWaitTimedOut),
+	"has_dial_status":    functions.TwoArgFunction(HasDialStatus),
 
 	"is_text_eq":      functions.TwoTextFunction(IsTextEQ),
 	"has_phrase":      functions.TwoTextFunction(HasPhrase),
@@ -170,6 +174,49 @@ func HasWaitTimedOut(env utils.Environment, value types.XValue) types.XValue {
 	return XFalseResult
 }
 
+// HasDialStatus returns whether the last dial in the `run` ended with one of the given `statuses`, which are
+// separated by spaces, e.g. "busy no_answer". It will return the status as the match.
+//
+//   @(has_dial_status(run, "answered")) -> false
+//   @(has_dial_status(contact, "answered")) -> ERROR
+//
+// @test has_dial_status(run, statuses)
+func HasDialStatus(env utils.Environment, arg1 types.XValue, arg2 types.XValue) types.XValue {
+	// first parameter needs to be a flow run
+	run, isRun := arg1.(flows.FlowRun)
+	if !isRun {
+		return types.NewXErrorf("must be called with a run as first argument")
+	}
+
+	statuses, xerr := types.ToXText(env, arg2)
+	if xerr != nil {
+		return xerr
+	}
+
+	// look for the last dial, stopping if we find a message which came after it
+	runEvents := run.Events()
+	for e := len(runEvents) - 1; e >= 0; e-- {
+		event := runEvents[e]
+
+		dialEnded, isDial := event.(*events.DialEndedEvent)
+		if isDial {
+			for _, status := range strings.Fields(strings.ToLower(statuses.Native())) {
+				if flows.DialStatus(status) == dialEnded.Dial.Status {
+					return NewTrueResult(types.NewXText(string(dialEnded.Dial.Status)))
+				}
+			}
+			break
+		}
+
+		_, isInput := event.(*events.MsgReceivedEvent)
+		if isInput {
+			break
+		}
+	}
+
+	return XFalseResult
+}
+
 // HasGroup returns whether the `contact` is part of group with the passed in UUID
 //
 //   @(has_group(contact, "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")) -> true
@@ -198,6 +245,75 @@ func HasGroup(env utils.Environment, arg1 types.XValue, arg2 types.XValue) types
 	return XFalseResult
 }
 
+// HasIntent tests whether any intent in a classification `result` has `name` with minimum `confidence`. The extracted
+// entities are returned as the extra of the match.
+//
+//   @(has_intent(results.intent, "book_flight", 0.5)) -> true
+//   @(has_intent(results.intent, "book_hotel", 0.2)) -> true
+//   @(has_intent(results.intent, "book_hotel", 0.5)) -> false
+//   @(has_intent(results.intent, "book_hotel", 0.2).match) -> book_hotel
+//   @(has_intent(results.favorite_color, "book_flight", 0.5)) -> false
+//   @(has_intent("foo", "book_flight", 0.5)) -> ERROR
+//
+// @test has_intent(result, name, confidence)
+func HasIntent(env utils.Environment, result types.XValue, name types.XValue, confidence types.XValue) types.XValue {
+	return hasIntent(env, result, name, confidence, false)
+}
+
+// HasTopIntent tests whether the top intent in a classification `result` has `name` with minimum `confidence`. The
+// extracted entities are returned as the extra of the match.
+//
+//   @(has_top_intent(results.intent, "book_flight", 0.5)) -> true
+//   @(has_top_intent(results.intent, "book_flight", 0.95)) -> false
+//   @(has_top_intent(results.intent, "book_hotel", 0.2)) -> false
+//   @(has_top_intent(results.intent, "book_flight", 0.5).match) -> book_flight
+//
+// @test has_top_intent(result, name, confidence)
+func HasTopIntent(env utils.Environment, result types.XValue, name types.XValue, confidence types.XValue) types.XValue {
+	return hasIntent(env, result, name, confidence, true)
+}
+
+func hasIntent(env utils.Environment, arg1 types.XValue, arg2 types.XValue, arg3 types.XValue, topOnly bool) types.XValue {
+	// is the first argument a result?
+	result, isResult := arg1.(*flows.Result)
+	if !isResult {
+		return types.NewXErrorf("must have a result as its first argument")
+	}
+
+	name, xerr := types.ToXText(env, arg2)
+	if xerr != nil {
+		return xerr
+	}
+	confidence, xerr := types.ToXNumber(env, arg3)
+	if xerr != nil {
+		return xerr
+	}
+
+	// results which aren't from a classifier won't have a classification in their extra
+	classification := &flows.Classification{}
+	if err := json.Unmarshal(result.Extra, classification); err != nil {
+		return XFalseResult
+	}
+
+	// intents aren't necessarily ordered by confidence so the top one has to be found
+	intents := classification.Intents
+	if topOnly {
+		intents = nil
+		if top := classification.TopIntent(); top != nil {
+			intents = []flows.ExtractedIntent{*top}
+		}
+	}
+
+	for _, intent := range intents {
+		if intent.Name == name.Native() && intent.Confidence.GreaterThanOrEqual(confidence.Native()) {
+			// include the top value of each entity as the extra of the match
+			return NewTrueResultWithExtra(types.NewXText(intent.Name), classification.TopEntities())
+		}
+	}
+
+	return XFalseResult
+}
+
 // HasPhrase tests whether `phrase` is contained in `text`
 //
 // The words in the test phrase must appear in the same order with no other words
@@ -60,6 +60,9 @@ var testTests = []struct {
 	{"is_error", []types.XValue{types.NewXErrorf("I am error")}, true, types.NewXErrorf("I am error"), false},
 	{"is_error", []types.XValue{}, false, nil, true},
 
+	{"has_dial_status", []types.XValue{xs("hello"), xs("answered")}, false, nil, true},
+	{"has_dial_status", []types.XValue{xs("hello")}, false, nil, true},
+
 	{"has_text", []types.XValue{xs("hello")}, true, xs("hello"), false},
 	{"has_text", []types.XValue{xs("  ")}, false, nil, false},
 	{"has_text", []types.XValue{nil}, false, nil, false},
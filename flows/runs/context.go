@@ -43,6 +43,8 @@ func (c *runContext) Resolve(env utils.Environment, key string) types.XValue {
 		return c.run.Session().Trigger()
 	case "input":
 		return c.run.Session().Input()
+	case "resume":
+		return c.run.Session().CurrentResume()
 	case "legacy_extra":
 		c.extra.update()
 		return c.extra
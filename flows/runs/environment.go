@@ -1,6 +1,7 @@
 package runs
 
 import (
+	"math/rand"
 	"regexp"
 	"strings"
 	"time"
@@ -33,6 +34,16 @@ func (e *runEnvironment) Timezone() *time.Location {
 	return e.run.Session().Environment().Timezone()
 }
 
+// Now returns the current time according to the session, in the environment timezone
+func (e *runEnvironment) Now() time.Time {
+	return e.run.Session().Now().In(e.Environment.Timezone())
+}
+
+// Rand returns the random number generator of the session
+func (e *runEnvironment) Rand() *rand.Rand {
+	return e.run.Session().Rand()
+}
+
 func (e *runEnvironment) Locations() (assets.LocationHierarchy, error) {
 	sessionAssets := e.run.Session().Assets()
 	hierarchies := sessionAssets.Locations().Hierarchies()
@@ -24,6 +24,8 @@ type flowRun struct {
 
 	context types.XValue
 	parent  flows.FlowRun
+	params  types.XValue
+	returns map[string]string
 
 	results flows.Results
 	path    Path
@@ -36,14 +38,17 @@ type flowRun struct {
 	exitedOn   *time.Time
 }
 
-// NewRun initializes a new context and flow run for the passed in flow and contact
-func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun) flows.FlowRun {
-	now := utils.Now()
+// NewRun initializes a new context and flow run for the passed in flow and contact. The params and returns are only
+// set for runs entered from a parent run.
+func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun, params types.XValue, returns map[string]string) flows.FlowRun {
+	now := session.Now()
 	r := &flowRun{
 		uuid:       flows.RunUUID(utils.NewUUID()),
 		session:    session,
 		flow:       flow,
 		parent:     parent,
+		params:     params,
+		returns:    returns,
 		results:    flows.NewResults(),
 		status:     flows.RunStatusActive,
 		events:     make([]flows.Event, 0),
@@ -66,9 +71,15 @@ func (r *flowRun) Environment() flows.RunEnvironment { return r.environment }
 func (r *flowRun) Flow() flows.Flow        { return r.flow }
 func (r *flowRun) Contact() *flows.Contact { return r.session.Contact() }
 func (r *flowRun) Context() types.XValue   { return r.context }
+func (r *flowRun) Params() types.XValue    { return r.params }
 func (r *flowRun) Events() []flows.Event   { return r.events }
 
 func (r *flowRun) Results() flows.Results { return r.results }
+
+// Returns returns the mapping of the outputs of this run's flow to the names of the results they should be saved as
+// on the parent run
+func (r *flowRun) Returns() map[string]string { return r.returns }
+
 func (r *flowRun) SaveResult(result *flows.Result) {
 	// truncate value if necessary
 	if len(result.Value) > r.Environment().MaxValueLength() {
@@ -76,11 +87,11 @@ func (r *flowRun) SaveResult(result *flows.Result) {
 	}
 
 	r.results.Save(result)
-	r.modifiedOn = utils.Now()
+	r.modifiedOn = r.session.Now()
 }
 
 func (r *flowRun) Exit(status flows.RunStatus) {
-	now := utils.Now()
+	now := r.session.Now()
 
 	r.status = status
 	r.exitedOn = &now
@@ -89,7 +100,7 @@ func (r *flowRun) Exit(status flows.RunStatus) {
 func (r *flowRun) Status() flows.RunStatus { return r.status }
 func (r *flowRun) SetStatus(status flows.RunStatus) {
 	r.status = status
-	r.modifiedOn = utils.Now()
+	r.modifiedOn = r.session.Now()
 }
 
 // ParentInSession returns the parent of the run within the same session if one exists
@@ -129,7 +140,7 @@ func (r *flowRun) LogEvent(s flows.Step, event flows.Event) {
 	}
 
 	r.events = append(r.events, event)
-	r.modifiedOn = utils.Now()
+	r.modifiedOn = r.session.Now()
 }
 
 func (r *flowRun) LogError(step flows.Step, err error) {
@@ -138,13 +149,59 @@ func (r *flowRun) LogError(step flows.Step, err error) {
 
 func (r *flowRun) Path() []flows.Step { return r.path }
 func (r *flowRun) CreateStep(node flows.Node) flows.Step {
-	now := utils.Now()
+	now := r.session.Now()
 	step := NewStep(node, now)
 	r.path = append(r.path, step)
 	r.modifiedOn = now
 	return step
 }
 
+// PrunePath removes older steps from the path of this run, keeping only the given number of most recent steps, as well
+// as the most recent step for each different node exit, which routers like random_once rely on
+func (r *flowRun) PrunePath(keep int) {
+	if len(r.path) <= keep {
+		return
+	}
+
+	type nodeExit struct {
+		node flows.NodeUUID
+		exit flows.ExitUUID
+	}
+
+	// work backwards through the path deciding which steps we need to keep
+	seen := make(map[nodeExit]bool)
+	keeping := make([]bool, len(r.path))
+	numKept := 0
+
+	for i := len(r.path) - 1; i >= 0; i-- {
+		key := nodeExit{r.path[i].NodeUUID(), r.path[i].ExitUUID()}
+
+		if i >= len(r.path)-keep || !seen[key] {
+			keeping[i] = true
+			numKept++
+		}
+		seen[key] = true
+	}
+
+	pruned := make(Path, 0, numKept)
+	for i, s := range r.path {
+		if keeping[i] {
+			pruned = append(pruned, s)
+		}
+	}
+	r.path = pruned
+}
+
+// PruneEvents removes older events from this run, keeping only the given number of most recent events
+func (r *flowRun) PruneEvents(keep int) {
+	if len(r.events) <= keep {
+		return
+	}
+	pruned := make([]flows.Event, keep)
+	copy(pruned, r.events[len(r.events)-keep:])
+	r.events = pruned
+}
+
 func (r *flowRun) PathLocation() (flows.Step, flows.Node, error) {
 	if r.Path() == nil {
 		return nil, nil, errors.Errorf("run has no location as path is empty")
@@ -167,7 +224,7 @@ func (r *flowRun) ExpiresOn() *time.Time { return r.expiresOn }
 func (r *flowRun) ResetExpiration(from *time.Time) {
 	if r.Flow().ExpireAfterMinutes() >= 0 {
 		if from == nil {
-			now := utils.Now()
+			now := r.session.Now()
 			from = &now
 		}
 
@@ -175,7 +232,7 @@ func (r *flowRun) ResetExpiration(from *time.Time) {
 		expiresOn := from.Add(expiresAfterMinutes * time.Minute)
 
 		r.expiresOn = &expiresOn
-		r.modifiedOn = utils.Now()
+		r.modifiedOn = r.session.Now()
 	}
 
 	if r.ParentInSession() != nil {
@@ -278,6 +335,8 @@ func (r *flowRun) Resolve(env utils.Environment, key string) types.XValue {
 		return r.Results()
 	case "path":
 		return r.path
+	case "params":
+		return r.params
 	case "created_on":
 		return types.NewXDateTime(r.CreatedOn())
 	case "exited_on":
@@ -321,6 +380,8 @@ type runEnvelope struct {
 	Results    flows.Results         `json:"results,omitempty" validate:"omitempty,dive"`
 	Status     flows.RunStatus       `json:"status" validate:"required"`
 	ParentUUID flows.RunUUID         `json:"parent_uuid,omitempty" validate:"omitempty,uuid4"`
+	Params     json.RawMessage       `json:"params,omitempty"`
+	Returns    map[string]string     `json:"returns,omitempty"`
 
 	CreatedOn  time.Time  `json:"created_on" validate:"required"`
 	ModifiedOn time.Time  `json:"modified_on" validate:"required"`
@@ -346,6 +407,11 @@ func ReadRun(session flows.Session, data json.RawMessage) (flows.FlowRun, error)
 		modifiedOn: e.ModifiedOn,
 		expiresOn:  e.ExpiresOn,
 		exitedOn:   e.ExitedOn,
+		returns:    e.Returns,
+	}
+
+	if e.Params != nil {
+		r.params = types.JSONToXValue(e.Params)
 	}
 
 	// lookup flow
@@ -400,6 +466,13 @@ func (r *flowRun) MarshalJSON() ([]byte, error) {
 		ExpiresOn:  r.expiresOn,
 		ExitedOn:   r.exitedOn,
 		Results:    r.results,
+		Returns:    r.returns,
+	}
+
+	if r.params != nil {
+		if e.Params, err = json.Marshal(r.params); err != nil {
+			return nil, err
+		}
 	}
 
 	if r.parent != nil {
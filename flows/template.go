@@ -0,0 +1,96 @@
+package flows
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/utils"
+)
+
+// Template represents a message template, e.g. a pre-approved WhatsApp template
+type Template struct {
+	assets.Template
+}
+
+// NewTemplate returns a new template object from the given template asset
+func NewTemplate(asset assets.Template) *Template {
+	return &Template{Template: asset}
+}
+
+// Asset returns the underlying asset
+func (t *Template) Asset() assets.Template { return t.Template }
+
+// Reference returns a reference to this template
+func (t *Template) Reference() *assets.TemplateReference {
+	if t == nil {
+		return nil
+	}
+	return assets.NewTemplateReference(t.UUID(), t.Name())
+}
+
+// FindTranslation finds the translation of this template for the given channel, trying each of the given languages in
+// order, and returns nil if there isn't one
+func (t *Template) FindTranslation(channel assets.ChannelUUID, languages []utils.Language) *TemplateTranslation {
+	for _, lang := range languages {
+		for _, tr := range t.Translations() {
+			if tr.Channel().UUID == channel && tr.Language() == lang {
+				return NewTemplateTranslation(tr)
+			}
+		}
+	}
+	return nil
+}
+
+// TemplateTranslation represents a single translation of a template
+type TemplateTranslation struct {
+	assets.TemplateTranslation
+}
+
+// NewTemplateTranslation returns a new template translation from the given asset
+func NewTemplateTranslation(asset assets.TemplateTranslation) *TemplateTranslation {
+	return &TemplateTranslation{TemplateTranslation: asset}
+}
+
+// Asset returns the underlying asset
+func (t *TemplateTranslation) Asset() assets.TemplateTranslation { return t.TemplateTranslation }
+
+// Substitute replaces the positional variable placeholders in the content of this translation, i.e. `{{1}}`, `{{2}}`..
+// with the given variable values
+func (t *TemplateTranslation) Substitute(vars []string) string {
+	s := t.Content()
+	for i, v := range vars {
+		s = strings.Replace(s, fmt.Sprintf("{{%d}}", i+1), v, -1)
+	}
+	return s
+}
+
+// TemplateAssets provides access to all template assets
+type TemplateAssets struct {
+	all    []*Template
+	byUUID map[assets.TemplateUUID]*Template
+}
+
+// NewTemplateAssets creates a new set of template assets
+func NewTemplateAssets(templates []assets.Template) *TemplateAssets {
+	s := &TemplateAssets{
+		all:    make([]*Template, len(templates)),
+		byUUID: make(map[assets.TemplateUUID]*Template, len(templates)),
+	}
+	for i, asset := range templates {
+		template := NewTemplate(asset)
+		s.all[i] = template
+		s.byUUID[template.UUID()] = template
+	}
+	return s
+}
+
+// All returns all the templates
+func (s *TemplateAssets) All() []*Template {
+	return s.all
+}
+
+// Get returns the template with the given UUID
+func (s *TemplateAssets) Get(uuid assets.TemplateUUID) *Template {
+	return s.byUUID[uuid]
+}
@@ -0,0 +1,42 @@
+package flows_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	whatsapp := assets.NewChannelReference("0bce5fd3-c215-45a0-bcb8-2386eb194175", "WhatsApp")
+	other := assets.NewChannelReference("c6f2c4a0-b5f8-4dca-8b58-2f0a5a7c4b2e", "Other WhatsApp")
+
+	template := flows.NewTemplate(types.NewTemplate(
+		assets.TemplateUUID("4eed8d2b-b9e7-4d43-ae17-9ef0e6ed3a75"),
+		"order_shipped",
+		[]*types.TemplateTranslation{
+			types.NewTemplateTranslation(utils.Language("eng"), whatsapp, "Hi {{1}}, your order {{2}} has shipped", 2),
+			types.NewTemplateTranslation(utils.Language("spa"), whatsapp, "Hola {{1}}, tu pedido {{2}} ha sido enviado", 2),
+		},
+	))
+
+	assert.Equal(t, assets.NewTemplateReference("4eed8d2b-b9e7-4d43-ae17-9ef0e6ed3a75", "order_shipped"), template.Reference())
+	assert.Nil(t, (*flows.Template)(nil).Reference())
+
+	// first language with a translation for the channel wins
+	tr := template.FindTranslation(whatsapp.UUID, []utils.Language{"fra", "spa", "eng"})
+	assert.Equal(t, utils.Language("spa"), tr.Language())
+	assert.Equal(t, "Hola Bob, tu pedido ORD-1234 ha sido enviado", tr.Substitute([]string{"Bob", "ORD-1234"}))
+
+	// no translation for this channel or these languages
+	assert.Nil(t, template.FindTranslation(other.UUID, []utils.Language{"eng"}))
+	assert.Nil(t, template.FindTranslation(whatsapp.UUID, []utils.Language{"fra"}))
+
+	templates := flows.NewTemplateAssets([]assets.Template{template.Asset()})
+	assert.Equal(t, template, templates.Get(assets.TemplateUUID("4eed8d2b-b9e7-4d43-ae17-9ef0e6ed3a75")))
+	assert.Nil(t, templates.Get(assets.TemplateUUID("e95c6a1e-5b0c-4a8d-9b8c-7d5bd5a3f1a2")))
+}
@@ -16,6 +16,7 @@ var RunContextTopLevels = []string{
 	"input",
 	"results",
 	"trigger",
+	"resume",
 	"legacy_extra",
 }
 
@@ -37,6 +38,28 @@ func ExtractFieldReferences(template string) []*assets.FieldReference {
 	return fieldRefs
 }
 
+// ExtractTriggerParamReferences extracts the keys of trigger params referenced in the given template
+func ExtractTriggerParamReferences(template string) []string {
+	return extractParamReferences(template, "trigger")
+}
+
+// ExtractRunParamReferences extracts the keys of run params referenced in the given template
+func ExtractRunParamReferences(template string) []string {
+	return extractParamReferences(template, "run")
+}
+
+// extracts the keys of params referenced as @<topLevel>.params.<key> in the given template. Unlike the rest of the
+// path, keys keep their case because params are looked up case-sensitively.
+func extractParamReferences(template string, topLevel string) []string {
+	keys := make([]string, 0)
+	tools.FindContextRefsInTemplate(template, RunContextTopLevels, func(path []string) {
+		if len(path) >= 3 && strings.ToLower(path[0]) == topLevel && strings.ToLower(path[1]) == "params" {
+			keys = append(keys, path[2])
+		}
+	})
+	return keys
+}
+
 // checks whether the given context path is a reference to a contact field
 func isFieldRefPath(path []string) (bool, string) {
 	for _, possible := range fieldRefPaths {
@@ -33,3 +33,24 @@ func TestExtractFieldReferences(t *testing.T) {
 		assert.Equal(t, tc.refs, actual, "field refs mismatch for template '%s'", tc.template)
 	}
 }
+
+func TestExtractParamReferences(t *testing.T) {
+	testCases := []struct {
+		template    string
+		triggerKeys []string
+		runKeys     []string
+	}{
+		{``, []string{}, []string{}},
+		{`Hi @contact`, []string{}, []string{}},
+		{`Your order @trigger.params.order_id`, []string{"order_id"}, []string{}},
+		{`Your order @RUN.PARAMS.order_id for @(run.params.amount * 2)`, []string{}, []string{"order_id", "amount"}},
+		{`Your order @trigger.params.orderId for @(TRIGGER.PARAMS.totalAmount * 2)`, []string{"orderId", "totalAmount"}, []string{}},
+		{`Hi @run.params.name, your order is @trigger.params.order_id`, []string{"order_id"}, []string{"name"}},
+		{`@trigger.params`, []string{}, []string{}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.triggerKeys, flows.ExtractTriggerParamReferences(tc.template), "trigger param refs mismatch for template '%s'", tc.template)
+		assert.Equal(t, tc.runKeys, flows.ExtractRunParamReferences(tc.template), "run param refs mismatch for template '%s'", tc.template)
+	}
+}
@@ -0,0 +1,159 @@
+package flows
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+)
+
+// TicketUUID is the UUID of a ticket
+type TicketUUID utils.UUID
+
+// TicketStatus is whether a ticket is still open or has been closed by an agent
+type TicketStatus string
+
+// the possible ticket statuses
+const (
+	TicketStatusOpen   TicketStatus = "open"
+	TicketStatusClosed TicketStatus = "closed"
+)
+
+// Ticket is a request for a human agent to help a contact, opened with a [ticketer](#asset:ticketer). It renders as
+// its subject in a template, and has the following properties which can be accessed:
+//
+//  * `uuid` the UUID of the ticket
+//  * `ticketer` the name of the ticketer the ticket was opened with
+//  * `subject` the subject of the ticket
+//  * `body` the body of the ticket
+//  * `topic` the topic of the ticket
+//  * `status` the status of the ticket, either `open` or `closed`
+//
+// Examples:
+//
+//   @contact.tickets -> Need help
+//   @(contact.tickets[0].uuid) -> 78d1fe0d-7e39-461e-81c3-a6a25f15ed69
+//   @(contact.tickets[0].topic) -> Orders
+//   @(json(contact.tickets[0])) -> {"body":"Where are my cookies?","status":"open","subject":"Need help","ticketer":"Support Tickets","topic":"Orders","uuid":"78d1fe0d-7e39-461e-81c3-a6a25f15ed69"}
+//
+// @context ticket
+type Ticket struct {
+	UUID     TicketUUID                `json:"uuid" validate:"required,uuid4"`
+	Ticketer *assets.TicketerReference `json:"ticketer" validate:"required,dive"`
+	Subject  string                    `json:"subject"`
+	Body     string                    `json:"body,omitempty"`
+	Topic    string                    `json:"topic,omitempty"`
+	Status   TicketStatus              `json:"status" validate:"required,eq=open|eq=closed"`
+	OpenedOn time.Time                 `json:"opened_on" validate:"required"`
+}
+
+// NewTicket creates a new open ticket
+func NewTicket(ticketer *assets.TicketerReference, subject string, body string, topic string, openedOn time.Time) *Ticket {
+	return &Ticket{
+		UUID:     TicketUUID(utils.NewUUID()),
+		Ticketer: ticketer,
+		Subject:  subject,
+		Body:     body,
+		Topic:    topic,
+		Status:   TicketStatusOpen,
+		OpenedOn: openedOn,
+	}
+}
+
+// Resolve resolves the given key when this ticket is referenced in an expression
+func (t *Ticket) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "uuid":
+		return types.NewXText(string(t.UUID))
+	case "ticketer":
+		return types.NewXText(t.Ticketer.Name)
+	case "subject":
+		return types.NewXText(t.Subject)
+	case "body":
+		return types.NewXText(t.Body)
+	case "topic":
+		return types.NewXText(t.Topic)
+	case "status":
+		return types.NewXText(string(t.Status))
+	}
+
+	return types.NewXResolveError(t, key)
+}
+
+// Describe returns a representation of this type for error messages
+func (t *Ticket) Describe() string { return "ticket" }
+
+// Reduce is called when this object needs to be reduced to a primitive
+func (t *Ticket) Reduce(env utils.Environment) types.XPrimitive { return types.NewXText(t.Subject) }
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (t *Ticket) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, t, "uuid", "ticketer", "subject", "body", "topic", "status").ToXJSON(env)
+}
+
+var _ types.XValue = (*Ticket)(nil)
+var _ types.XResolvable = (*Ticket)(nil)
+
+// TicketList is the tickets opened for a contact, in the order they were opened
+type TicketList []*Ticket
+
+// Get gets the ticket with the given UUID, or nil if there isn't one
+func (l TicketList) Get(uuid TicketUUID) *Ticket {
+	for _, t := range l {
+		if t.UUID == uuid {
+			return t
+		}
+	}
+	return nil
+}
+
+// LastOpen gets the most recently opened ticket which is still open, or nil if there isn't one
+func (l TicketList) LastOpen() *Ticket {
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].Status == TicketStatusOpen {
+			return l[i]
+		}
+	}
+	return nil
+}
+
+func (l TicketList) clone() TicketList {
+	if l == nil {
+		return nil
+	}
+
+	clone := make(TicketList, len(l))
+	for i, t := range l {
+		c := *t
+		clone[i] = &c
+	}
+	return clone
+}
+
+// Index is called when this object is indexed into in an expression
+func (l TicketList) Index(index int) types.XValue { return l[index] }
+
+// Length is called when the length of this object is requested in an expression
+func (l TicketList) Length() int { return len(l) }
+
+// Describe returns a representation of this type for error messages
+func (l TicketList) Describe() string { return "tickets" }
+
+// Reduce is called when this object needs to be reduced to a primitive
+func (l TicketList) Reduce(env utils.Environment) types.XPrimitive {
+	array := types.NewXArray()
+	for _, ticket := range l {
+		array.Append(ticket)
+	}
+	return array
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (l TicketList) ToXJSON(env utils.Environment) types.XText {
+	return l.Reduce(env).ToXJSON(env)
+}
+
+var _ types.XValue = (TicketList)(nil)
+var _ types.XIndexable = (TicketList)(nil)
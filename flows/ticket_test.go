@@ -0,0 +1,55 @@
+package flows_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickets(t *testing.T) {
+	utils.SetUUIDGenerator(utils.NewSeededUUID4Generator(12345))
+	defer utils.SetUUIDGenerator(utils.DefaultUUIDGenerator)
+
+	sa, err := engine.NewSessionAssets(static.NewEmptySource())
+	require.NoError(t, err)
+
+	env := utils.NewEnvironmentBuilder().Build()
+	ticketer := assets.NewTicketerReference(assets.TicketerUUID("19dc6346-9623-4fe4-be80-538d493ecdf5"), "Support Tickets")
+	openedOn := time.Date(2018, 10, 18, 14, 20, 30, 0, time.UTC)
+
+	ticket1 := flows.NewTicket(ticketer, "Need help", "Where are my cookies?", "Orders", openedOn)
+	ticket2 := flows.NewTicket(ticketer, "Still need help", "", "", openedOn)
+
+	assert.Equal(t, flows.TicketUUID("1ae96956-4b34-433e-8d1a-f05fe6923d6d"), ticket1.UUID)
+	assert.Equal(t, flows.TicketStatusOpen, ticket1.Status)
+	assert.Equal(t, types.NewXText("Support Tickets"), ticket1.Resolve(env, "ticketer"))
+	assert.Equal(t, types.NewXText("Orders"), ticket1.Resolve(env, "topic"))
+	assert.Equal(t, types.NewXResolveError(ticket1, "xxx"), ticket1.Resolve(env, "xxx"))
+	assert.Equal(t, types.NewXText("Need help"), ticket1.Reduce(env))
+	assert.Equal(t, types.NewXText(`{"body":"Where are my cookies?","status":"open","subject":"Need help","ticketer":"Support Tickets","topic":"Orders","uuid":"1ae96956-4b34-433e-8d1a-f05fe6923d6d"}`), ticket1.ToXJSON(env))
+
+	contact := flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), nil)
+	assert.Nil(t, contact.Tickets().LastOpen())
+
+	contact.AddTicket(ticket1)
+	contact.AddTicket(ticket2)
+	assert.Equal(t, ticket1, contact.Tickets().Get(ticket1.UUID))
+	assert.Nil(t, contact.Tickets().Get(flows.TicketUUID("2f7ba43e-03b0-4a2d-8f2b-2f9be8e3b12a")))
+	assert.Equal(t, ticket2, contact.Tickets().LastOpen())
+	assert.Equal(t, 2, contact.Tickets().Length())
+
+	// closing a ticket on a clone doesn't affect the original
+	clone := contact.Clone()
+	clone.Tickets().Get(ticket2.UUID).Status = flows.TicketStatusClosed
+	assert.Equal(t, ticket1.UUID, clone.Tickets().LastOpen().UUID)
+	assert.Equal(t, ticket2, contact.Tickets().LastOpen())
+}
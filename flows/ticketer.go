@@ -0,0 +1,56 @@
+package flows
+
+import (
+	"github.com/nyaruka/goflow/assets"
+)
+
+// Ticketer represents a ticket service
+type Ticketer struct {
+	assets.Ticketer
+}
+
+// NewTicketer returns a new ticketer object from the given ticketer asset
+func NewTicketer(asset assets.Ticketer) *Ticketer {
+	return &Ticketer{Ticketer: asset}
+}
+
+// Asset returns the underlying asset
+func (t *Ticketer) Asset() assets.Ticketer { return t.Ticketer }
+
+// Reference returns a reference to this ticketer
+func (t *Ticketer) Reference() *assets.TicketerReference {
+	if t == nil {
+		return nil
+	}
+	return assets.NewTicketerReference(t.UUID(), t.Name())
+}
+
+// TicketerAssets provides access to all ticketer assets
+type TicketerAssets struct {
+	all    []*Ticketer
+	byUUID map[assets.TicketerUUID]*Ticketer
+}
+
+// NewTicketerAssets creates a new set of ticketer assets
+func NewTicketerAssets(ticketers []assets.Ticketer) *TicketerAssets {
+	s := &TicketerAssets{
+		all:    make([]*Ticketer, len(ticketers)),
+		byUUID: make(map[assets.TicketerUUID]*Ticketer, len(ticketers)),
+	}
+	for i, asset := range ticketers {
+		ticketer := NewTicketer(asset)
+		s.all[i] = ticketer
+		s.byUUID[ticketer.UUID()] = ticketer
+	}
+	return s
+}
+
+// All returns all the ticketers
+func (s *TicketerAssets) All() []*Ticketer {
+	return s.all
+}
+
+// Get returns the ticketer with the given UUID
+func (s *TicketerAssets) Get(uuid assets.TicketerUUID) *Ticketer {
+	return s.byUUID[uuid]
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/test"
 	"github.com/nyaruka/goflow/utils"
@@ -64,6 +65,16 @@ func TestTriggerMarshaling(t *testing.T) {
 
 	contact := flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), nil)
 	contact.AddURN(flows.NewContactURN(urns.URN("tel:+12065551212"), nil))
+	previousFiredOn := time.Date(2018, 9, 11, 9, 30, 0, 0, time.UTC)
+
+	referralTrigger, err := triggers.NewReferralTrigger(
+		env,
+		flow,
+		contact,
+		channel,
+		triggers.NewReferralParams("spring_promo", "ad", map[string]string{"ad_id": "6123456789"}),
+	)
+	require.NoError(t, err)
 
 	triggerTests := []struct {
 		trigger   flows.Trigger
@@ -153,6 +164,88 @@ func TestTriggerMarshaling(t *testing.T) {
 				"type": "channel"
 			}`,
 		},
+		{
+			referralTrigger,
+			`{
+				"contact": {
+					"created_on": "2018-10-20T09:49:31.23456789Z",
+					"language": "eng",
+					"name": "Bob",
+					"urns": ["tel:+12065551212"],
+					"uuid": "c00e5d67-c275-4389-aded-7d8b151cbd5b"
+				},
+				"environment": {
+					"date_format": "YYYY-MM-DD",
+					"max_value_length": 640,
+					"number_format": {
+						"decimal_symbol": ".",
+						"digit_grouping_symbol": ","
+					},
+					"redaction_policy": "none",
+					"time_format": "tt:mm",
+					"timezone": "UTC"
+				},
+				"event": {
+					"channel": {
+						"name": "Nexmo",
+						"uuid": "3a05eaf5-cb1b-4246-bef1-f277419c83a7"
+					},
+					"type": "referral"
+				},
+				"flow": {
+					"name": "Registration",
+					"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"
+				},
+				"params": {
+					"extra": {"ad_id": "6123456789"},
+					"referrer_id": "spring_promo",
+					"source": "ad"
+				},
+				"triggered_on": "2018-10-20T09:49:31.23456789Z",
+				"type": "channel"
+			}`,
+		},
+		{
+			triggers.NewConsentTrigger(
+				env,
+				flow,
+				contact,
+				triggers.NewConsentEvent(triggers.ConsentEventTypeOptIn, channel),
+			),
+			`{
+				"contact": {
+					"created_on": "2018-10-20T09:49:31.23456789Z",
+					"language": "eng",
+					"name": "Bob",
+					"urns": ["tel:+12065551212"],
+					"uuid": "c00e5d67-c275-4389-aded-7d8b151cbd5b"
+				},
+				"environment": {
+					"date_format": "YYYY-MM-DD",
+					"max_value_length": 640,
+					"number_format": {
+						"decimal_symbol": ".",
+						"digit_grouping_symbol": ","
+					},
+					"redaction_policy": "none",
+					"time_format": "tt:mm",
+					"timezone": "UTC"
+				},
+				"event": {
+					"channel": {
+						"name": "Nexmo",
+						"uuid": "3a05eaf5-cb1b-4246-bef1-f277419c83a7"
+					},
+					"type": "opt_in"
+				},
+				"flow": {
+					"name": "Registration",
+					"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"
+				},
+				"triggered_on": "2018-10-20T09:49:31.23456789Z",
+				"type": "consent"
+			}`,
+		},
 		{
 			triggers.NewFlowActionTrigger(
 				env,
@@ -190,6 +283,44 @@ func TestTriggerMarshaling(t *testing.T) {
 				"type": "flow_action"
 			}`,
 		},
+		{
+			triggers.NewHTTPTrigger(
+				env,
+				flow,
+				contact,
+				types.JSONToXValue([]byte(`{"order_id": "ORD-1234", "amount": 25.5}`)),
+			),
+			`{
+				"contact": {
+					"created_on": "2018-10-20T09:49:31.23456789Z",
+					"language": "eng",
+					"name": "Bob",
+					"urns": ["tel:+12065551212"],
+					"uuid": "c00e5d67-c275-4389-aded-7d8b151cbd5b"
+				},
+				"environment": {
+					"date_format": "YYYY-MM-DD",
+					"max_value_length": 640,
+					"number_format": {
+						"decimal_symbol": ".",
+						"digit_grouping_symbol": ","
+					},
+					"redaction_policy": "none",
+					"time_format": "tt:mm",
+					"timezone": "UTC"
+				},
+				"flow": {
+					"name": "Registration",
+					"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"
+				},
+				"params": {
+					"amount": 25.5,
+					"order_id": "ORD-1234"
+				},
+				"triggered_on": "2018-10-20T09:49:31.23456789Z",
+				"type": "http"
+			}`,
+		},
 		{
 			triggers.NewIncomingCallTrigger(
 				env,
@@ -369,6 +500,53 @@ func TestTriggerMarshaling(t *testing.T) {
 				"type": "msg"
 			}`,
 		},
+		{
+			triggers.NewScheduleTrigger(
+				env,
+				flow,
+				contact,
+				triggers.NewMonthlyWeekdaySchedule(1, utils.NewTimeOfDay(9, 30, 0, 0), 2, time.Tuesday, utils.NewDate(2018, 1, 1)).WithEnd(utils.NewDate(2018, 12, 31)),
+				3,
+				&previousFiredOn,
+			),
+			`{
+				"contact": {
+					"created_on": "2018-10-20T09:49:31.23456789Z",
+					"language": "eng",
+					"name": "Bob",
+					"urns": ["tel:+12065551212"],
+					"uuid": "c00e5d67-c275-4389-aded-7d8b151cbd5b"
+				},
+				"environment": {
+					"date_format": "YYYY-MM-DD",
+					"max_value_length": 640,
+					"number_format": {
+						"decimal_symbol": ".",
+						"digit_grouping_symbol": ","
+					},
+					"redaction_policy": "none",
+					"time_format": "tt:mm",
+					"timezone": "UTC"
+				},
+				"flow": {
+					"name": "Registration",
+					"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"
+				},
+				"occurrence": 3,
+				"previous_fired_on": "2018-09-11T09:30:00Z",
+				"schedule": {
+					"end": "2018-12-31",
+					"interval": 1,
+					"repeat": "monthly",
+					"start": "2018-01-01",
+					"time": "09:30",
+					"week": 2,
+					"weekday": "tue"
+				},
+				"triggered_on": "2018-10-20T09:49:31.23456789Z",
+				"type": "schedule"
+			}`,
+		},
 	}
 
 	for _, tc := range triggerTests {
@@ -397,4 +575,106 @@ func TestReadTrigger(t *testing.T) {
 	// error if we don't recognize action type
 	_, err = triggers.ReadTrigger(sessionAssets, []byte(`{"type": "do_the_foo", "foo": "bar"}`), missing)
 	assert.EqualError(t, err, "unknown type: 'do_the_foo'")
+
+	// error if referral channel event doesn't have a referrer
+	_, err = triggers.ReadTrigger(sessionAssets, []byte(`{
+		"type": "channel",
+		"flow": {"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc", "name": "Registration"},
+		"event": {"type": "referral", "channel": {"uuid": "3a05eaf5-cb1b-4246-bef1-f277419c83a7", "name": "Nexmo"}},
+		"triggered_on": "2018-10-20T09:49:31.23456789Z"
+	}`), missing)
+	assert.EqualError(t, err, "referral channel event must have params")
+
+	_, err = triggers.ReadTrigger(sessionAssets, []byte(`{
+		"type": "channel",
+		"flow": {"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc", "name": "Registration"},
+		"event": {"type": "referral", "channel": {"uuid": "3a05eaf5-cb1b-4246-bef1-f277419c83a7", "name": "Nexmo"}},
+		"params": null,
+		"triggered_on": "2018-10-20T09:49:31.23456789Z"
+	}`), missing)
+	assert.EqualError(t, err, "referral channel event must have params")
+
+	_, err = triggers.ReadTrigger(sessionAssets, []byte(`{
+		"type": "channel",
+		"flow": {"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc", "name": "Registration"},
+		"event": {"type": "referral", "channel": {"uuid": "3a05eaf5-cb1b-4246-bef1-f277419c83a7", "name": "Nexmo"}},
+		"params": {"source": "ad"},
+		"triggered_on": "2018-10-20T09:49:31.23456789Z"
+	}`), missing)
+	assert.EqualError(t, err, "invalid referral params: field 'referrer_id' is required")
+}
+
+var paramsAssetsJSON = `{
+	"flows": [
+		{
+			"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc",
+			"name": "Order Update",
+			"spec_version": "12.0",
+			"language": "eng",
+			"type": "messaging",
+			"parameters": [
+				{"key": "order_id", "type": "text", "required": true},
+				{"key": "amount", "type": "number", "required": true},
+				{"key": "due", "type": "datetime"},
+				{"key": "paid", "type": "boolean"}
+			],
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"actions": [
+						{
+							"uuid": "e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+							"type": "send_msg",
+							"text": "Your order @trigger.params.order_id for $@(trigger.params.amount * 2) is on its way"
+						}
+					],
+					"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+				}
+			]
+		}
+	]
+}`
+
+func TestHTTPTrigger(t *testing.T) {
+	source, err := static.NewSource([]byte(paramsAssetsJSON))
+	require.NoError(t, err)
+
+	sa, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow := assets.NewFlowReference(assets.FlowUUID("7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"), "Order Update")
+	contact := flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), nil)
+
+	start := func(params string) (flows.Session, flows.Sprint, error) {
+		session := engine.NewBuilder().Build().NewSession(sa)
+		sprint, err := session.Start(triggers.NewHTTPTrigger(nil, flow, contact, types.JSONToXValue([]byte(params))))
+		return session, sprint, err
+	}
+
+	// params are converted to their declared types
+	session, sprint, err := start(`{"order_id": 1234, "amount": "25.5", "due": "2018-10-20T09:00:00Z", "paid": "false"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Your order 1234 for $51 is on its way", sprint.Events()[0].(*events.MsgCreatedEvent).Msg.Text())
+
+	params := session.Trigger().Params().(types.XMap)
+	assert.Equal(t, types.NewXText("1234"), params.Resolve(nil, "order_id"))
+	assert.Equal(t, types.RequireXNumberFromString("25.5"), params.Resolve(nil, "amount"))
+	assert.Equal(t, types.NewXDateTime(time.Date(2018, 10, 20, 9, 0, 0, 0, time.UTC)), params.Resolve(nil, "due"))
+	assert.Equal(t, types.XBooleanFalse, params.Resolve(nil, "paid"))
+
+	// params are checked against the declarations
+	_, _, err = start(`{"amount": 25.5}`)
+	assert.EqualError(t, err, "invalid params for flow[uuid=7c37d7e5-6468-4b31-8109-ced2ef8b5ddc,name=Order Update]: missing required param 'order_id'")
+
+	_, _, err = start(`{"order_id": "ORD-1234", "amount": "lots"}`)
+	assert.EqualError(t, err, "invalid params for flow[uuid=7c37d7e5-6468-4b31-8109-ced2ef8b5ddc,name=Order Update]: param 'amount' must be a number")
+
+	_, _, err = start(`{"order_id": "ORD-1234", "amount": 25.5, "paid": "maybe"}`)
+	assert.EqualError(t, err, "invalid params for flow[uuid=7c37d7e5-6468-4b31-8109-ced2ef8b5ddc,name=Order Update]: param 'paid' must be a boolean")
+
+	_, _, err = start(`{"order_id": "ORD-1234", "amount": 25.5, "color": "red"}`)
+	assert.EqualError(t, err, "invalid params for flow[uuid=7c37d7e5-6468-4b31-8109-ced2ef8b5ddc,name=Order Update]: param 'color' isn't declared by the flow")
+
+	_, _, err = start(`["ORD-1234"]`)
+	assert.EqualError(t, err, "invalid params for flow[uuid=7c37d7e5-6468-4b31-8109-ced2ef8b5ddc,name=Order Update]: params must be an object")
 }
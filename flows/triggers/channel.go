@@ -8,6 +8,8 @@ import (
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -24,6 +26,7 @@ type ChannelEventType string
 const (
 	ChannelEventTypeNewConversation ChannelEventType = "new_conversation"
 	ChannelEventTypeIncomingCall    ChannelEventType = "incoming_call"
+	ChannelEventTypeReferral        ChannelEventType = "referral"
 )
 
 // ChannelEvent describes the specific event on the channel that triggered the session
@@ -37,7 +40,22 @@ func NewChannelEvent(typeName ChannelEventType, channel *assets.ChannelReference
 	return &ChannelEvent{Type: typeName, Channel: channel}
 }
 
-// ChannelTrigger is used when a session was triggered by a channel event
+// ReferralParams are the params of a referral channel event, e.g. a Facebook referral, a Telegram /start deep link
+// or a click-to-message ad, and describe where the contact came from
+type ReferralParams struct {
+	ReferrerID string            `json:"referrer_id" validate:"required"`
+	Source     string            `json:"source,omitempty"`
+	Extra      map[string]string `json:"extra,omitempty"`
+}
+
+// NewReferralParams creates new referral params
+func NewReferralParams(referrerID string, source string, extra map[string]string) *ReferralParams {
+	return &ReferralParams{ReferrerID: referrerID, Source: source, Extra: extra}
+}
+
+// ChannelTrigger is used when a session was triggered by a channel event. Referral events must have params which
+// identify the referrer, and these are available in expressions as `@trigger.params.referrer_id`,
+// `@trigger.params.source` and `@trigger.params.extra`, so that a switch router can branch on them.
 //
 //   {
 //     "type": "channel",
@@ -48,9 +66,10 @@ func NewChannelEvent(typeName ChannelEventType, channel *assets.ChannelReference
 //       "created_on": "2018-01-01T12:00:00.000000Z"
 //     },
 //     "event": {
-//         "type": "new_conversation",
+//         "type": "referral",
 //         "channel": {"uuid": "58e9b092-fe42-4173-876c-ff45a14a24fe", "name": "Facebook"}
 //     },
+//     "params": {"referrer_id": "spring_promo", "source": "ad", "extra": {"ad_id": "6123456789"}},
 //     "triggered_on": "2000-01-01T00:00:00.000000000-00:00"
 //   }
 //
@@ -79,6 +98,20 @@ func NewIncomingCallTrigger(env utils.Environment, flow *assets.FlowReference, c
 	}
 }
 
+// NewReferralTrigger creates a new channel trigger for a referral event with the passed in values
+func NewReferralTrigger(env utils.Environment, flow *assets.FlowReference, contact *flows.Contact, channel *assets.ChannelReference, referral *ReferralParams) (*ChannelTrigger, error) {
+	event := NewChannelEvent(ChannelEventTypeReferral, channel)
+	params, err := json.Marshal(referral)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal referral params")
+	}
+
+	return &ChannelTrigger{
+		baseTrigger: newBaseTrigger(TypeChannel, env, flow, contact, nil, types.JSONToXValue(params)),
+		event:       event,
+	}, nil
+}
+
 // ToXJSON is called when this type is passed to @(json(...))
 func (t *ChannelTrigger) ToXJSON(env utils.Environment) types.XText {
 	return types.ResolveKeys(env, t, "type", "params").ToXJSON(env)
@@ -101,6 +134,15 @@ func readChannelTrigger(sessionAssets flows.SessionAssets, data json.RawMessage,
 		return nil, err
 	}
 
+	if e.Event.Type == ChannelEventTypeReferral {
+		if e.Params == nil || string(e.Params) == "null" {
+			return nil, errors.New("referral channel event must have params")
+		}
+		if err := utils.UnmarshalAndValidate(e.Params, &ReferralParams{}); err != nil {
+			return nil, errors.Wrap(err, "invalid referral params")
+		}
+	}
+
 	t := &ChannelTrigger{
 		event: e.Event,
 	}
@@ -0,0 +1,141 @@
+package triggers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions/modifiers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeConsent, readConsentTrigger)
+}
+
+// TypeConsent is the type for sessions triggered by a contact opting in or out of messages on a channel
+const TypeConsent string = "consent"
+
+// ConsentEventType is whether the contact opted in or out
+type ConsentEventType string
+
+// the different consent event types
+const (
+	ConsentEventTypeOptIn  ConsentEventType = "opt_in"
+	ConsentEventTypeOptOut ConsentEventType = "opt_out"
+)
+
+// ConsentEvent describes the opt-in or opt-out on the channel that triggered the session
+type ConsentEvent struct {
+	Type    ConsentEventType         `json:"type" validate:"required,eq=opt_in|eq=opt_out"`
+	Channel *assets.ChannelReference `json:"channel" validate:"required,dive"`
+}
+
+// NewConsentEvent creates a new consent event
+func NewConsentEvent(typeName ConsentEventType, channel *assets.ChannelReference) *ConsentEvent {
+	return &ConsentEvent{Type: typeName, Channel: channel}
+}
+
+// ConsentTrigger is used when a session was triggered by a contact opting in or out of messages on a channel. The
+// contact's consent for that channel is updated as the flow starts, generating a [event:contact_consent_changed]
+// event on the first run if it changed.
+//
+//   {
+//     "type": "consent",
+//     "flow": {"uuid": "50c3706e-fedb-42c0-8eab-dda3335714b7", "name": "Registration"},
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z"
+//     },
+//     "event": {
+//         "type": "opt_in",
+//         "channel": {"uuid": "58e9b092-fe42-4173-876c-ff45a14a24fe", "name": "WhatsApp"}
+//     },
+//     "triggered_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @trigger consent
+type ConsentTrigger struct {
+	baseTrigger
+	event *ConsentEvent
+}
+
+// NewConsentTrigger creates a new consent trigger with the passed in values
+func NewConsentTrigger(env utils.Environment, flow *assets.FlowReference, contact *flows.Contact, event *ConsentEvent) *ConsentTrigger {
+	return &ConsentTrigger{
+		baseTrigger: newBaseTrigger(TypeConsent, env, flow, contact, nil, nil),
+		event:       event,
+	}
+}
+
+// Initialize initializes the session and checks that the channel exists
+func (t *ConsentTrigger) Initialize(session flows.Session, logEvent flows.EventCallback) error {
+	if err := t.baseTrigger.Initialize(session, logEvent); err != nil {
+		return err
+	}
+
+	if session.Assets().Channels().Get(t.event.Channel.UUID) == nil {
+		return errors.Errorf("unable to load %s", t.event.Channel)
+	}
+	return nil
+}
+
+// InitializeRun records the contact's consent when we visit our first node
+func (t *ConsentTrigger) InitializeRun(run flows.FlowRun, logEvent flows.EventCallback) error {
+	if run.Contact() != nil {
+		channel := run.Session().Assets().Channels().Get(t.event.Channel.UUID)
+
+		status := flows.ConsentStatusGranted
+		if t.event.Type == ConsentEventTypeOptOut {
+			status = flows.ConsentStatusRevoked
+		}
+
+		modifiers.NewConsentModifier(channel, status, run.Session().Now()).Apply(run.Session().Environment(), run.Session().Assets(), run.Contact(), logEvent)
+	}
+
+	return t.baseTrigger.InitializeRun(run, logEvent)
+}
+
+var _ flows.Trigger = (*ConsentTrigger)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type consentTriggerEnvelope struct {
+	baseTriggerEnvelope
+	Event *ConsentEvent `json:"event" validate:"required,dive"`
+}
+
+func readConsentTrigger(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Trigger, error) {
+	e := &consentTriggerEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	t := &ConsentTrigger{
+		event: e.Event,
+	}
+
+	if err := t.unmarshal(sessionAssets, &e.baseTriggerEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// MarshalJSON marshals this trigger into JSON
+func (t *ConsentTrigger) MarshalJSON() ([]byte, error) {
+	e := &consentTriggerEnvelope{
+		Event: t.event,
+	}
+
+	if err := t.marshal(&e.baseTriggerEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
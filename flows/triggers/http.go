@@ -0,0 +1,101 @@
+package triggers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeHTTP, readHTTPTrigger)
+}
+
+// TypeHTTP is the type for sessions triggered by an external API call
+const TypeHTTP string = "http"
+
+// HTTPTrigger is used when a session was triggered by an external API call. If the flow declares parameters then the
+// params are checked against those declarations when the session starts, and converted to their declared types, so that
+// `@trigger.params.amount` is a number if it was declared as one.
+//
+//   {
+//     "type": "http",
+//     "flow": {"uuid": "50c3706e-fedb-42c0-8eab-dda3335714b7", "name": "Registration"},
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z"
+//     },
+//     "params": {"order_id": "ORD-1234", "amount": 25.5},
+//     "triggered_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @trigger http
+type HTTPTrigger struct {
+	baseTrigger
+}
+
+// NewHTTPTrigger creates a new HTTP trigger with the passed in params
+func NewHTTPTrigger(env utils.Environment, flow *assets.FlowReference, contact *flows.Contact, params types.XValue) *HTTPTrigger {
+	return &HTTPTrigger{
+		baseTrigger: newBaseTrigger(TypeHTTP, env, flow, contact, nil, params),
+	}
+}
+
+// Initialize initializes the session and checks our params against those declared by the flow
+func (t *HTTPTrigger) Initialize(session flows.Session, logEvent flows.EventCallback) error {
+	if err := t.baseTrigger.Initialize(session, logEvent); err != nil {
+		return err
+	}
+
+	flow, _ := session.Assets().Flows().Get(t.Flow().UUID)
+
+	// flows which don't declare parameters can be started with any params
+	if flow.Parameters() == nil {
+		return nil
+	}
+
+	params, err := flow.Parameters().Coerce(session.Environment(), t.params)
+	if err != nil {
+		return errors.Wrapf(err, "invalid params for %s", flow.Reference())
+	}
+
+	t.params = params
+	return nil
+}
+
+var _ flows.Trigger = (*HTTPTrigger)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+func readHTTPTrigger(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Trigger, error) {
+	e := &baseTriggerEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	t := &HTTPTrigger{}
+
+	if err := t.unmarshal(sessionAssets, e, missing); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// MarshalJSON marshals this trigger into JSON
+func (t *HTTPTrigger) MarshalJSON() ([]byte, error) {
+	e := &baseTriggerEnvelope{}
+
+	if err := t.marshal(e); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,444 @@
+package triggers
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeSchedule, readScheduleTrigger)
+
+	utils.Validator.RegisterAlias("schedule_repeat", "eq=daily|eq=weekly|eq=monthly")
+	utils.Validator.RegisterAlias("schedule_weekday", "eq=mon|eq=tue|eq=wed|eq=thu|eq=fri|eq=sat|eq=sun")
+}
+
+// TypeSchedule is the type for sessions triggered by a recurring schedule
+const TypeSchedule string = "schedule"
+
+// ScheduleRepeat is how often a schedule repeats
+type ScheduleRepeat string
+
+// the different ways a schedule can repeat
+const (
+	ScheduleRepeatDaily   ScheduleRepeat = "daily"
+	ScheduleRepeatWeekly  ScheduleRepeat = "weekly"
+	ScheduleRepeatMonthly ScheduleRepeat = "monthly"
+)
+
+// ScheduleLast can be used as a day of the month or week of the month to mean the last one
+const ScheduleLast = -1
+
+// the longest we need to look ahead to find the next fire date, i.e. an nth weekday of the month repeating yearly
+const maxScheduleLookahead = 366 + 31
+
+var weekdayCodes = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Schedule is a recurring schedule on which a flow is started. It fires at a time of day on days which are either
+// every N days, on some days of every N weeks, or on a day of every N months. The day of the month can be a fixed
+// day, the last day, or the nth (or last) weekday of the month, e.g. the 2nd Tuesday. Days are counted from the start
+// date, and schedules can have an end date after which they no longer fire.
+type Schedule struct {
+	repeat    ScheduleRepeat
+	interval  int
+	timeOfDay utils.TimeOfDay
+	weekdays  []time.Weekday
+	day       int
+	week      int
+	weekday   time.Weekday
+	timezone  *time.Location
+	start     utils.Date
+	end       *utils.Date
+}
+
+// NewDailySchedule creates a new schedule which fires every interval days
+func NewDailySchedule(interval int, timeOfDay utils.TimeOfDay, start utils.Date) *Schedule {
+	return &Schedule{repeat: ScheduleRepeatDaily, interval: interval, timeOfDay: timeOfDay, start: start}
+}
+
+// NewWeeklySchedule creates a new schedule which fires on the given weekdays of every interval weeks
+func NewWeeklySchedule(interval int, timeOfDay utils.TimeOfDay, weekdays []time.Weekday, start utils.Date) *Schedule {
+	return &Schedule{repeat: ScheduleRepeatWeekly, interval: interval, timeOfDay: timeOfDay, weekdays: weekdays, start: start}
+}
+
+// NewMonthlySchedule creates a new schedule which fires on the given day of every interval months. Days past the end of
+// a month fire on its last day, and ScheduleLast can be used to always fire on the last day.
+func NewMonthlySchedule(interval int, timeOfDay utils.TimeOfDay, day int, start utils.Date) *Schedule {
+	return &Schedule{repeat: ScheduleRepeatMonthly, interval: interval, timeOfDay: timeOfDay, day: day, start: start}
+}
+
+// NewMonthlyWeekdaySchedule creates a new schedule which fires on the nth weekday of every interval months, e.g. the
+// 2nd Tuesday. ScheduleLast can be used as the week to fire on the last such weekday of the month.
+func NewMonthlyWeekdaySchedule(interval int, timeOfDay utils.TimeOfDay, week int, weekday time.Weekday, start utils.Date) *Schedule {
+	return &Schedule{repeat: ScheduleRepeatMonthly, interval: interval, timeOfDay: timeOfDay, week: week, weekday: weekday, start: start}
+}
+
+// WithTimezone sets the timezone of this schedule, which otherwise fires in the contact's timezone
+func (s *Schedule) WithTimezone(timezone *time.Location) *Schedule {
+	s.timezone = timezone
+	return s
+}
+
+// WithEnd sets the last date on which this schedule can fire
+func (s *Schedule) WithEnd(end utils.Date) *Schedule {
+	s.end = &end
+	return s
+}
+
+// Repeat returns how often this schedule repeats
+func (s *Schedule) Repeat() ScheduleRepeat { return s.repeat }
+
+// Timezone returns the timezone of this schedule if it has one
+func (s *Schedule) Timezone() *time.Location { return s.timezone }
+
+// Next returns the first time after the given time that this schedule fires, or nil if it has ended. The timezone is
+// used unless the schedule has its own.
+func (s *Schedule) Next(after time.Time, tz *time.Location) *time.Time {
+	if s.timezone != nil {
+		tz = s.timezone
+	}
+
+	date := utils.ExtractDate(after.In(tz))
+	if date.Compare(s.start) < 0 {
+		date = s.start
+	}
+
+	for i := 0; i < maxScheduleLookahead*s.interval; i++ {
+		if s.end != nil && date.Compare(*s.end) > 0 {
+			return nil
+		}
+
+		if s.firesOn(date) {
+			fireOn := s.timeOfDay.Combine(date, tz)
+			if fireOn.After(after) {
+				return &fireOn
+			}
+		}
+
+		date = addDays(date, 1)
+	}
+	return nil
+}
+
+// NextForContact returns the first time after the given time that this schedule fires for the given contact. Unless the
+// schedule has its own timezone, it fires in the contact's timezone, or the environment's if the contact doesn't have one.
+func (s *Schedule) NextForContact(env utils.Environment, contact *flows.Contact, after time.Time) *time.Time {
+	tz := env.Timezone()
+	if contact != nil && contact.Timezone() != nil {
+		tz = contact.Timezone()
+	}
+	return s.Next(after, tz)
+}
+
+// FireTimes returns up to count times after the given time that this schedule fires
+func (s *Schedule) FireTimes(after time.Time, tz *time.Location, count int) []time.Time {
+	times := make([]time.Time, 0, count)
+
+	for len(times) < count {
+		next := s.Next(after, tz)
+		if next == nil {
+			break
+		}
+		times = append(times, *next)
+		after = *next
+	}
+	return times
+}
+
+// whether this schedule fires on the given date
+func (s *Schedule) firesOn(date utils.Date) bool {
+	switch s.repeat {
+	case ScheduleRepeatDaily:
+		return daysBetween(s.start, date)%s.interval == 0
+
+	case ScheduleRepeatWeekly:
+		// weeks start on Monday
+		startOffset := (int(s.start.Weekday()) + 6) % 7
+		if (daysBetween(s.start, date)+startOffset)/7%s.interval != 0 {
+			return false
+		}
+
+		weekdays := s.weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{s.start.Weekday()}
+		}
+		for _, weekday := range weekdays {
+			if date.Weekday() == weekday {
+				return true
+			}
+		}
+		return false
+
+	case ScheduleRepeatMonthly:
+		months := (date.Year-s.start.Year)*12 + int(date.Month) - int(s.start.Month)
+		if months%s.interval != 0 {
+			return false
+		}
+
+		lastDay := daysInMonth(date)
+
+		if s.week != 0 {
+			if date.Weekday() != s.weekday {
+				return false
+			}
+			if s.week == ScheduleLast {
+				return date.Day+7 > lastDay
+			}
+			return (date.Day-1)/7+1 == s.week
+		}
+
+		if s.day == ScheduleLast || s.day > lastDay {
+			return date.Day == lastDay
+		}
+		return date.Day == s.day
+	}
+	return false
+}
+
+func addDays(date utils.Date, days int) utils.Date {
+	return utils.ExtractDate(date.Combine(utils.ZeroTimeOfDay, time.UTC).AddDate(0, 0, days))
+}
+
+func daysBetween(date1 utils.Date, date2 utils.Date) int {
+	return int(date2.Combine(utils.ZeroTimeOfDay, time.UTC).Sub(date1.Combine(utils.ZeroTimeOfDay, time.UTC)).Hours() / 24)
+}
+
+func daysInMonth(date utils.Date) int {
+	return time.Date(date.Year, date.Month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// ScheduleTrigger is used when a session was triggered by a recurring schedule. The schedule can be accessed in
+// expressions as `@trigger.schedule` which has the following properties:
+//
+//  * `repeat` how often the schedule repeats, one of "daily", "weekly" or "monthly"
+//  * `occurrence` the number of times the schedule has fired including this time, starting at 1
+//  * `previous` the time the schedule previously fired, if it has fired before
+//  * `next` the next time the schedule will fire, if it hasn't ended
+//
+//   {
+//     "type": "schedule",
+//     "flow": {"uuid": "50c3706e-fedb-42c0-8eab-dda3335714b7", "name": "Registration"},
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z"
+//     },
+//     "schedule": {
+//       "repeat": "monthly",
+//       "interval": 1,
+//       "time": "09:30",
+//       "week": 2,
+//       "weekday": "tue",
+//       "start": "2018-01-01"
+//     },
+//     "occurrence": 3,
+//     "previous_fired_on": "2018-02-13T09:30:00.000000000-00:00",
+//     "triggered_on": "2018-03-13T09:30:00.000000000-00:00"
+//   }
+//
+// @trigger schedule
+type ScheduleTrigger struct {
+	baseTrigger
+	schedule        *Schedule
+	occurrence      int
+	previousFiredOn *time.Time
+}
+
+// NewScheduleTrigger creates a new schedule trigger for the given occurrence of the schedule
+func NewScheduleTrigger(env utils.Environment, flow *assets.FlowReference, contact *flows.Contact, schedule *Schedule, occurrence int, previousFiredOn *time.Time) *ScheduleTrigger {
+	return &ScheduleTrigger{
+		baseTrigger:     newBaseTrigger(TypeSchedule, env, flow, contact, nil, nil),
+		schedule:        schedule,
+		occurrence:      occurrence,
+		previousFiredOn: previousFiredOn,
+	}
+}
+
+// Schedule returns the schedule which triggered the session
+func (t *ScheduleTrigger) Schedule() *Schedule { return t.schedule }
+
+// Occurrence returns the number of times the schedule has fired including this time
+func (t *ScheduleTrigger) Occurrence() int { return t.occurrence }
+
+// PreviousFiredOn returns the time the schedule previously fired
+func (t *ScheduleTrigger) PreviousFiredOn() *time.Time { return t.previousFiredOn }
+
+// Resolve resolves the given key when this trigger is referenced in an expression
+func (t *ScheduleTrigger) Resolve(env utils.Environment, key string) types.XValue {
+	switch strings.ToLower(key) {
+	case "schedule":
+		var previous, next types.XValue
+		if t.previousFiredOn != nil {
+			previous = types.NewXDateTime(*t.previousFiredOn)
+		}
+		if nextFireOn := t.schedule.NextForContact(env, t.contact, t.triggeredOn); nextFireOn != nil {
+			next = types.NewXDateTime(*nextFireOn)
+		}
+
+		return types.NewXMap(map[string]types.XValue{
+			"repeat":     types.NewXText(string(t.schedule.repeat)),
+			"occurrence": types.NewXNumberFromInt(t.occurrence),
+			"previous":   previous,
+			"next":       next,
+		})
+	}
+
+	return t.baseTrigger.Resolve(env, key)
+}
+
+// ToXJSON is called when this type is passed to @(json(...))
+func (t *ScheduleTrigger) ToXJSON(env utils.Environment) types.XText {
+	return types.ResolveKeys(env, t, "type", "params", "schedule").ToXJSON(env)
+}
+
+var _ flows.Trigger = (*ScheduleTrigger)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type scheduleEnvelope struct {
+	Repeat   ScheduleRepeat `json:"repeat" validate:"required,schedule_repeat"`
+	Interval int            `json:"interval" validate:"required,min=1"`
+	Time     string         `json:"time" validate:"required"`
+	Weekdays []string       `json:"weekdays,omitempty" validate:"dive,schedule_weekday"`
+	Day      int            `json:"day,omitempty" validate:"min=-1,max=31"`
+	Week     int            `json:"week,omitempty" validate:"min=-1,max=5"`
+	Weekday  string         `json:"weekday,omitempty" validate:"omitempty,schedule_weekday"`
+	Timezone string         `json:"timezone,omitempty"`
+	Start    string         `json:"start" validate:"required"`
+	End      string         `json:"end,omitempty"`
+}
+
+func parseWeekday(code string) time.Weekday {
+	for i, c := range weekdayCodes {
+		if c == code {
+			return time.Weekday(i)
+		}
+	}
+	return time.Sunday
+}
+
+// UnmarshalJSON unmarshals a schedule from JSON
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	e := &scheduleEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return err
+	}
+
+	s.repeat = e.Repeat
+	s.interval = e.Interval
+	s.day = e.Day
+	s.week = e.Week
+
+	var err error
+	if s.timeOfDay, err = utils.TimeFromString(e.Time); err != nil {
+		return errors.Wrap(err, "invalid schedule time")
+	}
+	if s.start, err = utils.ParseDate("2006-01-02", e.Start); err != nil {
+		return errors.Wrap(err, "invalid schedule start")
+	}
+	if e.End != "" {
+		end, err := utils.ParseDate("2006-01-02", e.End)
+		if err != nil {
+			return errors.Wrap(err, "invalid schedule end")
+		}
+		s.end = &end
+	}
+	if e.Timezone != "" {
+		if s.timezone, err = time.LoadLocation(e.Timezone); err != nil {
+			return errors.Wrap(err, "invalid schedule timezone")
+		}
+	}
+
+	for _, code := range e.Weekdays {
+		s.weekdays = append(s.weekdays, parseWeekday(code))
+	}
+
+	if s.repeat == ScheduleRepeatMonthly {
+		if (s.day == 0) == (s.week == 0) {
+			return errors.New("monthly schedule must have either a day or a week and weekday")
+		}
+		if s.week != 0 {
+			if e.Weekday == "" {
+				return errors.New("monthly schedule with a week must have a weekday")
+			}
+			s.weekday = parseWeekday(e.Weekday)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON marshals this schedule into JSON
+func (s *Schedule) MarshalJSON() ([]byte, error) {
+	e := &scheduleEnvelope{
+		Repeat:   s.repeat,
+		Interval: s.interval,
+		Time:     s.timeOfDay.Format("15:04"),
+		Day:      s.day,
+		Week:     s.week,
+		Start:    s.start.String(),
+	}
+
+	for _, weekday := range s.weekdays {
+		e.Weekdays = append(e.Weekdays, weekdayCodes[weekday])
+	}
+	if s.week != 0 {
+		e.Weekday = weekdayCodes[s.weekday]
+	}
+	if s.timezone != nil {
+		e.Timezone = s.timezone.String()
+	}
+	if s.end != nil {
+		e.End = s.end.String()
+	}
+
+	return json.Marshal(e)
+}
+
+type scheduleTriggerEnvelope struct {
+	baseTriggerEnvelope
+	Schedule        *Schedule  `json:"schedule" validate:"required"`
+	Occurrence      int        `json:"occurrence" validate:"required,min=1"`
+	PreviousFiredOn *time.Time `json:"previous_fired_on,omitempty"`
+}
+
+func readScheduleTrigger(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Trigger, error) {
+	e := &scheduleTriggerEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	t := &ScheduleTrigger{
+		schedule:        e.Schedule,
+		occurrence:      e.Occurrence,
+		previousFiredOn: e.PreviousFiredOn,
+	}
+	if err := t.unmarshal(sessionAssets, &e.baseTriggerEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// MarshalJSON marshals this trigger into JSON
+func (t *ScheduleTrigger) MarshalJSON() ([]byte, error) {
+	e := &scheduleTriggerEnvelope{
+		Schedule:        t.schedule,
+		Occurrence:      t.occurrence,
+		PreviousFiredOn: t.previousFiredOn,
+	}
+
+	if err := t.marshal(&e.baseTriggerEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
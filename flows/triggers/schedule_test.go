@@ -0,0 +1,230 @@
+package triggers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleFireTimes(t *testing.T) {
+	kigali, _ := time.LoadLocation("Africa/Kigali")
+	guayaquil, _ := time.LoadLocation("America/Guayaquil")
+
+	nineAM := utils.NewTimeOfDay(9, 0, 0, 0)
+	after := time.Date(2018, 10, 20, 9, 49, 0, 0, time.UTC)
+
+	tcs := []struct {
+		description string
+		schedule    *triggers.Schedule
+		tz          *time.Location
+		expected    []time.Time
+	}{
+		{
+			"every other day",
+			triggers.NewDailySchedule(2, nineAM, utils.NewDate(2018, 10, 1)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2018, 10, 21, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 23, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 25, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"mondays and thursdays of every other week",
+			triggers.NewWeeklySchedule(2, nineAM, []time.Weekday{time.Monday, time.Thursday}, utils.NewDate(2018, 10, 1)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2018, 10, 29, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 11, 12, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"31st of every month falls back to the last day",
+			triggers.NewMonthlySchedule(1, nineAM, 31, utils.NewDate(2018, 1, 1)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2018, 10, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 12, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"15th of every quarter",
+			triggers.NewMonthlySchedule(3, nineAM, 15, utils.NewDate(2018, 1, 15)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2019, 1, 15, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, 4, 15, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, 7, 15, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"last Friday of every month",
+			triggers.NewMonthlyWeekdaySchedule(1, nineAM, triggers.ScheduleLast, time.Friday, utils.NewDate(2018, 1, 1)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2018, 10, 26, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 12, 28, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"2nd Tuesday of every month until the end of the year",
+			triggers.NewMonthlyWeekdaySchedule(1, nineAM, 2, time.Tuesday, utils.NewDate(2018, 1, 1)).WithEnd(utils.NewDate(2018, 12, 31)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2018, 11, 13, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, 12, 11, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"daily in the given timezone",
+			triggers.NewDailySchedule(1, nineAM, utils.NewDate(2018, 1, 1)),
+			guayaquil,
+			[]time.Time{
+				time.Date(2018, 10, 20, 14, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 21, 14, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 22, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"daily in the schedule's own timezone",
+			triggers.NewDailySchedule(1, nineAM, utils.NewDate(2018, 1, 1)).WithTimezone(kigali),
+			guayaquil,
+			[]time.Time{
+				time.Date(2018, 10, 21, 7, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 22, 7, 0, 0, 0, time.UTC),
+				time.Date(2018, 10, 23, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"not started yet",
+			triggers.NewDailySchedule(1, nineAM, utils.NewDate(2019, 1, 1)),
+			time.UTC,
+			[]time.Time{
+				time.Date(2019, 1, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, 1, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, 1, 3, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"already ended",
+			triggers.NewDailySchedule(1, nineAM, utils.NewDate(2018, 1, 1)).WithEnd(utils.NewDate(2018, 10, 20)),
+			time.UTC,
+			[]time.Time{},
+		},
+	}
+
+	for _, tc := range tcs {
+		actual := tc.schedule.FireTimes(after, tc.tz, 3)
+
+		require.Equal(t, len(tc.expected), len(actual), "fire times count mismatch for %s", tc.description)
+		for i := range tc.expected {
+			assert.True(t, tc.expected[i].Equal(actual[i]), "fire time mismatch for %s: expected %s, got %s", tc.description, tc.expected[i], actual[i])
+		}
+	}
+}
+
+func TestScheduleNextForContact(t *testing.T) {
+	kigali, _ := time.LoadLocation("Africa/Kigali")
+	guayaquil, _ := time.LoadLocation("America/Guayaquil")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	source, err := static.NewSource([]byte(`{}`))
+	require.NoError(t, err)
+	sa, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	env := utils.NewEnvironmentBuilder().WithTimezone(tokyo).Build()
+	schedule := triggers.NewDailySchedule(1, utils.NewTimeOfDay(9, 0, 0, 0), utils.NewDate(2018, 1, 1))
+	after := time.Date(2018, 10, 20, 9, 49, 0, 0, time.UTC)
+
+	// contact's timezone takes precedence over the environment's
+	contact := flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), guayaquil)
+	assert.Equal(t, time.Date(2018, 10, 20, 14, 0, 0, 0, time.UTC), schedule.NextForContact(env, contact, after).UTC())
+
+	// which is used if the contact doesn't have one
+	contact = flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), nil)
+	assert.Equal(t, time.Date(2018, 10, 21, 0, 0, 0, 0, time.UTC), schedule.NextForContact(env, contact, after).UTC())
+	assert.Equal(t, time.Date(2018, 10, 21, 0, 0, 0, 0, time.UTC), schedule.NextForContact(env, nil, after).UTC())
+
+	// but a schedule with its own timezone always uses that
+	schedule.WithTimezone(kigali)
+	contact = flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), guayaquil)
+	assert.Equal(t, time.Date(2018, 10, 21, 7, 0, 0, 0, time.UTC), schedule.NextForContact(env, contact, after).UTC())
+	assert.Equal(t, time.Date(2018, 10, 21, 7, 0, 0, 0, time.UTC), schedule.NextForContact(env, nil, after).UTC())
+}
+
+func TestReadSchedule(t *testing.T) {
+	read := func(data string) error {
+		s := &triggers.Schedule{}
+		return s.UnmarshalJSON([]byte(data))
+	}
+
+	assert.NoError(t, read(`{"repeat": "weekly", "interval": 1, "time": "09:00", "weekdays": ["mon", "fri"], "start": "2018-01-01", "timezone": "Africa/Kigali"}`))
+	assert.NoError(t, read(`{"repeat": "monthly", "interval": 1, "time": "09:00", "day": -1, "start": "2018-01-01"}`))
+
+	assert.EqualError(t, read(`{"repeat": "hourly", "interval": 1, "time": "09:00", "start": "2018-01-01"}`), "field 'repeat' failed tag 'schedule_repeat'")
+	assert.EqualError(t, read(`{"repeat": "weekly", "interval": 1, "time": "09:00", "weekdays": ["monday"], "start": "2018-01-01"}`), "field 'weekdays[0]' failed tag 'schedule_weekday'")
+	assert.EqualError(t, read(`{"repeat": "monthly", "interval": 1, "time": "09:00", "start": "2018-01-01"}`), "monthly schedule must have either a day or a week and weekday")
+	assert.EqualError(t, read(`{"repeat": "monthly", "interval": 1, "time": "09:00", "week": 2, "start": "2018-01-01"}`), "monthly schedule with a week must have a weekday")
+	assert.EqualError(t, read(`{"repeat": "daily", "interval": 1, "time": "09:00", "start": "2018-01-01", "timezone": "Mars/Olympus"}`), "invalid schedule timezone: unknown time zone Mars/Olympus")
+}
+
+var scheduleAssetsJSON = `{
+	"flows": [
+		{
+			"uuid": "7c37d7e5-6468-4b31-8109-ced2ef8b5ddc",
+			"name": "Reminders",
+			"spec_version": "12.0",
+			"language": "eng",
+			"type": "messaging",
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"actions": [
+						{
+							"uuid": "e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+							"type": "send_msg",
+							"text": "Reminder #@trigger.schedule.occurrence (last sent @(format_date(trigger.schedule.previous)), next @(format_date(trigger.schedule.next)))"
+						}
+					],
+					"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+				}
+			]
+		}
+	]
+}`
+
+func TestScheduleTrigger(t *testing.T) {
+	utils.SetTimeSource(utils.NewFixedTimeSource(time.Date(2018, 10, 9, 9, 0, 0, 0, time.UTC)))
+	defer utils.SetTimeSource(utils.DefaultTimeSource)
+
+	source, err := static.NewSource([]byte(scheduleAssetsJSON))
+	require.NoError(t, err)
+
+	sa, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow := assets.NewFlowReference(assets.FlowUUID("7c37d7e5-6468-4b31-8109-ced2ef8b5ddc"), "Reminders")
+	contact := flows.NewEmptyContact(sa, "Bob", utils.Language("eng"), nil)
+	schedule := triggers.NewMonthlyWeekdaySchedule(1, utils.NewTimeOfDay(9, 0, 0, 0), 2, time.Tuesday, utils.NewDate(2018, 1, 1))
+	previousFiredOn := time.Date(2018, 9, 11, 9, 0, 0, 0, time.UTC)
+
+	session := engine.NewBuilder().Build().NewSession(sa)
+	sprint, err := session.Start(triggers.NewScheduleTrigger(nil, flow, contact, schedule, 10, &previousFiredOn))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Reminder #10 (last sent 2018-09-11, next 2018-11-13)", sprint.Events()[0].(*events.MsgCreatedEvent).Msg.Text())
+}
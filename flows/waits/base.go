@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/nyaruka/goflow/excellent/tools"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers"
 	"github.com/nyaruka/goflow/utils"
 
 	"github.com/pkg/errors"
@@ -24,7 +28,15 @@ func RegisterType(name string, f readFunc) {
 type baseWait struct {
 	type_ string
 
-	timeout   *int
+	// the timeout is either a fixed number of seconds or an expression which evaluates to a number of seconds, and can
+	// be pushed back to avoid quiet hours
+	timeout           *int
+	timeoutExpression string
+	quietHours        *QuietHours
+
+	// whether changes to the contact made outside of the flow should re-evaluate the node's router
+	rerouteOnContactChange bool
+
 	timeoutOn *time.Time
 }
 
@@ -38,37 +50,111 @@ func (w *baseWait) Type() string { return w.type_ }
 // Timeout returns the timeout of this wait in seconds or nil if no timeout is set
 func (w *baseWait) Timeout() *int { return w.timeout }
 
+// TimeoutExpression returns the expression which gives the timeout of this wait in seconds (optional)
+func (w *baseWait) TimeoutExpression() string { return w.timeoutExpression }
+
+// QuietHours returns the period of each day when this wait shouldn't time out (optional)
+func (w *baseWait) QuietHours() *QuietHours { return w.quietHours }
+
+// RerouteOnContactChange returns whether this wait should re-evaluate its node's router when the contact is changed
+func (w *baseWait) RerouteOnContactChange() bool { return w.rerouteOnContactChange }
+
 // TimeoutOn returns when this wait times out
 func (w *baseWait) TimeoutOn() *time.Time { return w.timeoutOn }
 
+// Validate checks that this wait's expressions are valid
+func (w *baseWait) Validate(exits []flows.Exit) error {
+	return validateTemplate(w.timeoutExpression, "timeout_expression")
+}
+
+// whether this wait has a timeout of any kind
+func (w *baseWait) hasTimeout() bool { return w.timeout != nil || w.timeoutExpression != "" }
+
 // Begin beings waiting
-func (w *baseWait) Begin(run flows.FlowRun) bool {
+func (w *baseWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
+	w.timeoutOn = nil
+
+	var seconds int
 	if w.timeout != nil {
-		timeoutOn := utils.Now().Add(time.Second * time.Duration(*w.timeout))
+		seconds = *w.timeout
+	} else if w.timeoutExpression != "" {
+		var err error
+		if seconds, err = evaluateSeconds(run, w.timeoutExpression); err != nil {
+			log(events.NewErrorEvent(errors.Wrap(err, "unable to evaluate wait timeout")))
+			return true
+		}
+	} else {
+		return true
+	}
 
-		w.timeoutOn = &timeoutOn
+	timeoutOn := run.Session().Now().Add(time.Second * time.Duration(seconds))
+	if w.quietHours != nil {
+		timeoutOn = w.quietHours.Adjust(timeoutOn, run.Environment().Timezone())
 	}
+
+	w.timeoutOn = &timeoutOn
 	return true
 }
 
-// End ends this wait or returns an error
-func (w *baseWait) End(resume flows.Resume, node flows.Node) error {
+// End ends this wait or returns an error. Besides the resume types handled by each wait type, any wait can be ended by
+// the run expiring, by its timeout or by the contact changing, and any other type of resume is an error.
+func (w *baseWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
 	switch resume.Type() {
-	case resumes.TypeRunExpiration:
-		// expired runs always end a wait
+	case resumes.TypeRunExpiration, resumes.TypeContactChanged:
+		// expired runs always end a wait, and contact changes are validated by ValidateResume
 		return nil
 	case resumes.TypeWaitTimeout:
-		if node.Wait().Timeout() == nil {
+		if nodeWait, isBase := node.Wait().(interface{ hasTimeout() bool }); isBase && !nodeWait.hasTimeout() {
 			return errors.Errorf("can't end with timeout as node no longer has a wait timeout")
 		}
-		if w.Timeout() == nil || w.TimeoutOn() == nil {
+		if !w.hasTimeout() || w.TimeoutOn() == nil {
 			return errors.Errorf("can't end with timeout as session wait has no timeout")
 		}
-		if utils.Now().Before(*w.TimeoutOn()) {
+		if run.Session().Now().Before(*w.TimeoutOn()) {
 			return errors.Errorf("can't end with timeout before wait has timed out")
 		}
+		return nil
 	}
-	return nil
+
+	return errors.Errorf("can't end a %s wait with a %s resume", w.type_, resume.Type())
+}
+
+// ValidateResume checks whether a contact changed resume should end this wait. We keep waiting unless we've opted in to
+// re-evaluating the node's router and it now matches one of its cases, in which case we take that route.
+func (w *baseWait) ValidateResume(run flows.FlowRun, resume flows.Resume, node flows.Node, log flows.EventCallback) (bool, flows.Route) {
+	if resume.Type() != resumes.TypeContactChanged {
+		return false, flows.NoRoute
+	}
+
+	router, isSwitch := node.Router().(*routers.SwitchRouter)
+	if !w.rerouteOnContactChange || !isSwitch {
+		return true, flows.NoRoute
+	}
+
+	step, _, err := run.PathLocation()
+	if err != nil {
+		log(events.NewErrorEvent(err))
+		return true, flows.NoRoute
+	}
+
+	// evaluate the router without the last input so it doesn't match against an old message, and only clear that
+	// input for good if we do re-route
+	input := run.Session().Input()
+	run.Session().SetInput(nil)
+
+	_, route, err := router.PickRoute(run, node.Exits(), step)
+	if err != nil {
+		log(events.NewErrorEvent(err))
+		run.Session().SetInput(input)
+		return true, flows.NoRoute
+	}
+
+	// only a matching case is a reason to stop waiting
+	if route.Exit() == "" || route.Exit() == router.Default {
+		run.Session().SetInput(input)
+		return true, flows.NoRoute
+	}
+	return false, route
 }
 
 //------------------------------------------------------------------------------------------
@@ -76,9 +162,12 @@ func (w *baseWait) End(resume flows.Resume, node flows.Node) error {
 //------------------------------------------------------------------------------------------
 
 type baseWaitEnvelope struct {
-	Type      string     `json:"type" validate:"required"`
-	Timeout   *int       `json:"timeout,omitempty"`
-	TimeoutOn *time.Time `json:"timeout_on,omitempty"`
+	Type                   string      `json:"type" validate:"required"`
+	Timeout                *int        `json:"timeout,omitempty"`
+	TimeoutExpression      string      `json:"timeout_expression,omitempty"`
+	QuietHours             *QuietHours `json:"quiet_hours,omitempty"`
+	RerouteOnContactChange bool        `json:"reroute_on_contact_change,omitempty"`
+	TimeoutOn              *time.Time  `json:"timeout_on,omitempty"`
 }
 
 // ReadWait reads a wait from the given JSON
@@ -98,13 +187,47 @@ func ReadWait(data json.RawMessage) (flows.Wait, error) {
 func (w *baseWait) unmarshal(e *baseWaitEnvelope) error {
 	w.type_ = e.Type
 	w.timeout = e.Timeout
+	w.timeoutExpression = e.TimeoutExpression
+	w.quietHours = e.QuietHours
+	w.rerouteOnContactChange = e.RerouteOnContactChange
 	w.timeoutOn = e.TimeoutOn
+
+	if w.timeout != nil && w.timeoutExpression != "" {
+		return errors.New("wait can't have both a timeout and a timeout expression")
+	}
 	return nil
 }
 
 func (w *baseWait) marshal(e *baseWaitEnvelope) error {
 	e.Type = w.type_
 	e.Timeout = w.timeout
+	e.TimeoutExpression = w.timeoutExpression
+	e.QuietHours = w.quietHours
+	e.RerouteOnContactChange = w.rerouteOnContactChange
 	e.TimeoutOn = w.timeoutOn
 	return nil
 }
+
+// evaluates the given expression which should give a number of seconds
+func evaluateSeconds(run flows.FlowRun, expression string) (int, error) {
+	value, err := run.EvaluateTemplateValue(expression)
+	if err != nil {
+		return 0, err
+	}
+	seconds, xerr := types.ToXNumber(run.Environment(), value)
+	if xerr != nil {
+		return 0, errors.Wrap(xerr, "expression must evaluate to a number of seconds")
+	}
+	return int(seconds.Native().IntPart()), nil
+}
+
+// checks that the given template, if there is one, is syntactically valid
+func validateTemplate(template string, name string) error {
+	if template == "" {
+		return nil
+	}
+	if err := tools.FindContextRefsInTemplate(template, flows.RunContextTopLevels, func([]string) {}); err != nil {
+		return errors.Wrapf(err, "invalid %s", name)
+	}
+	return nil
+}
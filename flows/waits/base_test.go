@@ -2,11 +2,25 @@ package waits_test
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions/modifiers"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/flows/waits"
+	"github.com/nyaruka/goflow/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadWait(t *testing.T) {
@@ -38,4 +52,173 @@ func TestReadWait(t *testing.T) {
 	data, err = json.Marshal(wait)
 	assert.NoError(t, err)
 	assert.Equal(t, `{"type":"msg","hint":{"type":"image"}}`, string(data))
+
+	// read dial wait without limits
+	wait, err = waits.ReadWait([]byte(`{"type": "dial", "phone": "@contact.fields.agent_phone"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "dial", wait.Type())
+	assert.Equal(t, "@contact.fields.agent_phone", wait.(*waits.DialWait).Phone())
+	assert.Equal(t, 60, wait.(*waits.DialWait).DialLimitSeconds())
+	assert.Equal(t, 7200, wait.(*waits.DialWait).CallLimitSeconds())
+
+	// marshal back to JSON
+	data, err = json.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"dial","phone":"@contact.fields.agent_phone","dial_limit_seconds":60,"call_limit_seconds":7200}`, string(data))
+
+	// read external event wait
+	wait, err = waits.ReadWait([]byte(`{"type": "external_event", "event": "payment_confirmed", "key": "ORD-@contact.id", "result_name": "Payment", "timeout": 3600}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "external_event", wait.Type())
+	assert.Equal(t, "payment_confirmed", wait.(*waits.ExternalEventWait).Event())
+	assert.Equal(t, "ORD-@contact.id", wait.(*waits.ExternalEventWait).Key())
+	assert.Equal(t, "Payment", wait.(*waits.ExternalEventWait).ResultName())
+	assert.Equal(t, "", wait.(*waits.ExternalEventWait).CorrelationKey())
+
+	// marshal back to JSON
+	data, err = json.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"external_event","timeout":3600,"event":"payment_confirmed","key":"ORD-@contact.id","result_name":"Payment"}`, string(data))
+
+	// error if external event wait has no key
+	_, err = waits.ReadWait([]byte(`{"type": "external_event", "event": "payment_confirmed"}`))
+	assert.EqualError(t, err, "field 'key' is required")
+
+	// read ticket wait
+	wait, err = waits.ReadWait([]byte(`{"type": "ticket", "timeout": 86400}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "ticket", wait.Type())
+	assert.Equal(t, flows.TicketUUID(""), wait.(*waits.TicketWait).TicketUUID())
+
+	// marshal back to JSON
+	data, err = json.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"ticket","timeout":86400}`, string(data))
+
+	// error if dial wait has no phone
+	_, err = waits.ReadWait([]byte(`{"type": "dial"}`))
+	assert.EqualError(t, err, "field 'phone' is required")
+}
+
+var rerouteWaitJSON = `{
+	"flows": [
+		{
+			"uuid": "0c9a8b7d-6e5f-4a3b-9c2d-1e0f9a8b7c6d",
+			"name": "Upgrade",
+			"spec_version": "12.0",
+			"language": "eng",
+			"type": "messaging",
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"wait": {"type": "msg"},
+					"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1", "destination_node_uuid": "1d2e3f4a-5b6c-4d7e-8f9a-0b1c2d3e4f5a"}]
+				},
+				{
+					"uuid": "1d2e3f4a-5b6c-4d7e-8f9a-0b1c2d3e4f5a",
+					"wait": {"type": "msg", "reroute_on_contact_change": true},
+					"router": {
+						"type": "switch",
+						"default_exit_uuid": "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e",
+						"operand": "@contact",
+						"cases": [
+							{
+								"uuid": "3f4a5b6c-7d8e-4f9a-8b1c-2d3e4f5a6b7c",
+								"type": "has_group",
+								"arguments": ["a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"],
+								"exit_uuid": "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d"
+							}
+						]
+					},
+					"exits": [
+						{"uuid": "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d", "name": "VIP"},
+						{"uuid": "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e", "name": "Other"}
+					]
+				}
+			]
+		}
+	],
+	"groups": [
+		{"uuid": "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "name": "VIP"},
+		{"uuid": "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e", "name": "Newsletter"}
+	]
+}`
+
+func TestRerouteOnContactChange(t *testing.T) {
+	source, err := static.NewSource([]byte(rerouteWaitJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("0c9a8b7d-6e5f-4a3b-9c2d-1e0f9a8b7c6d")
+	require.NoError(t, err)
+	vip := sessionAssets.Groups().Get("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d")
+	newsletter := sessionAssets.Groups().Get("b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e")
+
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", nil)
+
+	session := engine.NewBuilder().Build().NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	// get to the wait which re-routes with a message as the session's input
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+12065551212"), nil, "hi there", nil)
+	_, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.NotNil(t, session.Input())
+
+	// a change which doesn't match the router keeps us waiting without losing that input
+	mod := modifiers.NewGroupsModifier([]*flows.Group{newsletter}, modifiers.GroupsAdd)
+	_, err = session.Resume(resumes.NewContactChangedResume(nil, nil, []flows.Modifier{mod}))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.NotNil(t, session.Input())
+	assert.Equal(t, "msg", session.Input().Type())
+
+	// whereas one which does, re-routes and clears the input
+	mod = modifiers.NewGroupsModifier([]*flows.Group{vip}, modifiers.GroupsAdd)
+	_, err = session.Resume(resumes.NewContactChangedResume(nil, nil, []flows.Modifier{mod}))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Nil(t, session.Input())
+	assert.Equal(t, flows.ExitUUID("4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d"), session.Runs()[0].Path()[1].ExitUUID())
+}
+
+func TestDialWaitTimeout(t *testing.T) {
+	assetsJSON, err := ioutil.ReadFile("../../test/testdata/flows/dial.json")
+	require.NoError(t, err)
+
+	// give the dial wait a timeout
+	withTimeout := strings.Replace(string(assetsJSON), `"dial_limit_seconds": 30,`, `"dial_limit_seconds": 30, "timeout": 60,`, 1)
+
+	source, err := static.NewSource([]byte(withTimeout))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("a1a1a0cf-2b43-4d4a-9a3e-6b6ac6f3d2c1")
+	require.NoError(t, err)
+
+	clock := &testClock{now: time.Date(2018, 4, 11, 13, 24, 30, 0, time.UTC)}
+	eng := engine.NewBuilder().WithTimeSource(clock).Build()
+
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", nil)
+	connection := flows.NewConnection(assets.NewChannelReference("57f1078f-88aa-46f4-a59a-948a5739c03d", "Nexmo"), urns.URN("tel:+12065551212"))
+
+	session := eng.NewSession(sessionAssets)
+	_, err = session.Start(triggers.NewManualVoiceTrigger(nil, flow.Reference(), contact, connection, nil))
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.NotNil(t, session.Wait().TimeoutOn())
+
+	// once the timeout has passed, the wait can be ended by it
+	clock.now = clock.now.Add(time.Minute * 2)
+
+	sprint, err := session.Resume(resumes.NewWaitTimeoutResume(nil, nil))
+	require.NoError(t, err)
+	for _, e := range sprint.Events() {
+		assert.NotEqual(t, events.TypeError, e.Type(), "unexpected error event")
+	}
+	assert.Equal(t, events.TypeWaitTimedOut, sprint.Events()[0].Type())
 }
@@ -0,0 +1,154 @@
+package waits
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeDial, readDialWait)
+}
+
+// TypeDial is the type of our dial wait
+const TypeDial string = "dial"
+
+const (
+	defaultDialLimitSeconds = 60
+	defaultCallLimitSeconds = 7200
+)
+
+// DialWait is a wait which waits for the caller in a voice flow to be forwarded to another phone number (i.e. a
+// dial resume)
+type DialWait struct {
+	baseWait
+
+	// the phone number to dial, which can be a template
+	phone string
+
+	// how long to wait for the dialed number to answer, and how long the forwarded call can last
+	dialLimitSeconds int
+	callLimitSeconds int
+}
+
+// NewDialWait creates a new dial wait
+func NewDialWait(phone string, dialLimitSeconds, callLimitSeconds int) *DialWait {
+	return &DialWait{
+		baseWait:         newBaseWait(TypeDial, nil),
+		phone:            phone,
+		dialLimitSeconds: dialLimitSeconds,
+		callLimitSeconds: callLimitSeconds,
+	}
+}
+
+// Phone returns the phone number to dial
+func (w *DialWait) Phone() string { return w.phone }
+
+// DialLimitSeconds returns how long to wait for the dialed number to answer
+func (w *DialWait) DialLimitSeconds() int { return w.dialLimitSeconds }
+
+// CallLimitSeconds returns how long the forwarded call can last
+func (w *DialWait) CallLimitSeconds() int { return w.callLimitSeconds }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *DialWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeVoice}
+}
+
+// Validate checks that this wait's expressions are valid
+func (w *DialWait) Validate(exits []flows.Exit) error {
+	if err := w.baseWait.Validate(exits); err != nil {
+		return err
+	}
+	return validateTemplate(w.phone, "phone")
+}
+
+// Begin beings waiting at this wait
+func (w *DialWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
+	phone, err := run.EvaluateTemplate(w.phone)
+	if err != nil {
+		log(events.NewErrorEvent(err))
+	}
+
+	urn, err := urns.NewTelURNForCountry(phone, string(run.Environment().DefaultCountry()))
+	if err != nil {
+		log(events.NewErrorEvent(errors.Wrapf(err, "unable to dial '%s'", phone)))
+		return false
+	}
+
+	if !w.baseWait.Begin(run, log) {
+		return false
+	}
+
+	log(events.NewDialWaitEvent(urn, w.dialLimitSeconds, w.callLimitSeconds))
+	return true
+}
+
+// End ends this wait or returns an error
+func (w *DialWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
+	switch resume.Type() {
+	case resumes.TypeDial:
+		return nil
+	case resumes.TypeRunExpiration, resumes.TypeWaitTimeout, resumes.TypeContactChanged:
+		return w.baseWait.End(run, resume, node)
+	}
+
+	return errors.Errorf("can't end a dial wait with a %s resume", resume.Type())
+}
+
+var _ flows.Wait = (*DialWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type dialWaitEnvelope struct {
+	baseWaitEnvelope
+
+	Phone            string `json:"phone" validate:"required"`
+	DialLimitSeconds int    `json:"dial_limit_seconds,omitempty" validate:"omitempty,min=0"`
+	CallLimitSeconds int    `json:"call_limit_seconds,omitempty" validate:"omitempty,min=0"`
+}
+
+func readDialWait(data json.RawMessage) (flows.Wait, error) {
+	e := &dialWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &DialWait{
+		phone:            e.Phone,
+		dialLimitSeconds: e.DialLimitSeconds,
+		callLimitSeconds: e.CallLimitSeconds,
+	}
+
+	if w.dialLimitSeconds == 0 {
+		w.dialLimitSeconds = defaultDialLimitSeconds
+	}
+	if w.callLimitSeconds == 0 {
+		w.callLimitSeconds = defaultCallLimitSeconds
+	}
+
+	return w, w.unmarshal(&e.baseWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *DialWait) MarshalJSON() ([]byte, error) {
+	e := &dialWaitEnvelope{
+		Phone:            w.phone,
+		DialLimitSeconds: w.dialLimitSeconds,
+		CallLimitSeconds: w.callLimitSeconds,
+	}
+
+	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,154 @@
+package waits
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeExternalEvent, readExternalEventWait)
+}
+
+// TypeExternalEvent is the type of our external event wait
+const TypeExternalEvent string = "external_event"
+
+// ExternalEventWait is a wait which waits for a named event from a third party, such as a payment confirmation, which
+// is correlated with this session by a key (i.e. an external event resume). If the wait has a result name, the event is
+// saved as a result with that name.
+type ExternalEventWait struct {
+	baseWait
+
+	// the name of the event to wait for
+	event string
+
+	// the name of the result the event is saved as (optional)
+	resultName string
+
+	// the template used to build the correlation key, and its value when we began waiting
+	key            string
+	correlationKey string
+}
+
+// NewExternalEventWait creates a new external event wait
+func NewExternalEventWait(event string, key string, resultName string, timeout *int) *ExternalEventWait {
+	return &ExternalEventWait{
+		baseWait:   newBaseWait(TypeExternalEvent, timeout),
+		event:      event,
+		key:        key,
+		resultName: resultName,
+	}
+}
+
+// Event returns the name of the event to wait for
+func (w *ExternalEventWait) Event() string { return w.event }
+
+// ResultName returns the name of the result the event is saved as (optional)
+func (w *ExternalEventWait) ResultName() string { return w.resultName }
+
+// Key returns the template used to build the correlation key
+func (w *ExternalEventWait) Key() string { return w.key }
+
+// CorrelationKey returns the correlation key which the event must have to end this wait
+func (w *ExternalEventWait) CorrelationKey() string { return w.correlationKey }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *ExternalEventWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeVoice}
+}
+
+// Validate checks that this wait's expressions are valid
+func (w *ExternalEventWait) Validate(exits []flows.Exit) error {
+	if err := w.baseWait.Validate(exits); err != nil {
+		return err
+	}
+	return validateTemplate(w.key, "key")
+}
+
+// Begin beings waiting at this wait
+func (w *ExternalEventWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
+	key, err := run.EvaluateTemplate(w.key)
+	if err != nil {
+		log(events.NewErrorEvent(err))
+	}
+	if key == "" {
+		log(events.NewErrorEvent(errors.Errorf("correlation key for external event '%s' evaluated to empty string", w.event)))
+		return false
+	}
+
+	if !w.baseWait.Begin(run, log) {
+		return false
+	}
+
+	w.correlationKey = key
+
+	log(events.NewExternalEventWaitEvent(w.event, w.correlationKey, w.timeoutOn))
+	return true
+}
+
+// End ends this wait or returns an error
+func (w *ExternalEventWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
+	switch typed := resume.(type) {
+	case *resumes.ExternalEventResume:
+		if typed.Event() != w.event || typed.Key() != w.correlationKey {
+			return errors.Errorf("external event '%s' with key '%s' doesn't match wait for '%s' with key '%s'", typed.Event(), typed.Key(), w.event, w.correlationKey)
+		}
+		return nil
+	case *resumes.RunExpirationResume, *resumes.WaitTimeoutResume, *resumes.ContactChangedResume:
+		return w.baseWait.End(run, resume, node)
+	}
+
+	return errors.Errorf("can't end an external event wait with a %s resume", resume.Type())
+}
+
+var _ flows.Wait = (*ExternalEventWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type externalEventWaitEnvelope struct {
+	baseWaitEnvelope
+
+	Event          string `json:"event" validate:"required"`
+	Key            string `json:"key" validate:"required"`
+	ResultName     string `json:"result_name,omitempty"`
+	CorrelationKey string `json:"correlation_key,omitempty"`
+}
+
+func readExternalEventWait(data json.RawMessage) (flows.Wait, error) {
+	e := &externalEventWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &ExternalEventWait{
+		event:          e.Event,
+		key:            e.Key,
+		resultName:     e.ResultName,
+		correlationKey: e.CorrelationKey,
+	}
+
+	return w, w.unmarshal(&e.baseWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *ExternalEventWait) MarshalJSON() ([]byte, error) {
+	e := &externalEventWaitEnvelope{
+		Event:          w.event,
+		Key:            w.key,
+		ResultName:     w.resultName,
+		CorrelationKey: w.correlationKey,
+	}
+
+	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
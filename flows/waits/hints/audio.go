@@ -22,3 +22,8 @@ func NewAudioHint() *AudioHint {
 		baseHint: newBaseHint(TypeAudio),
 	}
 }
+
+// Accepts returns whether the given message has an audio attachment
+func (h *AudioHint) Accepts(msg *flows.MsgIn) bool {
+	return hasAttachmentOfType(msg, "audio")
+}
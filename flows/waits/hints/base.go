@@ -2,6 +2,7 @@ package hints
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/utils"
@@ -28,6 +29,17 @@ func newBaseHint(typeName string) baseHint {
 // Type returns the type of this hint
 func (h *baseHint) Type() string { return h.Type_ }
 
+// checks whether the given message has an attachment of the given media type, e.g. "image" matches "image/jpeg"
+func hasAttachmentOfType(msg *flows.MsgIn, mediaType string) bool {
+	for _, attachment := range msg.Attachments() {
+		contentType := attachment.ContentType()
+		if contentType == mediaType || strings.HasPrefix(contentType, mediaType+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/waits/hints"
+	"github.com/nyaruka/goflow/utils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -69,3 +72,38 @@ func TestReadHint(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"type":"digits","count":1}`, string(data))
 }
+
+func TestHintAccepts(t *testing.T) {
+	msg := func(text string, attachments ...flows.Attachment) *flows.MsgIn {
+		return flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+12065551212"), nil, text, attachments)
+	}
+
+	tcs := []struct {
+		hint    flows.Hint
+		msg     *flows.MsgIn
+		accepts bool
+	}{
+		{hints.NewFixedDigitsHint(4), msg("1234"), true},
+		{hints.NewFixedDigitsHint(4), msg(" 1234 "), true},
+		{hints.NewFixedDigitsHint(4), msg("123"), false},
+		{hints.NewFixedDigitsHint(4), msg("12a4"), false},
+		{hints.NewFixedDigitsHint(4), msg(""), false},
+		{hints.NewTerminatedDigitsHint("#"), msg("12345#"), true},
+		{hints.NewTerminatedDigitsHint("#"), msg("12345"), true},
+		{hints.NewTerminatedDigitsHint("#"), msg("#"), false},
+		{hints.NewImageHint(), msg("", "image/jpeg:http://s3.amazon.com/bucket/test.jpg"), true},
+		{hints.NewImageHint(), msg("", "image:http://s3.amazon.com/bucket/test.jpg"), true},
+		{hints.NewImageHint(), msg("", "imagery/foo:http://s3.amazon.com/bucket/test.jpg"), false},
+		{hints.NewImageHint(), msg("a photo"), false},
+		{hints.NewVideoHint(), msg("", "image/jpeg:http://s3.amazon.com/bucket/test.jpg", "video/mp4:http://s3.amazon.com/bucket/test.mp4"), true},
+		{hints.NewVideoHint(), msg("", "image/jpeg:http://s3.amazon.com/bucket/test.jpg"), false},
+		{hints.NewAudioHint(), msg("", "audio/mp3:http://s3.amazon.com/bucket/test.mp3"), true},
+		{hints.NewAudioHint(), msg("", "video/mp4:http://s3.amazon.com/bucket/test.mp4"), false},
+		{hints.NewLocationHint(), msg("", "geo:-2.90875,-79.0117686"), true},
+		{hints.NewLocationHint(), msg("Cuenca"), false},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.accepts, tc.hint.Accepts(tc.msg), "accepts mismatch for %s hint and msg '%s' %v", tc.hint.Type(), tc.msg.Text(), tc.msg.Attachments())
+	}
+}
@@ -1,6 +1,8 @@
 package hints
 
 import (
+	"strings"
+
 	"github.com/nyaruka/goflow/flows"
 )
 
@@ -34,3 +36,21 @@ func NewTerminatedDigitsHint(terminatedBy string) *DigitsHint {
 		TerminatedBy: terminatedBy,
 	}
 }
+
+// Accepts returns whether the given message contains only digits, of the right count if one is set. A trailing
+// terminator is ignored if the channel didn't already strip it.
+func (h *DigitsHint) Accepts(msg *flows.MsgIn) bool {
+	digits := strings.TrimSpace(msg.Text())
+	if h.TerminatedBy != "" {
+		digits = strings.TrimSuffix(digits, h.TerminatedBy)
+	}
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return h.Count == nil || len(digits) == *h.Count
+}
@@ -22,3 +22,8 @@ func NewImageHint() *ImageHint {
 		baseHint: newBaseHint(TypeImage),
 	}
 }
+
+// Accepts returns whether the given message has an image attachment
+func (h *ImageHint) Accepts(msg *flows.MsgIn) bool {
+	return hasAttachmentOfType(msg, "image")
+}
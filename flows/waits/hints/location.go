@@ -22,3 +22,8 @@ func NewLocationHint() *LocationHint {
 		baseHint: newBaseHint(TypeLocation),
 	}
 }
+
+// Accepts returns whether the given message has a location attachment
+func (h *LocationHint) Accepts(msg *flows.MsgIn) bool {
+	return hasAttachmentOfType(msg, "geo")
+}
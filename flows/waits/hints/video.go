@@ -22,3 +22,8 @@ func NewVideoHint() *VideoHint {
 		baseHint: newBaseHint(TypeVideo),
 	}
 }
+
+// Accepts returns whether the given message has a video attachment
+func (h *VideoHint) Accepts(msg *flows.MsgIn) bool {
+	return hasAttachmentOfType(msg, "video")
+}
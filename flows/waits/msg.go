@@ -2,7 +2,10 @@ package waits
 
 import (
 	"encoding/json"
+	"strings"
 
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/resumes"
@@ -20,6 +23,19 @@ func init() {
 // TypeMsg is the type of our message wait
 const TypeMsg string = "msg"
 
+// HintEnforcement describes how a message wait enforces its hint, i.e. how many times the contact is re-prompted after
+// a reply which doesn't satisfy the hint, and which exit is taken once those retries have been used up
+type HintEnforcement struct {
+	Retries      int            `json:"retries" validate:"min=0"`
+	RetryMessage string         `json:"retry_message" validate:"required"`
+	ExitUUID     flows.ExitUUID `json:"exit_uuid" validate:"required,uuid4"`
+}
+
+// NewHintEnforcement creates a new hint enforcement
+func NewHintEnforcement(retries int, retryMessage string, exitUUID flows.ExitUUID) *HintEnforcement {
+	return &HintEnforcement{Retries: retries, RetryMessage: retryMessage, ExitUUID: exitUUID}
+}
+
 // MsgWait is a wait which waits for an incoming message (i.e. a msg_received event)
 type MsgWait struct {
 	baseWait
@@ -27,8 +43,12 @@ type MsgWait struct {
 	// Message waits can indicate to the caller what kind of message the flow is expecting. In the case of flows of type
 	// messaging_offline, this should be considered a requirement and the client should only reply with a message containing
 	// an attachment of that type. In the case of other flow types this should be considered only a hint to the channel,
-	// which may or may not support prompting the contact for media of that type.
-	hint flows.Hint
+	// which may or may not support prompting the contact for media of that type, unless the wait also enforces its hint.
+	hint        flows.Hint
+	enforcement *HintEnforcement
+
+	// how many replies have been rejected since this wait began
+	rejections int
 }
 
 // NewMsgWait creates a new message wait
@@ -39,12 +59,61 @@ func NewMsgWait(timeout *int, hint flows.Hint) *MsgWait {
 	}
 }
 
+// NewEnforcedMsgWait creates a new message wait which rejects replies that don't satisfy its hint
+func NewEnforcedMsgWait(timeout *int, hint flows.Hint, enforcement *HintEnforcement) *MsgWait {
+	return &MsgWait{
+		baseWait:    newBaseWait(TypeMsg, timeout),
+		hint:        hint,
+		enforcement: enforcement,
+	}
+}
+
 // Hint returns the hint (optional)
 func (w *MsgWait) Hint() flows.Hint { return w.hint }
 
+// Enforcement returns how the hint is enforced (optional)
+func (w *MsgWait) Enforcement() *HintEnforcement { return w.enforcement }
+
+// Rejections returns how many replies have been rejected since this wait began
+func (w *MsgWait) Rejections() int { return w.rejections }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *MsgWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeMessagingOffline, flows.FlowTypeVoice}
+}
+
+// Validate checks that this wait's expressions and enforcement exit are valid
+func (w *MsgWait) Validate(exits []flows.Exit) error {
+	if err := w.baseWait.Validate(exits); err != nil {
+		return err
+	}
+
+	if w.enforcement != nil {
+		if w.hint == nil {
+			return errors.New("can't enforce a wait without a hint")
+		}
+
+		hasExit := false
+		for _, e := range exits {
+			if e.UUID() == w.enforcement.ExitUUID {
+				hasExit = true
+				break
+			}
+		}
+		if !hasExit {
+			return errors.Errorf("enforcement exit %s is not a valid exit", w.enforcement.ExitUUID)
+		}
+
+		return validateTemplate(w.enforcement.RetryMessage, "retry_message")
+	}
+	return nil
+}
+
 // Begin beings waiting at this wait
 func (w *MsgWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
-	if !w.baseWait.Begin(run) {
+	w.rejections = 0
+
+	if !w.baseWait.Begin(run, log) {
 		return false
 	}
 
@@ -60,16 +129,88 @@ func (w *MsgWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
 }
 
 // End ends this wait or returns an error
-func (w *MsgWait) End(resume flows.Resume, node flows.Node) error {
-	// if we have a message we can definitely resume
-	if resume.Type() == resumes.TypeMsg {
+func (w *MsgWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
+	switch resume.Type() {
+	case resumes.TypeMsg:
+		// if we have a message we can definitely resume
 		return nil
+	case resumes.TypeRunExpiration, resumes.TypeWaitTimeout, resumes.TypeContactChanged:
+		return w.baseWait.End(run, resume, node)
+	}
+
+	return errors.Errorf("can't end a message wait with a %s resume", resume.Type())
+}
+
+// ValidateResume checks that a message resume satisfies our hint if we enforce it. If it doesn't, we either re-prompt
+// the contact and keep waiting, or if there are no retries remaining, route to the enforcement exit.
+func (w *MsgWait) ValidateResume(run flows.FlowRun, resume flows.Resume, node flows.Node, log flows.EventCallback) (bool, flows.Route) {
+	msgResume, isMsg := resume.(*resumes.MsgResume)
+	if !isMsg {
+		return w.baseWait.ValidateResume(run, resume, node, log)
+	}
+	if w.enforcement == nil || w.hint == nil || w.hint.Accepts(msgResume.Msg()) {
+		return false, flows.NoRoute
+	}
+
+	w.rejections++
+	retriesRemaining := w.enforcement.Retries - w.rejections
+	if retriesRemaining < 0 {
+		log(events.NewMsgRejectedEvent(w.hint.Type(), 0))
+
+		return false, flows.NewRoute(w.enforcement.ExitUUID, msgResume.Msg().Text(), nil)
 	}
 
-	return w.baseWait.End(resume, node)
+	log(events.NewMsgRejectedEvent(w.hint.Type(), retriesRemaining))
+
+	w.reprompt(run, node, log)
+
+	log(events.NewMsgWait(w.timeoutOn))
+	return true, flows.NoRoute
 }
 
-var _ flows.Wait = (*MsgWait)(nil)
+// sends the localized retry message to the contact
+func (w *MsgWait) reprompt(run flows.FlowRun, node flows.Node, log flows.EventCallback) {
+	localizedText := run.GetText(utils.UUID(node.UUID()), "retry_message", w.enforcement.RetryMessage)
+	evaluatedText, err := run.EvaluateTemplate(localizedText)
+	if err != nil {
+		log(events.NewErrorEvent(err))
+	}
+	evaluatedText = strings.TrimSpace(evaluatedText)
+	if evaluatedText == "" {
+		return
+	}
+
+	// in a voice flow the prompt is said to the caller
+	if run.Flow().Type() == flows.FlowTypeVoice {
+		connection := run.Session().Trigger().Connection()
+		log(events.NewIVRCreatedEvent(flows.NewMsgOut(connection.URN(), connection.Channel(), evaluatedText, nil, nil, nil)))
+		return
+	}
+
+	// otherwise it's sent to the contact's preferred URN and channel they've consented to, or it's up to the caller
+	// to handle a message without a URN or channel
+	urn := urns.NilURN
+	var channelRef *assets.ChannelReference
+
+	if run.Contact() != nil {
+		destinations, unconsented := run.Contact().ResolveConsentedDestinations(false)
+
+		for _, dest := range unconsented {
+			log(events.NewErrorEventf("contact hasn't consented to messages on %s", dest.Channel.Reference()))
+		}
+
+		if len(destinations) > 0 {
+			urn = destinations[0].URN.URN()
+			channelRef = assets.NewChannelReference(destinations[0].Channel.UUID(), destinations[0].Channel.Name())
+		} else if len(unconsented) > 0 {
+			return
+		}
+	}
+
+	log(events.NewMsgCreatedEvent(flows.NewMsgOut(urn, channelRef, evaluatedText, nil, nil, nil)))
+}
+
+var _ flows.WaitWithValidation = (*MsgWait)(nil)
 
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
@@ -78,7 +219,9 @@ var _ flows.Wait = (*MsgWait)(nil)
 type msgWaitEnvelope struct {
 	baseWaitEnvelope
 
-	Hint json.RawMessage `json:"hint,omitempty"`
+	Hint        json.RawMessage  `json:"hint,omitempty"`
+	Enforcement *HintEnforcement `json:"enforcement,omitempty"`
+	Rejections  int              `json:"rejections,omitempty"`
 }
 
 func readMsgWait(data json.RawMessage) (flows.Wait, error) {
@@ -87,7 +230,7 @@ func readMsgWait(data json.RawMessage) (flows.Wait, error) {
 		return nil, err
 	}
 
-	w := &MsgWait{}
+	w := &MsgWait{enforcement: e.Enforcement, rejections: e.Rejections}
 
 	var err error
 	if e.Hint != nil {
@@ -101,7 +244,7 @@ func readMsgWait(data json.RawMessage) (flows.Wait, error) {
 
 // MarshalJSON marshals this wait into JSON
 func (w *MsgWait) MarshalJSON() ([]byte, error) {
-	e := &msgWaitEnvelope{}
+	e := &msgWaitEnvelope{Enforcement: w.enforcement, Rejections: w.rejections}
 
 	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
 		return nil, err
@@ -3,9 +3,16 @@ package waits_test
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
 	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/flows/waits"
 	"github.com/nyaruka/goflow/flows/waits/hints"
@@ -53,6 +60,32 @@ func TestMsgWait(t *testing.T) {
 	wait = waits.NewMsgWait(&timeout, hints.NewImageHint())
 	marshaled, _ = json.Marshal(wait)
 	assert.Equal(t, `{"type":"msg","timeout":5,"hint":{"type":"image"}}`, string(marshaled))
+
+	// enforced digits hint
+	wait = waits.NewEnforcedMsgWait(nil, hints.NewFixedDigitsHint(4), waits.NewHintEnforcement(2, "Please enter 4 digits", "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"))
+	marshaled, _ = json.Marshal(wait)
+	assert.Equal(t, `{"type":"msg","hint":{"type":"digits","count":4},"enforcement":{"retries":2,"retry_message":"Please enter 4 digits","exit_uuid":"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"}}`, string(marshaled))
+
+	exits := []flows.Exit{
+		definition.NewExit("5d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f8a", "", "Valid"),
+		definition.NewExit("9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", "", "Invalid"),
+	}
+	assert.NoError(t, wait.Validate(exits))
+
+	// enforcement exit must be one of the node's exits
+	assert.EqualError(t, wait.Validate(exits[:1]), "enforcement exit 9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d is not a valid exit")
+
+	// can't enforce without a hint
+	wait = waits.NewEnforcedMsgWait(nil, nil, waits.NewHintEnforcement(2, "Please enter 4 digits", "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"))
+	assert.EqualError(t, wait.Validate(exits), "can't enforce a wait without a hint")
+
+	// retry message must be a valid template
+	wait = waits.NewEnforcedMsgWait(nil, hints.NewImageHint(), waits.NewHintEnforcement(2, "Please send a photo @(upper(, ))", "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"))
+	assert.EqualError(t, wait.Validate(exits), "invalid retry_message: error evaluating @(upper(, )): syntax error at , )")
+
+	// enforcement requires a retry message and exit
+	_, err := waits.ReadWait([]byte(`{"type": "msg", "hint": {"type": "image"}, "enforcement": {"retries": 1}}`))
+	assert.EqualError(t, err, "field 'enforcement.retry_message' is required, field 'enforcement.exit_uuid' is required")
 }
 
 func TestMsgWaitSkipIfInitial(t *testing.T) {
@@ -70,6 +103,12 @@ func TestMsgWaitSkipIfInitial(t *testing.T) {
 	assert.Equal(t, 1, len(sprint.Events()))
 	assert.Equal(t, "msg_wait", sprint.Events()[0].Type())
 
+	// only a msg resume or one of the resumes any wait accepts can end it
+	sprint, err = session.Resume(resumes.NewDialResume(env, nil, flows.NewDial(flows.DialStatusAnswered, 10)))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, "can't end a message wait with a dial resume", sprint.Events()[0].(*events.ErrorEvent).Text)
+
 	session, flow = initializeSession(t)
 
 	// whereas a msg trigger will skip over it
@@ -93,3 +132,119 @@ func initializeSession(t *testing.T) (flows.Session, flows.Flow) {
 
 	return session, flow
 }
+
+var msgWaitTimeoutsJSON = `{
+	"flows": [
+		{
+			"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4",
+			"name": "Reminders",
+			"spec_version": "12.0",
+			"language": "eng",
+			"type": "messaging",
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"wait": {
+						"type": "msg",
+						"timeout_expression": "@(datetime_diff(now(), contact.fields.appointment, \"s\") - 86400)",
+						"quiet_hours": {"start": "21:00", "end": "08:00"}
+					},
+					"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+				}
+			]
+		}
+	],
+	"fields": [
+		{"key": "appointment", "name": "Appointment", "type": "datetime"}
+	]
+}`
+
+func TestMsgWaitTimeouts(t *testing.T) {
+	source, err := static.NewSource([]byte(msgWaitTimeoutsJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+
+	contact, err := flows.ReadContact(sessionAssets, json.RawMessage(`{
+		"uuid": "5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f",
+		"name": "Bob",
+		"language": "eng",
+		"timezone": "America/Guayaquil",
+		"created_on": "2018-01-01T00:00:00Z",
+		"fields": {
+			"appointment": {"text": "2018-04-13T02:30:00Z", "datetime": "2018-04-13T02:30:00Z"}
+		}
+	}`), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	start := func(now time.Time) (flows.Session, flows.Sprint) {
+		eng := engine.NewBuilder().WithTimeSource(&testClock{now: now}).Build()
+		session := eng.NewSession(sessionAssets)
+		sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact.Clone(), nil))
+		require.NoError(t, err)
+		return session, sprint
+	}
+
+	// a day before the appointment is 9:30pm in the contact's timezone so the timeout is pushed back to 8am
+	session, sprint := start(time.Date(2018, 4, 10, 15, 0, 0, 0, time.UTC))
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, time.Date(2018, 4, 12, 13, 0, 0, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+
+	// the effective timeout is recorded in the event and the session
+	require.Equal(t, 1, len(sprint.Events()))
+	assert.Equal(t, session.Wait().TimeoutOn(), sprint.Events()[0].(*events.MsgWaitEvent).TimeoutOn)
+
+	marshaled, err := json.Marshal(session.Wait())
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"msg","timeout_expression":"@(datetime_diff(now(), contact.fields.appointment, \"s\") - 86400)","quiet_hours":{"start":"21:00","end":"08:00"},"timeout_on":"2018-04-12T08:00:00-05:00"}`, string(marshaled))
+
+	// if the expression doesn't evaluate to a number, we log an error and wait without a timeout
+	contact.Fields().Clear(sessionAssets.Fields().Get("appointment"))
+	session, sprint = start(time.Date(2018, 4, 10, 15, 0, 0, 0, time.UTC))
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Nil(t, session.Wait().TimeoutOn())
+	require.Equal(t, 2, len(sprint.Events()))
+	assert.Equal(t, "error", sprint.Events()[0].Type())
+	assert.Equal(t, "msg_wait", sprint.Events()[1].Type())
+
+	// a wait can't have both kinds of timeout
+	_, err = waits.ReadWait([]byte(`{"type": "msg", "timeout": 60, "timeout_expression": "@(60)"}`))
+	assert.EqualError(t, err, "wait can't have both a timeout and a timeout expression")
+}
+
+func TestMsgWaitRejectionsPerSession(t *testing.T) {
+	source, err := static.LoadSource("../../test/testdata/flows/enforced_hint.json")
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("0b5c2d1e-6f3a-4e8b-9c7d-1a2b3c4d5e6f")
+	require.NoError(t, err)
+
+	eng := engine.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", nil)
+
+	// two sessions waiting on the same node of the same flow
+	session1 := eng.NewSession(sessionAssets)
+	_, err = session1.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	session2 := eng.NewSession(sessionAssets)
+	_, err = session2.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	// a rejected reply in one session only counts against that session
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+12065551212"), nil, "12", nil)
+	_, err = session1.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, session1.Wait().(*waits.MsgWait).Rejections())
+	assert.Equal(t, 0, session2.Wait().(*waits.MsgWait).Rejections())
+	assert.Equal(t, 0, flow.Nodes()[0].Wait().(*waits.MsgWait).Rejections())
+
+	// and isn't kept as the session's resume
+	assert.Nil(t, session1.CurrentResume())
+}
@@ -0,0 +1,82 @@
+package waits
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// QuietHours is a period of each day, in the contact's timezone, during which a wait shouldn't time out. A timeout which
+// would fall within quiet hours is pushed back to when they end. The period can span midnight, e.g. 21:00 to 08:00.
+type QuietHours struct {
+	start utils.TimeOfDay
+	end   utils.TimeOfDay
+}
+
+// NewQuietHours creates new quiet hours
+func NewQuietHours(start, end utils.TimeOfDay) *QuietHours {
+	return &QuietHours{start: start, end: end}
+}
+
+// Start returns the time of day when quiet hours start
+func (q *QuietHours) Start() utils.TimeOfDay { return q.start }
+
+// End returns the time of day when quiet hours end
+func (q *QuietHours) End() utils.TimeOfDay { return q.end }
+
+// Adjust returns the given time, or if it falls within quiet hours in the given timezone, the time that they end
+func (q *QuietHours) Adjust(t time.Time, tz *time.Location) time.Time {
+	local := t.In(tz)
+	timeOfDay := utils.ExtractTimeOfDay(local)
+	today := utils.ExtractDate(local)
+
+	if q.start.Compare(q.end) <= 0 {
+		// quiet hours within a single day
+		if timeOfDay.Compare(q.start) >= 0 && timeOfDay.Compare(q.end) < 0 {
+			return q.end.Combine(today, tz)
+		}
+	} else {
+		// quiet hours which span midnight
+		if timeOfDay.Compare(q.start) >= 0 {
+			return q.end.Combine(utils.ExtractDate(local.AddDate(0, 0, 1)), tz)
+		}
+		if timeOfDay.Compare(q.end) < 0 {
+			return q.end.Combine(today, tz)
+		}
+	}
+	return t
+}
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type quietHoursEnvelope struct {
+	Start string `json:"start" validate:"required"`
+	End   string `json:"end" validate:"required"`
+}
+
+// UnmarshalJSON unmarshals quiet hours from JSON
+func (q *QuietHours) UnmarshalJSON(data []byte) error {
+	e := &quietHoursEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return err
+	}
+
+	var err error
+	if q.start, err = utils.TimeFromString(e.Start); err != nil {
+		return errors.Wrap(err, "invalid quiet hours start")
+	}
+	if q.end, err = utils.TimeFromString(e.End); err != nil {
+		return errors.Wrap(err, "invalid quiet hours end")
+	}
+	return nil
+}
+
+// MarshalJSON marshals these quiet hours into JSON
+func (q *QuietHours) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&quietHoursEnvelope{Start: q.start.Format("15:04"), End: q.end.Format("15:04")})
+}
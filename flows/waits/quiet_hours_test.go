@@ -0,0 +1,53 @@
+package waits_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/goflow/flows/waits"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHours(t *testing.T) {
+	tz, _ := time.LoadLocation("America/Guayaquil")
+
+	overnight := waits.NewQuietHours(utils.NewTimeOfDay(21, 0, 0, 0), utils.NewTimeOfDay(8, 0, 0, 0))
+	daytime := waits.NewQuietHours(utils.NewTimeOfDay(12, 0, 0, 0), utils.NewTimeOfDay(14, 0, 0, 0))
+
+	tcs := []struct {
+		quietHours *waits.QuietHours
+		time       time.Time
+		adjusted   time.Time
+	}{
+		{overnight, time.Date(2018, 4, 11, 15, 0, 0, 0, tz), time.Date(2018, 4, 11, 15, 0, 0, 0, tz)},
+		{overnight, time.Date(2018, 4, 11, 21, 0, 0, 0, tz), time.Date(2018, 4, 12, 8, 0, 0, 0, tz)},
+		{overnight, time.Date(2018, 4, 11, 23, 30, 0, 0, tz), time.Date(2018, 4, 12, 8, 0, 0, 0, tz)},
+		{overnight, time.Date(2018, 4, 12, 3, 0, 0, 0, tz), time.Date(2018, 4, 12, 8, 0, 0, 0, tz)},
+		{overnight, time.Date(2018, 4, 12, 8, 0, 0, 0, tz), time.Date(2018, 4, 12, 8, 0, 0, 0, tz)},
+		{daytime, time.Date(2018, 4, 11, 11, 59, 0, 0, tz), time.Date(2018, 4, 11, 11, 59, 0, 0, tz)},
+		{daytime, time.Date(2018, 4, 11, 13, 0, 0, 0, tz), time.Date(2018, 4, 11, 14, 0, 0, 0, tz)},
+
+		// quiet hours are in the given timezone regardless of the timezone of the time
+		{overnight, time.Date(2018, 4, 12, 8, 0, 0, 0, time.UTC), time.Date(2018, 4, 12, 8, 0, 0, 0, tz)},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.adjusted.UTC(), tc.quietHours.Adjust(tc.time, tz).UTC(), "adjust mismatch for %s", tc.time)
+	}
+
+	// can be read from and written to JSON
+	quietHours := &waits.QuietHours{}
+	require.NoError(t, json.Unmarshal([]byte(`{"start": "9:30pm", "end": "07:15"}`), quietHours))
+	assert.Equal(t, utils.NewTimeOfDay(21, 30, 0, 0), quietHours.Start())
+	assert.Equal(t, utils.NewTimeOfDay(7, 15, 0, 0), quietHours.End())
+
+	marshaled, err := json.Marshal(quietHours)
+	require.NoError(t, err)
+	assert.Equal(t, `{"start":"21:30","end":"07:15"}`, string(marshaled))
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"start": "xx", "end": "07:15"}`), quietHours), "invalid quiet hours start: string 'xx' couldn't be parsed as a time")
+}
@@ -0,0 +1,125 @@
+package waits
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeTicket, readTicketWait)
+}
+
+// TypeTicket is the type of our ticket wait
+const TypeTicket string = "ticket"
+
+// TicketWait is a wait which waits for an agent to close the contact's most recently opened ticket (i.e. a ticket closed
+// resume). Messages from the contact don't end the wait as they're for the agent.
+type TicketWait struct {
+	baseWait
+
+	// the ticket we began waiting on
+	ticketUUID flows.TicketUUID
+}
+
+// NewTicketWait creates a new ticket wait
+func NewTicketWait(timeout *int) *TicketWait {
+	return &TicketWait{baseWait: newBaseWait(TypeTicket, timeout)}
+}
+
+// TicketUUID returns the UUID of the ticket which must be closed to end this wait
+func (w *TicketWait) TicketUUID() flows.TicketUUID { return w.ticketUUID }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *TicketWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeVoice}
+}
+
+// Begin beings waiting at this wait
+func (w *TicketWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
+	var ticket *flows.Ticket
+	if run.Contact() != nil {
+		ticket = run.Contact().Tickets().LastOpen()
+	}
+	if ticket == nil {
+		log(events.NewErrorEventf("contact has no open ticket to wait on"))
+		return false
+	}
+
+	if !w.baseWait.Begin(run, log) {
+		return false
+	}
+
+	w.ticketUUID = ticket.UUID
+
+	log(events.NewTicketWaitEvent(w.ticketUUID, w.timeoutOn))
+	return true
+}
+
+// End ends this wait or returns an error
+func (w *TicketWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
+	switch resume.Type() {
+	case resumes.TypeMsg, resumes.TypeTicketClosed:
+		// messages and the closing of other tickets are accepted but ValidateResume keeps us waiting
+		return nil
+	case resumes.TypeRunExpiration, resumes.TypeWaitTimeout, resumes.TypeContactChanged:
+		return w.baseWait.End(run, resume, node)
+	}
+
+	return errors.Errorf("can't end a ticket wait with a %s resume", resume.Type())
+}
+
+// ValidateResume keeps us waiting if we're resumed with a message for the agent, or because a ticket other than the
+// one we're waiting on was closed
+func (w *TicketWait) ValidateResume(run flows.FlowRun, resume flows.Resume, node flows.Node, log flows.EventCallback) (bool, flows.Route) {
+	switch typed := resume.(type) {
+	case *resumes.MsgResume:
+		return true, flows.NoRoute
+	case *resumes.TicketClosedResume:
+		return typed.Ticket().UUID != w.ticketUUID, flows.NoRoute
+	}
+	return w.baseWait.ValidateResume(run, resume, node, log)
+}
+
+var _ flows.WaitWithValidation = (*TicketWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type ticketWaitEnvelope struct {
+	baseWaitEnvelope
+
+	TicketUUID flows.TicketUUID `json:"ticket_uuid,omitempty" validate:"omitempty,uuid4"`
+}
+
+func readTicketWait(data json.RawMessage) (flows.Wait, error) {
+	e := &ticketWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &TicketWait{
+		ticketUUID: e.TicketUUID,
+	}
+
+	return w, w.unmarshal(&e.baseWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *TicketWait) MarshalJSON() ([]byte, error) {
+	e := &ticketWaitEnvelope{
+		TicketUUID: w.ticketUUID,
+	}
+
+	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
@@ -0,0 +1,202 @@
+package waits
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterType(TypeTimer, readTimerWait)
+}
+
+// TypeTimer is the type of our timer wait
+const TypeTimer string = "timer"
+
+// TimerWait is a wait which pauses the flow for a delay, and can only be ended by that delay passing (i.e. a wait
+// timeout resume). Messages from the contact don't end the wait. The delay is one of a fixed number of seconds, an
+// expression which evaluates to a number of seconds, or a time of day which is the next occurrence of that time in the
+// contact's timezone. Waits with a delay expression or time of day must also have a fixed timeout in seconds which is
+// used if that can't be evaluated.
+type TimerWait struct {
+	baseWait
+
+	delay string
+	until string
+}
+
+// NewTimerWait creates a new timer wait with a fixed delay in seconds
+func NewTimerWait(timeout int) *TimerWait {
+	return &TimerWait{baseWait: newBaseWait(TypeTimer, &timeout)}
+}
+
+// NewTimerWaitWithDelay creates a new timer wait with a delay in seconds given by the given expression, falling back
+// to the given fixed timeout if that can't be evaluated
+func NewTimerWaitWithDelay(delay string, fallback int) *TimerWait {
+	return &TimerWait{baseWait: newBaseWait(TypeTimer, &fallback), delay: delay}
+}
+
+// NewTimerWaitUntil creates a new timer wait which waits until the next occurrence of the given time of day, falling
+// back to the given fixed timeout if that can't be evaluated
+func NewTimerWaitUntil(until string, fallback int) *TimerWait {
+	return &TimerWait{baseWait: newBaseWait(TypeTimer, &fallback), until: until}
+}
+
+// Delay returns the expression which gives the delay in seconds (optional)
+func (w *TimerWait) Delay() string { return w.delay }
+
+// Until returns the time of day to wait until (optional)
+func (w *TimerWait) Until() string { return w.until }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *TimerWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeMessagingOffline}
+}
+
+// Validate checks that this wait's expressions are valid
+func (w *TimerWait) Validate(exits []flows.Exit) error {
+	if err := validateTemplate(w.delay, "delay"); err != nil {
+		return err
+	}
+	return validateTemplate(w.until, "until")
+}
+
+// Begin beings waiting at this wait
+func (w *TimerWait) Begin(run flows.FlowRun, log flows.EventCallback) bool {
+	timeoutOn, err := w.resolveTimeoutOn(run)
+	if err != nil {
+		// log the error and wait for the fixed fallback timeout instead
+		log(events.NewErrorEvent(err))
+		timeoutOn = run.Session().Now().Add(time.Second * time.Duration(*w.timeout))
+	}
+	if w.quietHours != nil {
+		timeoutOn = w.quietHours.Adjust(timeoutOn, run.Environment().Timezone())
+	}
+
+	w.timeoutOn = &timeoutOn
+
+	log(events.NewTimerWaitEvent(timeoutOn))
+	return true
+}
+
+// works out when this wait should time out
+func (w *TimerWait) resolveTimeoutOn(run flows.FlowRun) (time.Time, error) {
+	now := run.Session().Now()
+
+	if w.delay == "" && w.until == "" {
+		return now.Add(time.Second * time.Duration(*w.timeout)), nil
+	}
+
+	if w.delay != "" {
+		seconds, err := evaluateSeconds(run, w.delay)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "unable to evaluate timer delay")
+		}
+		return now.Add(time.Second * time.Duration(seconds)), nil
+	}
+
+	until, err := run.EvaluateTemplate(w.until)
+	if err != nil {
+		return time.Time{}, err
+	}
+	timeOfDay, err := utils.TimeFromString(until)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "timer until must evaluate to a time of day")
+	}
+
+	// find the next occurrence of that time in the contact's timezone
+	tz := run.Environment().Timezone()
+	localNow := now.In(tz)
+	next := timeOfDay.Combine(utils.ExtractDate(localNow), tz)
+	if !next.After(localNow) {
+		next = timeOfDay.Combine(utils.ExtractDate(localNow.AddDate(0, 0, 1)), tz)
+	}
+	return next, nil
+}
+
+// End ends this wait or returns an error
+func (w *TimerWait) End(run flows.FlowRun, resume flows.Resume, node flows.Node) error {
+	switch resume.Type() {
+	case resumes.TypeMsg, resumes.TypeRunExpiration, resumes.TypeContactChanged:
+		// msg resumes are accepted but ValidateResume keeps us waiting
+		return nil
+	case resumes.TypeWaitTimeout:
+		if w.timeoutOn == nil {
+			return errors.Errorf("can't end with timeout as session wait has no timeout")
+		}
+		if run.Session().Now().Before(*w.timeoutOn) {
+			return errors.Errorf("can't end with timeout before wait has timed out")
+		}
+		return nil
+	}
+
+	return errors.Errorf("can't end a timer wait with a %s resume", resume.Type())
+}
+
+// ValidateResume keeps us waiting if we're resumed with a message, which becomes the session's input but doesn't
+// shorten the delay
+func (w *TimerWait) ValidateResume(run flows.FlowRun, resume flows.Resume, node flows.Node, log flows.EventCallback) (bool, flows.Route) {
+	if resume.Type() == resumes.TypeMsg {
+		return true, flows.NoRoute
+	}
+	return w.baseWait.ValidateResume(run, resume, node, log)
+}
+
+var _ flows.Wait = (*TimerWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type timerWaitEnvelope struct {
+	baseWaitEnvelope
+
+	Delay string `json:"delay,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+func readTimerWait(data json.RawMessage) (flows.Wait, error) {
+	e := &timerWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	if e.TimeoutExpression != "" {
+		return nil, errors.New("timer wait should use delay rather than timeout_expression")
+	}
+
+	// must have a fixed timeout, and at most one other way of determining the delay
+	if e.Timeout == nil {
+		return nil, errors.New("timer wait must have a timeout")
+	}
+	if e.Delay != "" && e.Until != "" {
+		return nil, errors.New("timer wait can't have both delay and until")
+	}
+
+	w := &TimerWait{
+		delay: e.Delay,
+		until: e.Until,
+	}
+
+	return w, w.unmarshal(&e.baseWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *TimerWait) MarshalJSON() ([]byte, error) {
+	e := &timerWaitEnvelope{
+		Delay: w.delay,
+		Until: w.until,
+	}
+
+	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
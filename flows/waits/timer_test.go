@@ -0,0 +1,174 @@
+package waits_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/flows/waits"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var timerWaitJSON = `{
+	"flows": [
+		{
+			"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4",
+			"name": "Timers",
+			"spec_version": "12.0",
+			"language": "eng",
+			"type": "messaging",
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"wait": {"type": "timer", "until": "09:00", "timeout": 86400},
+					"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1", "destination_node_uuid": "11a772f3-3ca2-4429-8b33-20fdcfc2b69e"}]
+				},
+				{
+					"uuid": "11a772f3-3ca2-4429-8b33-20fdcfc2b69e",
+					"wait": {"type": "timer", "delay": "@(2 * 86400)", "timeout": 86400},
+					"exits": [{"uuid": "c70fe86c-9aac-4cc2-a5cb-d35cbe3fed6e", "destination_node_uuid": "2a8b7f0c-9a62-4c5e-8ab0-7f3b0d7c7e36"}]
+				},
+				{
+					"uuid": "2a8b7f0c-9a62-4c5e-8ab0-7f3b0d7c7e36",
+					"wait": {"type": "timer", "timeout": 3600},
+					"exits": [{"uuid": "78ae8f05-f92e-43b2-a886-406eaea1b8e0"}]
+				}
+			]
+		}
+	]
+}`
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func TestTimerWait(t *testing.T) {
+	source, err := static.NewSource([]byte(timerWaitJSON))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+
+	// 8:24am in the contact's timezone
+	clock := &testClock{now: time.Date(2018, 4, 11, 13, 24, 30, 0, time.UTC)}
+	eng := engine.NewBuilder().WithTimeSource(clock).Build()
+
+	tz, _ := time.LoadLocation("America/Guayaquil")
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", tz)
+
+	session := eng.NewSession(sessionAssets)
+	sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	// waiting until the next 9am in the contact's timezone
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.Equal(t, 1, len(sprint.Events()))
+	assert.Equal(t, "timer_wait", sprint.Events()[0].Type())
+	assert.Equal(t, time.Date(2018, 4, 11, 14, 0, 0, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+
+	// messages are received but don't end the wait
+	msg := flows.NewMsgIn(flows.MsgUUID(utils.NewUUID()), urns.URN("tel:+12065551212"), nil, "hi there", nil)
+	sprint, err = session.Resume(resumes.NewMsgResume(nil, nil, msg))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.Equal(t, 1, len(sprint.Events()))
+	assert.Equal(t, "msg_received", sprint.Events()[0].Type())
+	assert.Equal(t, "msg", session.Input().Type())
+	assert.Equal(t, time.Date(2018, 4, 11, 14, 0, 0, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+
+	// and neither does a timeout before its time
+	sprint, err = session.Resume(resumes.NewWaitTimeoutResume(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, "can't end with timeout before wait has timed out", sprint.Events()[0].(*events.ErrorEvent).Text)
+
+	// once it's 9am we can move on to the next wait whose delay is an expression
+	clock.now = time.Date(2018, 4, 11, 14, 0, 0, 0, time.UTC)
+	_, err = session.Resume(resumes.NewWaitTimeoutResume(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, time.Date(2018, 4, 13, 14, 0, 0, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+
+	// and then the final wait which has a fixed delay
+	clock.now = time.Date(2018, 4, 13, 14, 0, 0, 0, time.UTC)
+	_, err = session.Resume(resumes.NewWaitTimeoutResume(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, time.Date(2018, 4, 13, 15, 0, 0, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+
+	// the wait and its timeout are saved with the session
+	marshaled, err := json.Marshal(session.Wait())
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"timer","timeout":3600,"timeout_on":"2018-04-13T15:00:00Z"}`, string(marshaled))
+
+	clock.now = time.Date(2018, 4, 13, 15, 0, 0, 0, time.UTC)
+	_, err = session.Resume(resumes.NewWaitTimeoutResume(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	// a timer wait needs a fixed timeout and at most one other kind of delay
+	_, err = waits.ReadWait([]byte(`{"type": "timer"}`))
+	assert.EqualError(t, err, "timer wait must have a timeout")
+
+	_, err = waits.ReadWait([]byte(`{"type": "timer", "until": "09:00"}`))
+	assert.EqualError(t, err, "timer wait must have a timeout")
+
+	_, err = waits.ReadWait([]byte(`{"type": "timer", "timeout": 60, "delay": "@(60)", "until": "09:00"}`))
+	assert.EqualError(t, err, "timer wait can't have both delay and until")
+}
+
+func TestTimerWaitFallback(t *testing.T) {
+	source, err := static.NewSource([]byte(`{
+		"flows": [
+			{
+				"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4",
+				"name": "Timers",
+				"spec_version": "12.0",
+				"language": "eng",
+				"type": "messaging",
+				"nodes": [
+					{
+						"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+						"wait": {"type": "timer", "delay": "@(\"soon\")", "timeout": 3600},
+						"exits": [{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"}]
+					}
+				]
+			}
+		]
+	}`))
+	require.NoError(t, err)
+	sessionAssets, err := engine.NewSessionAssets(source)
+	require.NoError(t, err)
+
+	flow, err := sessionAssets.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+
+	clock := &testClock{now: time.Date(2018, 4, 11, 13, 24, 30, 0, time.UTC)}
+	eng := engine.NewBuilder().WithTimeSource(clock).Build()
+	contact := flows.NewEmptyContact(sessionAssets, "Bob", "eng", nil)
+
+	session := eng.NewSession(sessionAssets)
+	sprint, err := session.Start(triggers.NewManualTrigger(nil, flow.Reference(), contact, nil))
+	require.NoError(t, err)
+
+	// the delay can't be evaluated so the error is logged and we wait for the fixed timeout instead
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.Equal(t, 2, len(sprint.Events()))
+	assert.Equal(t, "error", sprint.Events()[0].Type())
+	assert.Equal(t, "timer_wait", sprint.Events()[1].Type())
+	assert.Equal(t, time.Date(2018, 4, 11, 14, 24, 30, 0, time.UTC), session.Wait().TimeoutOn().UTC())
+}
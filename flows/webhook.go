@@ -9,8 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/nyaruka/goflow/utils"
-
 	"github.com/pkg/errors"
 )
 
@@ -67,6 +65,50 @@ func (r WebhookStatus) String() string {
 	return string(r)
 }
 
+// WebhookService makes the HTTP requests for webhook calls, i.e. from call_webhook actions and to resthook subscribers
+type WebhookService interface {
+	// Call makes the given request, returning the response and a dump of the request. Resthook is the slug of the
+	// resthook if the request is to one of its subscribers.
+	Call(session Session, request *http.Request, resthook string) (*http.Response, string, error)
+}
+
+// WebhookServiceFunc is an adapter to allow a function to be used as a webhook service
+type WebhookServiceFunc func(Session, *http.Request, string) (*http.Response, string, error)
+
+// Call calls the function
+func (f WebhookServiceFunc) Call(session Session, request *http.Request, resthook string) (*http.Response, string, error) {
+	return f(session, request, resthook)
+}
+
+type httpWebhookService struct{}
+
+// NewHTTPWebhookService creates a webhook service which makes real HTTP requests with the engine's HTTP client
+func NewHTTPWebhookService() WebhookService {
+	return &httpWebhookService{}
+}
+
+func (s *httpWebhookService) Call(session Session, request *http.Request, resthook string) (*http.Response, string, error) {
+	return session.Engine().HTTPClient().DoWithDump(request)
+}
+
+type mockWebhookService struct {
+	status int
+	body   string
+}
+
+// NewMockWebhookService creates a webhook service which doesn't make any requests, but responds to every call with
+// the given status and body
+func NewMockWebhookService(status int, body string) WebhookService {
+	return &mockWebhookService{status: status, body: body}
+}
+
+func (s *mockWebhookService) Call(session Session, request *http.Request, resthook string) (*http.Response, string, error) {
+	return session.Engine().HTTPClient().MockWithDump(request, s.status, s.body)
+}
+
+// the service used in place of the engine's service when webhooks are disabled
+var disabledWebhookService = NewMockWebhookService(200, "DISABLED")
+
 // WebhookCall is a call made to an external service
 type WebhookCall struct {
 	url           string
@@ -78,29 +120,108 @@ type WebhookCall struct {
 	requestTrace  string
 	responseTrace string
 	bodyIgnored   bool
+	attempts      []*WebhookAttempt
+}
+
+// MakeWebhookCall fires the passed in http request using the engine's webhook service, returning any errors encountered.
+// RequestResponse is always set regardless of any errors being set. The request is made with the context of the
+// session's current sprint. If a retry policy is given, failed attempts are retried according to that policy, and if
+// the engine has a circuit breaker, calls to hosts whose circuits are open fail without being made.
+func MakeWebhookCall(session Session, request *http.Request, resthook string, retry *WebhookRetry) (*WebhookCall, error) {
+	// tie the request to the current sprint so that it's abandoned if the sprint is cancelled
+	request = request.WithContext(session.SprintContext())
+
+	var call *WebhookCall
+	var err error
+	attempts := make([]*WebhookAttempt, 0, 1)
+
+	for attempt := 1; ; attempt++ {
+		// if we can't make another attempt, the outcome is that of the previous attempt if there was one
+		if cantErr := checkCanAttempt(session, request); cantErr != nil {
+			if call == nil {
+				return newWebhookCallFromError(request, "", cantErr), cantErr
+			}
+			break
+		}
+
+		if call, err = makeWebhookAttempt(session, request, resthook); call == nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, newWebhookAttempt(call))
+
+		if !retry.shouldRetry(call, attempt) || !retry.wait(session, attempt) {
+			break
+		}
+
+		// the previous attempt will have consumed the request body so it needs replaced
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+	}
+
+	if len(attempts) > 1 {
+		call.attempts = attempts
+	}
+	return call, err
+}
+
+// checks that the engine's circuit breaker allows calls to the host, and that this sprint can afford another webhook
+// call, which is only charged to the sprint's budget if the breaker allows it
+func checkCanAttempt(session Session, request *http.Request) error {
+	budget := session.SprintBudget()
+
+	// check the budget before the breaker so that a call we won't make doesn't take a half-open host's probe, but
+	// still count it so that the sprint is stopped
+	if err := budget.CanUseWebhookCall(); err != nil {
+		budget.UseWebhookCall()
+		return err
+	}
+
+	breaker := session.Engine().WebhookCircuitBreaker()
+	if breaker != nil && !session.Engine().DisableWebhooks() && !breaker.Allow(request.URL.Host, session.Now()) {
+		return errors.Errorf("circuit breaker is open for %s", request.URL.Host)
+	}
+
+	budget.UseWebhookCall()
+	return nil
 }
 
-// MakeWebhookCall fires the passed in http request, returning any errors encountered. RequestResponse is always set
-// regardless of any errors being set
-func MakeWebhookCall(session Session, request *http.Request, resthook string) (*WebhookCall, error) {
+// makes a single attempt of the given request
+func makeWebhookAttempt(session Session, request *http.Request, resthook string) (*WebhookCall, error) {
 	var response *http.Response
 	var requestDump string
 	var err error
 	var timeTaken time.Duration
 
 	if session.Engine().DisableWebhooks() {
-		response, requestDump, err = session.Engine().HTTPClient().MockWithDump(request, 200, "DISABLED")
+		response, requestDump, err = disabledWebhookService.Call(session, request, resthook)
 	} else {
-		start := utils.Now()
-		response, requestDump, err = session.Engine().HTTPClient().DoWithDump(request)
-		timeTaken = utils.Now().Sub(start)
+		start := session.Now()
+		response, requestDump, err = session.Engine().WebhookService().Call(session, request, resthook)
+		timeTaken = session.Now().Sub(start)
+
+		// a call which failed because the sprint was cancelled says nothing about the health of the host
+		if breaker := session.Engine().WebhookCircuitBreaker(); breaker != nil && session.SprintContext().Err() == nil {
+			breaker.Record(request.URL.Host, err != nil || response.StatusCode/100 == 5, session.Now())
+		}
 	}
 
 	if err != nil {
 		return newWebhookCallFromError(request, requestDump, err), err
 	}
 
-	return newWebhookCallFromResponse(requestDump, response, session.Engine().MaxWebhookResponseBytes(), timeTaken, resthook)
+	call, err := newWebhookCallFromResponse(requestDump, response, session.Engine().MaxWebhookResponseBytes(), timeTaken, resthook)
+	if err != nil {
+		return nil, err
+	}
+
+	session.SprintBudget().UseWebhookBytes(len(call.Body()))
+	return call, nil
 }
 
 // URL returns the full URL
@@ -146,6 +267,9 @@ func (w *WebhookCall) BodyIgnored() bool {
 	return w.bodyIgnored
 }
 
+// Attempts returns the outcome of each attempt if this call was retried
+func (w *WebhookCall) Attempts() []*WebhookAttempt { return w.attempts }
+
 // newWebhookCallFromError creates a new webhook call based on the passed in http request and error (when we received no response)
 func newWebhookCallFromError(request *http.Request, requestTrace string, requestError error) *WebhookCall {
 	return &WebhookCall{
@@ -0,0 +1,81 @@
+package flows
+
+import (
+	"sync"
+	"time"
+)
+
+// WebhookCircuitBreaker is shared by all the sessions of an engine and tracks consecutive failures of webhook calls
+// to each host. Once a host has failed too many times in a row, its circuit is opened and calls to it fail immediately
+// rather than waiting on a dead endpoint. After a cool down period, the circuit is half-open and a single probe call is
+// allowed through. The circuit is closed if that succeeds, or opened again if it fails. If the outcome of the probe
+// is never recorded, another probe is allowed after a further cool down period.
+type WebhookCircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mutex sync.Mutex
+	hosts map[string]*hostCircuit
+}
+
+type hostCircuit struct {
+	failures int
+	openedOn time.Time
+	probedOn time.Time
+}
+
+// NewWebhookCircuitBreaker creates a new circuit breaker which opens after the given number of consecutive failures
+// of calls to a host, and stays open for the given cool down period
+func NewWebhookCircuitBreaker(threshold int, cooldown time.Duration) *WebhookCircuitBreaker {
+	return &WebhookCircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*hostCircuit),
+	}
+}
+
+// Allow returns whether a call can be made to the given host at the given time
+func (b *WebhookCircuitBreaker) Allow(host string, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	circuit := b.hosts[host]
+	if circuit == nil || circuit.failures < b.threshold {
+		return true
+	}
+
+	// circuit is open and still cooling down
+	if now.Before(circuit.openedOn.Add(b.cooldown)) {
+		return false
+	}
+
+	// circuit is half-open so only allow a probe if there isn't already one in progress
+	if !circuit.probedOn.IsZero() && now.Before(circuit.probedOn.Add(b.cooldown)) {
+		return false
+	}
+	circuit.probedOn = now
+	return true
+}
+
+// Record records the outcome of a call to the given host at the given time
+func (b *WebhookCircuitBreaker) Record(host string, failed bool, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !failed {
+		delete(b.hosts, host)
+		return
+	}
+
+	circuit := b.hosts[host]
+	if circuit == nil {
+		circuit = &hostCircuit{}
+		b.hosts[host] = circuit
+	}
+
+	circuit.failures++
+	if circuit.failures >= b.threshold {
+		circuit.openedOn = now
+		circuit.probedOn = time.Time{}
+	}
+}
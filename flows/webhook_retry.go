@@ -0,0 +1,86 @@
+package flows
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/utils"
+)
+
+// the maximum total time that a single webhook call can spend waiting between retries
+const maxWebhookRetryBackoff = 30 * time.Second
+
+// WebhookRetry is the policy for retrying a webhook call which failed with a connection error or with a response status
+// that might be temporary. After each failed attempt the call waits before trying again, doubling the wait each time.
+// Retries which would take the total wait for a call over 30 seconds, or the sprint over its duration limit, aren't made.
+type WebhookRetry struct {
+	// MaxAttempts is the maximum number of attempts, including the first
+	MaxAttempts int `json:"max_attempts" validate:"required,min=1,max=5"`
+
+	// BackoffMS is how long to wait in milliseconds before the first retry
+	BackoffMS int `json:"backoff_ms,omitempty" validate:"min=0,max=10000"`
+
+	// Statuses are the response status codes which should be retried, which if empty are any 5xx status
+	Statuses []int `json:"statuses,omitempty" validate:"omitempty,dive,min=100,max=599"`
+}
+
+// NewWebhookRetry creates a new webhook retry policy
+func NewWebhookRetry(maxAttempts int, backoffMS int, statuses []int) *WebhookRetry {
+	return &WebhookRetry{MaxAttempts: maxAttempts, BackoffMS: backoffMS, Statuses: statuses}
+}
+
+// determines whether the given call, which was the given attempt, should be retried
+func (r *WebhookRetry) shouldRetry(call *WebhookCall, attempt int) bool {
+	if r == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+	if call.Status() == WebhookStatusConnectionError {
+		return true
+	}
+	if call.Status() != WebhookStatusResponseError {
+		return false
+	}
+
+	if len(r.Statuses) == 0 {
+		return call.StatusCode()/100 == 5
+	}
+	for _, status := range r.Statuses {
+		if call.StatusCode() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// waits before retrying after the given attempt, returning false if the retry shouldn't be made because it would wait
+// for too long, or the session's sprint was cancelled in the meantime
+func (r *WebhookRetry) wait(session Session, attempt int) bool {
+	initial := time.Duration(r.BackoffMS) * time.Millisecond
+	backoff := initial << uint(attempt-1)
+	if backoff <= 0 {
+		return session.SprintContext().Err() == nil
+	}
+
+	// each wait is double the previous one so the total of the previous waits is one less than this one
+	waited := backoff - initial
+	if waited+backoff > maxWebhookRetryBackoff || !session.SprintBudget().CanWait(backoff) {
+		return false
+	}
+
+	// waiting is done by the engine's time source so that sessions with a fake time source don't actually block
+	return utils.Sleep(session.SprintContext(), session.Engine().TimeSource(), backoff)
+}
+
+// WebhookAttempt is the outcome of a single attempt of a webhook call which was retried
+type WebhookAttempt struct {
+	Status     WebhookStatus `json:"status" validate:"required"`
+	StatusCode int           `json:"status_code,omitempty"`
+	ElapsedMS  int           `json:"elapsed_ms"`
+}
+
+func newWebhookAttempt(call *WebhookCall) *WebhookAttempt {
+	return &WebhookAttempt{
+		Status:     call.Status(),
+		StatusCode: call.StatusCode(),
+		ElapsedMS:  int(call.TimeTaken() / time.Millisecond),
+	}
+}
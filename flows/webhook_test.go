@@ -1,12 +1,20 @@
 package flows_test
 
 import (
+	"bytes"
+	"context"
 	"github.com/nyaruka/goflow/flows"
+	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/nyaruka/goflow/flows/engine"
 	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -116,7 +124,7 @@ func TestWebhookParsing(t *testing.T) {
 		request, err := http.NewRequest(tc.call.method, tc.call.url, strings.NewReader(tc.call.body))
 		require.NoError(t, err)
 
-		webhook, err := flows.MakeWebhookCall(session, request, "")
+		webhook, err := flows.MakeWebhookCall(session, request, "", nil)
 		if tc.isError {
 			assert.Error(t, err)
 		} else {
@@ -132,3 +140,301 @@ func TestWebhookParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestWebhookService(t *testing.T) {
+	session, _, err := test.CreateTestSession("", nil)
+	require.NoError(t, err)
+
+	// a service which responds based on the host, and records which resthooks were called
+	resthooks := make([]string, 0)
+	service := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		resthooks = append(resthooks, resthook)
+
+		if request.URL.Host == "down.com" {
+			return flows.NewMockWebhookService(503, `{"errors": ["down"]}`).Call(s, request, resthook)
+		}
+		return flows.NewMockWebhookService(200, `{"ok": true}`).Call(s, request, resthook)
+	})
+
+	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-testing").WithWebhookService(service).Build()
+	session = eng.NewSession(session.Assets())
+
+	request, _ := http.NewRequest("GET", "http://up.com/?cmd=success", nil)
+	webhook, err := flows.MakeWebhookCall(session, request, "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://up.com/?cmd=success", webhook.URL())
+	assert.Equal(t, flows.WebhookStatusSuccess, webhook.Status())
+	assert.Equal(t, "GET /?cmd=success HTTP/1.1\r\nHost: up.com\r\nUser-Agent: goflow-testing\r\nAccept-Encoding: gzip\r\n\r\n", webhook.Request())
+	assert.Equal(t, `{"ok": true}`, webhook.Body())
+
+	request, _ = http.NewRequest("POST", "http://down.com/", strings.NewReader(`{}`))
+	webhook, err = flows.MakeWebhookCall(session, request, "new-registration", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.WebhookStatusResponseError, webhook.Status())
+	assert.Equal(t, "new-registration", webhook.Resthook())
+	assert.Equal(t, `{"errors": ["down"]}`, webhook.Body())
+	assert.Equal(t, []string{"", "new-registration"}, resthooks)
+
+	// disabling webhooks takes precedence over the configured service
+	eng = engine.NewBuilder().WithWebhookService(service).WithDisableWebhooks(true).Build()
+	session = eng.NewSession(session.Assets())
+
+	request, _ = http.NewRequest("GET", "http://up.com/", nil)
+	webhook, err = flows.MakeWebhookCall(session, request, "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "DISABLED", webhook.Body())
+	assert.Equal(t, 2, len(resthooks))
+}
+
+func TestWebhookRetry(t *testing.T) {
+	session, _, err := test.CreateTestSession("", nil)
+	require.NoError(t, err)
+
+	// a service which responds to each call with the next of the given statuses
+	var statuses []int
+	var bodies []string
+	service := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		body, _ := ioutil.ReadAll(request.Body)
+		bodies = append(bodies, string(body))
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		status := statuses[0]
+		statuses = statuses[1:]
+		if status == 0 {
+			return nil, "POST / HTTP/1.1\r\n\r\n", errors.New("connection refused")
+		}
+		return flows.NewMockWebhookService(status, `{}`).Call(s, request, resthook)
+	})
+
+	eng := engine.NewBuilder().WithWebhookService(service).Build()
+	session = eng.NewSession(session.Assets())
+
+	testCases := []struct {
+		retry            *flows.WebhookRetry
+		statuses         []int
+		expectedStatus   flows.WebhookStatus
+		expectedAttempts []int
+		isError          bool
+	}{
+		// no retry policy
+		{nil, []int{503}, flows.WebhookStatusResponseError, nil, false},
+
+		// 5xx statuses retried by default until one succeeds
+		{flows.NewWebhookRetry(3, 0, nil), []int{503, 500, 200}, flows.WebhookStatusSuccess, []int{503, 500, 200}, false},
+
+		// or until we run out of attempts
+		{flows.NewWebhookRetry(2, 0, nil), []int{503, 503}, flows.WebhookStatusResponseError, []int{503, 503}, false},
+
+		// 4xx statuses aren't retried by default
+		{flows.NewWebhookRetry(3, 0, nil), []int{400}, flows.WebhookStatusResponseError, nil, false},
+
+		// only the given statuses are retried if specified
+		{flows.NewWebhookRetry(3, 0, []int{429}), []int{429, 503}, flows.WebhookStatusResponseError, []int{429, 503}, false},
+
+		// connection errors are always retried
+		{flows.NewWebhookRetry(3, 0, []int{429}), []int{0, 200}, flows.WebhookStatusSuccess, []int{0, 200}, false},
+		{flows.NewWebhookRetry(2, 0, nil), []int{0, 0}, flows.WebhookStatusConnectionError, []int{0, 0}, true},
+	}
+
+	for _, tc := range testCases {
+		statuses = tc.statuses
+		bodies = nil
+
+		request, _ := http.NewRequest("POST", "http://example.com/", strings.NewReader(`{"foo": "bar"}`))
+		webhook, err := flows.MakeWebhookCall(session, request, "", tc.retry)
+		if tc.isError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, tc.expectedStatus, webhook.Status())
+		assert.Equal(t, 0, len(statuses), "not all statuses used for retry %+v", tc.retry)
+
+		// every attempt should have been made with the full body
+		for _, body := range bodies {
+			assert.Equal(t, `{"foo": "bar"}`, body)
+		}
+
+		if tc.expectedAttempts == nil {
+			assert.Nil(t, webhook.Attempts())
+		} else {
+			actualAttempts := make([]int, len(webhook.Attempts()))
+			for i, attempt := range webhook.Attempts() {
+				actualAttempts[i] = attempt.StatusCode
+			}
+			assert.Equal(t, tc.expectedAttempts, actualAttempts)
+		}
+	}
+}
+
+func TestWebhookCircuitBreaker(t *testing.T) {
+	now := time.Date(2019, 10, 7, 15, 21, 30, 0, time.UTC)
+
+	breaker := flows.NewWebhookCircuitBreaker(2, time.Minute)
+	assert.True(t, breaker.Allow("down.com", now))
+
+	breaker.Record("down.com", true, now)
+	assert.True(t, breaker.Allow("down.com", now))
+
+	breaker.Record("down.com", true, now)
+	assert.False(t, breaker.Allow("down.com", now))
+	assert.False(t, breaker.Allow("down.com", now.Add(59*time.Second)))
+	assert.True(t, breaker.Allow("up.com", now))
+
+	// once cooled down, a single probe call is allowed but one more failure re-opens the circuit
+	assert.True(t, breaker.Allow("down.com", now.Add(time.Minute)))
+	assert.False(t, breaker.Allow("down.com", now.Add(time.Minute)))
+	breaker.Record("down.com", true, now.Add(time.Minute))
+	assert.False(t, breaker.Allow("down.com", now.Add(time.Minute+time.Second)))
+
+	// if the outcome of a probe is never recorded, another is allowed after the cool down period
+	assert.True(t, breaker.Allow("down.com", now.Add(2*time.Minute)))
+	assert.False(t, breaker.Allow("down.com", now.Add(2*time.Minute+59*time.Second)))
+	assert.True(t, breaker.Allow("down.com", now.Add(3*time.Minute)))
+
+	// and a success closes it
+	breaker.Record("down.com", false, now.Add(3*time.Minute))
+	breaker.Record("down.com", true, now.Add(3*time.Minute))
+	assert.True(t, breaker.Allow("down.com", now.Add(3*time.Minute)))
+
+	// check the engine's breaker is used for webhook calls
+	session, _, err := test.CreateTestSession("", nil)
+	require.NoError(t, err)
+
+	numCalls := 0
+	service := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		numCalls++
+		return flows.NewMockWebhookService(503, `{"errors": ["down"]}`).Call(s, request, resthook)
+	})
+
+	eng := engine.NewBuilder().
+		WithWebhookService(service).
+		WithWebhookCircuitBreaker(flows.NewWebhookCircuitBreaker(2, time.Minute)).
+		WithMaxWebhookCallsPerSprint(3).
+		WithTimeSource(utils.NewFixedTimeSource(now)).
+		Build()
+	session = eng.NewSession(session.Assets())
+
+	// retries count towards opening the circuit, and aren't made once it's open
+	request, _ := http.NewRequest("GET", "http://down.com/", nil)
+	webhook, err := flows.MakeWebhookCall(session, request, "", flows.NewWebhookRetry(3, 0, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, flows.WebhookStatusResponseError, webhook.Status())
+	assert.Equal(t, 2, len(webhook.Attempts()))
+	assert.Equal(t, 2, numCalls)
+
+	request, _ = http.NewRequest("GET", "http://down.com/", nil)
+	webhook, err = flows.MakeWebhookCall(session, request, "", nil)
+	assert.EqualError(t, err, "circuit breaker is open for down.com")
+	assert.Equal(t, flows.WebhookStatusConnectionError, webhook.Status())
+	assert.Equal(t, 2, numCalls)
+
+	// calls refused by the breaker aren't charged to the sprint's budget
+	request, _ = http.NewRequest("GET", "http://up.com/", nil)
+	_, err = flows.MakeWebhookCall(session, request, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, numCalls)
+
+	// a call refused by the sprint's budget doesn't take a half-open host's probe
+	breaker = flows.NewWebhookCircuitBreaker(2, time.Minute)
+	breaker.Record("down.com", true, now.Add(-time.Minute))
+	breaker.Record("down.com", true, now.Add(-time.Minute))
+
+	eng = engine.NewBuilder().
+		WithWebhookService(service).
+		WithWebhookCircuitBreaker(breaker).
+		WithMaxWebhookCallsPerSprint(1).
+		WithTimeSource(utils.NewFixedTimeSource(now)).
+		Build()
+	session = eng.NewSession(session.Assets())
+
+	request, _ = http.NewRequest("GET", "http://up.com/", nil)
+	_, err = flows.MakeWebhookCall(session, request, "", nil)
+	assert.NoError(t, err)
+
+	request, _ = http.NewRequest("GET", "http://down.com/", nil)
+	_, err = flows.MakeWebhookCall(session, request, "", nil)
+	assert.EqualError(t, err, "webhook call limit of 1 exceeded, call not made")
+	assert.Equal(t, 4, numCalls)
+	assert.True(t, breaker.Allow("down.com", now))
+
+	// and calls which fail because the sprint was cancelled aren't counted as host failures
+	breaker = flows.NewWebhookCircuitBreaker(1, time.Minute)
+	cancelling := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		return nil, "GET / HTTP/1.1\r\n\r\n", context.Canceled
+	})
+	eng = engine.NewBuilder().
+		WithWebhookService(cancelling).
+		WithWebhookCircuitBreaker(breaker).
+		WithTimeSource(utils.NewFixedTimeSource(now)).
+		Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := &cancelledSession{Session: eng.NewSession(session.Assets()), ctx: ctx}
+
+	request, _ = http.NewRequest("GET", "http://slow.com/", nil)
+	webhook, err = flows.MakeWebhookCall(cancelled, request, "", nil)
+	assert.Error(t, err)
+	assert.Equal(t, flows.WebhookStatusConnectionError, webhook.Status())
+	assert.True(t, breaker.Allow("slow.com", now))
+}
+
+// a session whose sprint has the given context
+type cancelledSession struct {
+	flows.Session
+	ctx context.Context
+}
+
+func (s *cancelledSession) SprintContext() context.Context { return s.ctx }
+
+func TestWebhookRetrySprintDuration(t *testing.T) {
+	session, _, err := test.CreateTestSession("", nil)
+	require.NoError(t, err)
+
+	numCalls := 0
+	service := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		numCalls++
+		return flows.NewMockWebhookService(503, `{"errors": ["down"]}`).Call(s, request, resthook)
+	})
+
+	// a retry which would take the sprint over its duration limit isn't made
+	eng := engine.NewBuilder().WithWebhookService(service).WithMaxSprintDuration(time.Second).Build()
+	session = eng.NewSession(session.Assets())
+
+	request, _ := http.NewRequest("GET", "http://down.com/", nil)
+	webhook, err := flows.MakeWebhookCall(session, request, "", flows.NewWebhookRetry(3, 2000, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, flows.WebhookStatusResponseError, webhook.Status())
+	assert.Nil(t, webhook.Attempts())
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestWebhookRetryWithFixedTime(t *testing.T) {
+	session, _, err := test.CreateTestSession("", nil)
+	require.NoError(t, err)
+
+	numCalls := 0
+	service := flows.WebhookServiceFunc(func(s flows.Session, request *http.Request, resthook string) (*http.Response, string, error) {
+		numCalls++
+		return flows.NewMockWebhookService(503, `{"errors": ["down"]}`).Call(s, request, resthook)
+	})
+
+	// waits between retries are taken by the engine's time source, so a fixed one doesn't block
+	clock := utils.NewFixedTimeSource(time.Date(2018, 4, 11, 13, 24, 30, 0, time.UTC))
+	eng := engine.NewBuilder().WithWebhookService(service).WithTimeSource(clock).Build()
+	session = eng.NewSession(session.Assets())
+
+	start := time.Now()
+	request, _ := http.NewRequest("GET", "http://down.com/", nil)
+	webhook, err := flows.MakeWebhookCall(session, request, "", flows.NewWebhookRetry(3, 10000, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, flows.WebhookStatusResponseError, webhook.Status())
+	assert.Equal(t, 3, len(webhook.Attempts()))
+	assert.Equal(t, 3, numCalls)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -1,25 +1,35 @@
 module github.com/nyaruka/goflow
 
+go 1.27.1
+
 require (
 	github.com/Masterminds/semver v1.4.2
 	github.com/antlr/antlr4 v0.0.0-20181218183534-70d9ddcd0a58
 	github.com/buger/jsonparser v0.0.0-20180131123142-4be68c93a244
-	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/dustin/go-humanize v0.0.0-20171111073723-bb3d318650d4
-	github.com/go-playground/locales v0.11.2 // indirect
-	github.com/go-playground/universal-translator v0.16.0 // indirect
-	github.com/kr/pretty v0.1.0 // indirect
 	github.com/nyaruka/gocommon v0.2.0
 	github.com/nyaruka/phonenumbers v1.0.35
 	github.com/pkg/errors v0.8.0
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/satori/go.uuid v1.2.0
 	github.com/sergi/go-diff v1.0.0
 	github.com/shopspring/decimal v0.0.0-20180319170823-2df3e6ddaf6e
 	github.com/stretchr/testify v1.2.1
-	golang.org/x/sync v0.0.0-20181108010431-42b317875d0f // indirect
 	golang.org/x/text v0.3.0
+	gopkg.in/go-playground/validator.v9 v9.12.0
+)
+
+require (
+	github.com/aws/aws-lambda-go v1.8.1 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/go-playground/locales v0.11.2 // indirect
+	github.com/go-playground/universal-translator v0.16.0 // indirect
+	github.com/golang/protobuf v1.2.0 // indirect
+	github.com/kr/pretty v0.1.0 // indirect
+	github.com/kr/pty v1.1.1 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20180921000356-2f5d2388922f // indirect
+	golang.org/x/sync v0.0.0-20181108010431-42b317875d0f // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
-	gopkg.in/go-playground/validator.v9 v9.12.0
 )
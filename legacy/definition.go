@@ -393,7 +393,7 @@ func migrateAction(baseLanguage utils.Language, a Action, localization flows.Loc
 	case "channel":
 		return actions.NewSetContactChannelAction(a.UUID, assets.NewChannelReference(a.Channel, a.Name)), nil
 	case "flow":
-		return actions.NewEnterFlowAction(a.UUID, a.Flow.Migrate(), true), nil
+		return actions.NewEnterFlowAction(a.UUID, a.Flow.Migrate(), true, nil, nil), nil
 	case "trigger-flow":
 		contacts := make([]*flows.ContactReference, len(a.Contacts))
 		for i, contact := range a.Contacts {
@@ -462,7 +462,7 @@ func migrateAction(baseLanguage utils.Language, a Action, localization flows.Loc
 		}
 
 		if a.Type == "reply" {
-			return actions.NewSendMsgAction(a.UUID, migratedText, attachments, migratedQuickReplies, a.SendAll), nil
+			return actions.NewSendMsgAction(a.UUID, migratedText, attachments, migratedQuickReplies, a.SendAll, nil), nil
 		}
 
 		contacts := make([]*flows.ContactReference, len(a.Contacts))
@@ -574,7 +574,7 @@ func migrateRuleSet(lang utils.Language, r RuleSet, localization flows.Localizat
 	switch r.Type {
 	case "subflow":
 		newActions = []flows.Action{
-			actions.NewEnterFlowAction(flows.ActionUUID(utils.NewUUID()), config.Flow, false),
+			actions.NewEnterFlowAction(flows.ActionUUID(utils.NewUUID()), config.Flow, false, nil, nil),
 		}
 
 		// subflow rulesets operate on the child flow status
@@ -600,7 +600,7 @@ func migrateRuleSet(lang utils.Language, r RuleSet, localization flows.Localizat
 		}
 
 		newActions = []flows.Action{
-			actions.NewCallWebhookAction(flows.ActionUUID(utils.NewUUID()), method, migratedURL, headers, body, resultName),
+			actions.NewCallWebhookAction(flows.ActionUUID(utils.NewUUID()), method, migratedURL, headers, body, nil, resultName),
 		}
 
 		// webhook rulesets operate on the webhook status, saved as category
@@ -609,7 +609,7 @@ func migrateRuleSet(lang utils.Language, r RuleSet, localization flows.Localizat
 
 	case "resthook":
 		newActions = []flows.Action{
-			actions.NewCallResthookAction(flows.ActionUUID(utils.NewUUID()), config.Resthook, resultName),
+			actions.NewCallResthookAction(flows.ActionUUID(utils.NewUUID()), config.Resthook, nil, resultName),
 		}
 
 		// resthook rulesets operate on the webhook status, saved as category
@@ -1065,6 +1065,9 @@ func (f *Flow) Migrate(collapseExits bool, includeUI bool, baseMediaURL string)
 		f.Metadata.Expires,
 		localization,
 		nodes,
+		nil,
+		nil,
+		nil,
 		ui,
 	), nil
 }
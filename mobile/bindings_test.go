@@ -86,7 +86,7 @@ func TestMobileBindings(t *testing.T) {
 	marshaled, err := session.ToJSON()
 	require.NoError(t, err)
 
-	assert.Equal(t, `{"type":"messaging_offline","environment":{"date_f`, marshaled[:50])
+	assert.Equal(t, `{"spec_version":"1.1.0","type":"messaging_offline"`, marshaled[:50])
 
 	// and try to read it back
 	session2, err := eng.ReadSession(sa, marshaled)
@@ -30,24 +30,33 @@ var flowTests = []struct {
 	{"airtime.json", "airtime_disabled_test.json"},
 	{"all_actions.json", "all_actions_test.json"},
 	{"brochure.json", "brochure_test.json"},
+	{"classifier.json", "classifier_test.json"},
+	{"consent_opt_out.json", "consent_opt_out_test.json"},
+	{"contact_changed.json", "contact_changed_test.json"},
 	{"date_parse.json", "date_parse_test.json"},
 	{"default_result.json", "default_result_test.json"},
+	{"dial.json", "dial_test.json"},
 	{"dynamic_groups_correction.json", "dynamic_groups_correction_test.json"},
 	{"dynamic_groups.json", "dynamic_groups_test.json"},
 	{"empty.json", "empty_test.json"},
+	{"enforced_hint.json", "enforced_hint_test.json"},
+	{"external_event.json", "external_event_test.json"},
 	{"initial_wait.json", "initial_wait_test.json"},
 	{"legacy_extra.json", "legacy_extra_test.json"},
 	{"no_contact.json", "no_contact_test.json"},
 	{"node_loop.json", "node_loop_test.json"},
 	{"redact_urns.json", "redact_urns_test.json"},
+	{"referral.json", "referral_test.json"},
 	{"resthook.json", "resthook_test.json"},
 	{"router_tests.json", "router_tests_test.json"},
 	{"subflow_loop_with_wait.json", "subflow_loop_with_wait_test.json"},
 	{"enter_flow_loop.json", "enter_flow_loop_test.json"},
 	{"enter_flow_terminal.json", "enter_flow_terminal_test.json"},
 	{"subflow_other.json", "subflow_other_test.json"},
+	{"subflow_params.json", "subflow_params_test.json"},
 	{"subflow.json", "subflow_test.json"},
 	{"subflow.json", "subflow_resume_with_expiration_test.json"},
+	{"ticket.json", "ticket_test.json"},
 	{"triggered.json", "triggered_test.json"},
 	{"two_questions.json", "two_questions_test.json"},
 	{"two_questions.json", "two_questions_resume_with_expiration_test.json"},
@@ -114,7 +123,7 @@ func runFlow(assetsPath string, rawTrigger json.RawMessage, rawResumes []json.Ra
 		return runResult{}, errors.Wrapf(err, "error unmarshalling trigger")
 	}
 
-	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-testing").Build()
+	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-testing").WithRandSeed(123456).WithClassifierService(classifierService).Build()
 	session := eng.NewSession(sessionAssets)
 
 	sprint, err := session.Start(trigger)
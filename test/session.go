@@ -12,6 +12,7 @@ import (
 	"github.com/nyaruka/goflow/flows/triggers"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 var sessionAssets = `{
@@ -38,6 +39,14 @@ var sessionAssets = `{
             "roles": ["send", "receive"]
         }
     ],
+    "classifiers": [
+        {
+            "uuid": "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000",
+            "name": "Booking",
+            "type": "wit",
+            "intents": ["book_flight", "book_hotel"]
+        }
+    ],
     "flows": [
         {
             "uuid": "50c3706e-fedb-42c0-8eab-dda3335714b7",
@@ -139,6 +148,9 @@ var sessionAssets = `{
             "spec_version": "12.0",
             "language": "eng",
             "type": "messaging",
+            "outputs": [
+                {"key": "age", "type": "number", "value": "@results.age.value"}
+            ],
             "nodes": [{
                 "uuid": "d9dba561-b5ee-4f62-ba44-60c4dc242b84",
                 "actions": [
@@ -234,6 +246,27 @@ var sessionAssets = `{
                 "http://localhost/?cmd=success"
             ]
         }
+    ],
+    "templates": [
+        {
+            "uuid": "3ce100b7-a734-4b4e-891b-350b1279ade2",
+            "name": "survey_reminder",
+            "translations": [
+                {
+                    "language": "eng",
+                    "channel": {"uuid": "57f1078f-88aa-46f4-a59a-948a5739c03d", "name": "My Android Phone"},
+                    "content": "Hi {{1}}, are you ready to complete today's survey?",
+                    "variable_count": 1
+                }
+            ]
+        }
+    ],
+    "ticketers": [
+        {
+            "uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5",
+            "name": "Support Tickets",
+            "type": "mailgun"
+        }
     ]
 }`
 
@@ -426,6 +459,12 @@ var voiceSessionTrigger = `{
     }
 }`
 
+// classifies text for the classifiers in our test assets
+var classifierService = flows.NewLocalClassifierService(
+	flows.NewLocalClassifierRule("book_flight", `\bflight\b(?:.* to (?P<location>\w+))?`, decimal.RequireFromString("0.9")),
+	flows.NewLocalClassifierRule("book_hotel", `\bbook\b`, decimal.RequireFromString("0.3")),
+)
+
 // CreateTestSession creates a standard example session for testing
 func CreateTestSession(testServerURL string, actionToAdd flows.Action) (flows.Session, []flows.Event, error) {
 
@@ -509,7 +548,7 @@ func CreateSession(assetsJSON json.RawMessage, testServerURL string) (flows.Sess
 		return nil, errors.Wrap(err, "error creating test session assets")
 	}
 
-	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-testing").Build()
+	eng := engine.NewBuilder().WithDefaultUserAgent("goflow-testing").WithClassifierService(classifierService).Build()
 	session := eng.NewSession(assets)
 	return session, nil
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/json"
+	"math/rand"
 	"time"
 )
 
@@ -37,6 +38,9 @@ type Environment interface {
 	// Convenience method to get the current time in the env timezone
 	Now() time.Time
 
+	// Convenience method to get the random number generator
+	Rand() *rand.Rand
+
 	// extensions to the engine can expect their own env values
 	Extension(string) json.RawMessage
 
@@ -66,7 +70,8 @@ func (e *environment) NumberFormat() *NumberFormat      { return e.numberFormat
 func (e *environment) RedactionPolicy() RedactionPolicy { return e.redactionPolicy }
 func (e *environment) MaxValueLength() int              { return e.maxValueLength }
 
-func (e *environment) Now() time.Time { return Now().In(e.Timezone()) }
+func (e *environment) Now() time.Time   { return Now().In(e.Timezone()) }
+func (e *environment) Rand() *rand.Rand { return currentRand }
 
 func (e *environment) Extension(name string) json.RawMessage {
 	return e.extensions[name]
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/nyaruka/phonenumbers"
 )
@@ -31,6 +32,16 @@ func MinInt(x, y int) int {
 	return y
 }
 
+// SortedKeys returns the keys of the given map in sorted order
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // DeriveCountryFromTel attempts to derive a country code (e.g. RW) from a phone number
 func DeriveCountryFromTel(number string) string {
 	parsed, err := phonenumbers.Parse(number, "")
@@ -20,6 +20,11 @@ func TestMinInt(t *testing.T) {
 	assert.Equal(t, -1, utils.MinInt(1, -1))
 }
 
+func TestSortedKeys(t *testing.T) {
+	assert.Equal(t, []string{}, utils.SortedKeys(nil))
+	assert.Equal(t, []string{"a", "b", "c"}, utils.SortedKeys(map[string]string{"c": "3", "a": "1", "b": "2"}))
+}
+
 func TestDeriveCountryFromTel(t *testing.T) {
 	assert.Equal(t, "RW", utils.DeriveCountryFromTel("+250788383383"))
 	assert.Equal(t, "EC", utils.DeriveCountryFromTel("+593979000000"))
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"time"
 )
 
@@ -9,6 +10,20 @@ type TimeSource interface {
 	Now() time.Time
 }
 
+// Sleeper is a time source which can wait for a duration to pass, returning false if the context is done first
+type Sleeper interface {
+	Sleep(context.Context, time.Duration) bool
+}
+
+// Sleep waits for the given duration to pass according to the given time source. Only time sources which are
+// sleepers actually wait, so fixed and sequential time sources return immediately.
+func Sleep(ctx context.Context, source TimeSource, duration time.Duration) bool {
+	if sleeper, isSleeper := source.(Sleeper); isSleeper {
+		return sleeper.Sleep(ctx, duration)
+	}
+	return ctx.Err() == nil
+}
+
 // defaultTimeSource returns the current system time
 type defaultTimeSource struct{}
 
@@ -16,6 +31,18 @@ func (s defaultTimeSource) Now() time.Time {
 	return time.Now()
 }
 
+func (s defaultTimeSource) Sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // a time source which returns a fixed time
 type fixedTimeSource struct {
 	now time.Time
@@ -46,10 +73,24 @@ func NewSequentialTimeSource(start time.Time) TimeSource {
 	return &sequentialTimeSource{current: start}
 }
 
+// a time source which defers to the current process-wide time source
+type globalTimeSource struct{}
+
+func (s globalTimeSource) Now() time.Time {
+	return Now()
+}
+
+func (s globalTimeSource) Sleep(ctx context.Context, duration time.Duration) bool {
+	return Sleep(ctx, currentTimeSource, duration)
+}
+
 // DefaultTimeSource is the default time source
 var DefaultTimeSource TimeSource = defaultTimeSource{}
 var currentTimeSource = DefaultTimeSource
 
+// GlobalTimeSource is a time source which always returns the same as Now(), i.e. it respects calls to SetTimeSource
+var GlobalTimeSource TimeSource = globalTimeSource{}
+
 // Now returns the current time
 func Now() time.Time {
 	return currentTimeSource.Now()
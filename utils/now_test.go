@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -22,4 +23,33 @@ func TestTimeSources(t *testing.T) {
 	assert.Equal(t, time.Date(2018, 7, 5, 16, 29, 30, 123456, time.UTC), utils.Now())
 	assert.Equal(t, time.Date(2018, 7, 5, 16, 29, 31, 123456, time.UTC), utils.Now())
 	assert.Equal(t, time.Date(2018, 7, 5, 16, 29, 32, 123456, time.UTC), utils.Now())
+
+	// global time source defers to whatever is currently set
+	assert.Equal(t, time.Date(2018, 7, 5, 16, 29, 33, 123456, time.UTC), utils.GlobalTimeSource.Now())
+}
+
+func TestSleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the system clock actually waits
+	start := time.Now()
+	assert.True(t, utils.Sleep(ctx, utils.DefaultTimeSource, 10*time.Millisecond))
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+
+	// other time sources don't
+	start = time.Now()
+	assert.True(t, utils.Sleep(ctx, utils.NewFixedTimeSource(time.Date(2018, 7, 5, 16, 29, 30, 123456, time.UTC)), time.Hour))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// and the global time source defers to whatever is currently set
+	utils.SetTimeSource(utils.NewFixedTimeSource(time.Date(2018, 7, 5, 16, 29, 30, 123456, time.UTC)))
+	defer utils.SetTimeSource(utils.DefaultTimeSource)
+
+	assert.True(t, utils.Sleep(ctx, utils.GlobalTimeSource, time.Hour))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// but none wait once the context is done
+	cancel()
+	assert.False(t, utils.Sleep(ctx, utils.DefaultTimeSource, time.Hour))
+	assert.False(t, utils.Sleep(ctx, utils.GlobalTimeSource, time.Hour))
 }
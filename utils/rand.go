@@ -2,15 +2,34 @@ package utils
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
 // DefaultRand is the default rand for calls to Rand()
-var DefaultRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+var DefaultRand = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
 var currentRand = DefaultRand
 
+// a rand source which is safe for concurrent use, like the top-level source used by math/rand
+type lockedSource struct {
+	lk  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.src.Seed(seed)
+}
+
 // NewSeededRand creates a new seeded rand
 func NewSeededRand(seed int64) *rand.Rand {
 	return rand.New(rand.NewSource(seed))
@@ -18,7 +37,12 @@ func NewSeededRand(seed int64) *rand.Rand {
 
 // RandDecimal returns a random decimal in the range [0.0, 1.0)
 func RandDecimal() decimal.Decimal {
-	return decimal.NewFromFloat(currentRand.Float64())
+	return RandDecimalFrom(currentRand)
+}
+
+// RandDecimalFrom returns a random decimal in the range [0.0, 1.0) using the given rand
+func RandDecimalFrom(rnd *rand.Rand) decimal.Decimal {
+	return decimal.NewFromFloat(rnd.Float64())
 }
 
 // RandIntN returns a random integer in the range [0, n)
@@ -26,6 +50,20 @@ func RandIntN(n int) int {
 	return currentRand.Intn(n)
 }
 
+// MaxRandSeed is the largest seed returned by RandSeed, which is limited to 53 bits so that seeds survive being written
+// to JSON and read back by parsers which treat all numbers as floats
+const MaxRandSeed = 1<<53 - 1
+
+// RandSeed returns a random value which can be used to seed a new rand
+func RandSeed() int64 {
+	return RandSeedFrom(currentRand)
+}
+
+// RandSeedFrom returns a random value from the given rand which can be used to seed a new rand
+func RandSeedFrom(rnd *rand.Rand) int64 {
+	return rnd.Int63() & MaxRandSeed
+}
+
 // SetRand sets the rand used by Rand()
 func SetRand(rnd *rand.Rand) {
 	currentRand = rnd
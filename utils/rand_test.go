@@ -19,4 +19,11 @@ func TestRand(t *testing.T) {
 	assert.Equal(t, decimal.RequireFromString("0.89891152303272914281251360080204904079437255859375"), utils.RandDecimal())
 	assert.Equal(t, decimal.RequireFromString("0.6087185537746531149849715802702121436595916748046875"), utils.RandDecimal())
 	assert.Equal(t, decimal.RequireFromString("0.302355432890411612856240708424593321979045867919921875"), utils.RandDecimal())
+	assert.Equal(t, int64(5550079571748696), utils.RandSeed())
+	assert.True(t, utils.RandSeed() <= utils.MaxRandSeed)
+
+	// can also generate decimals from a given rand
+	rnd := utils.NewSeededRand(1234)
+	assert.Equal(t, 2, rnd.Intn(10))
+	assert.Equal(t, decimal.RequireFromString("0.68054456053856193786799622102989815175533294677734375"), utils.RandDecimalFrom(rnd))
 }
@@ -52,7 +52,9 @@ func NewUUID() UUID {
 	return currentUUIDGenerator.Next()
 }
 
-// SetUUIDGenerator sets the generator used by UUID4()
-func SetUUIDGenerator(generator UUIDGenerator) {
+// SetUUIDGenerator sets the generator used by UUID4(), returning the previous generator so that it can be restored
+func SetUUIDGenerator(generator UUIDGenerator) UUIDGenerator {
+	previous := currentUUIDGenerator
 	currentUUIDGenerator = generator
+	return previous
 }